@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+
+	"github.com/cockroachdb/errors"
+	gh "github.com/google/go-github/v80/github"
+	"github.com/spf13/cobra"
+
+	"github.com/smykla-labs/.github/pkg/github"
+)
+
+const (
+	// signingKeyEnv holds the private key content, as set by the signing_key action input.
+	signingKeyEnv = "INPUT_SIGNING_KEY"
+	// signingKeyPassphraseEnv holds the passphrase of an encrypted signing key.
+	signingKeyPassphraseEnv = "INPUT_SIGNING_KEY_PASSPHRASE"
+)
+
+// addCommitFlags registers flags controlling commit identity and signing.
+func addCommitFlags(cmd *cobra.Command) {
+	cmd.Flags().String(
+		"signing-key-file",
+		"",
+		"Path to SSH or OpenPGP private key used to sign commits (or key content in INPUT_SIGNING_KEY)",
+	)
+	cmd.Flags().String("signing-format", "", "Signing key format (ssh|openpgp), detected from key if empty")
+	cmd.Flags().String("commit-author-name", "", "Commit author name (required when signing)")
+	cmd.Flags().String("commit-author-email", "", "Commit author email (required when signing)")
+	cmd.Flags().String("committer-name", "", "Committer name (defaults to commit author)")
+	cmd.Flags().String("committer-email", "", "Committer email (defaults to commit author)")
+	cmd.Flags().String(
+		"co-authored-by",
+		"",
+		"Comma-separated Co-authored-by trailers (e.g., 'Name <email>')",
+	)
+}
+
+// getCommitOptions builds commit options from flags with INPUT_* env fallback.
+// Returns nil when no identity or signing is configured.
+func getCommitOptions(cmd *cobra.Command) (*github.CommitOptions, error) {
+	signer, err := getCommitSigner(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	author := commitIdentity(
+		getStringFlagWithEnvFallback(cmd, "commit-author-name", ""),
+		getStringFlagWithEnvFallback(cmd, "commit-author-email", ""),
+	)
+	committer := commitIdentity(
+		getStringFlagWithEnvFallback(cmd, "committer-name", ""),
+		getStringFlagWithEnvFallback(cmd, "committer-email", ""),
+	)
+
+	var coAuthors []string
+	if coAuthorsStr := getStringFlagWithEnvFallback(cmd, "co-authored-by", ""); coAuthorsStr != "" {
+		coAuthors = splitLabels(coAuthorsStr)
+	}
+
+	if signer == nil && author == nil && committer == nil && len(coAuthors) == 0 {
+		return nil, nil //nolint:nilnil // nil options mean default commit behavior
+	}
+
+	opts := &github.CommitOptions{
+		Author:    author,
+		Committer: committer,
+		CoAuthors: coAuthors,
+	}
+
+	if signer != nil {
+		opts.Signer = signer
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// getCommitSigner loads the signing key from --signing-key-file or INPUT_SIGNING_KEY.
+// The passphrase is only read from the environment so it never appears in process lists.
+func getCommitSigner(cmd *cobra.Command) (gh.MessageSignerFunc, error) {
+	key := []byte(os.Getenv(signingKeyEnv))
+
+	if keyFile := getStringFlagWithEnvFallback(cmd, "signing-key-file", ""); keyFile != "" {
+		//nolint:gosec // keyFile is controlled input from CLI flags
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading signing key file")
+		}
+
+		key = content
+	}
+
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	signer, err := github.NewCommitSigner(
+		getStringFlagWithEnvFallback(cmd, "signing-format", ""),
+		key,
+		[]byte(os.Getenv(signingKeyPassphraseEnv)),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading signing key")
+	}
+
+	return signer, nil
+}
+
+// commitIdentity returns a commit identity, or nil when both name and email are empty.
+func commitIdentity(name string, email string) *gh.CommitAuthor {
+	if name == "" && email == "" {
+		return nil
+	}
+
+	return &gh.CommitAuthor{
+		Name:  gh.Ptr(name),
+		Email: gh.Ptr(email),
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/smykla-labs/.github/pkg/github"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+
+	defaultInsightsDays = 14
+)
+
+var settingsRulesetInsightsCmd = &cobra.Command{
+	Use:   "ruleset-insights",
+	Short: "Report what rulesets in evaluate mode would have blocked",
+	Long: `Read the rule suites of repositories and report the violations of rulesets in
+evaluate enforcement by rule and actor, to decide whether they can be promoted to
+active. A ruleset is ready when it had no violations over the last --days days and
+was not changed during them. With --promote, ready rulesets are switched to active.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		// Get flags with env fallback
+		org := getPersistentStringFlagWithEnvFallback(cmd, "org", "GITHUB_REPOSITORY_OWNER")
+		dryRun := getPersistentBoolFlagWithEnvFallback(cmd, "dry-run")
+		format := getStringFlagWithEnvFallback(cmd, "format", "")
+		output := getStringFlagWithEnvFallback(cmd, "output", "")
+		promote := getBoolFlagWithEnvFallback(cmd, "promote")
+
+		days, err := getIntFlagWithEnvFallback(cmd, "days")
+		if err != nil {
+			return err
+		}
+
+		var repos []string
+
+		for repo := range strings.SplitSeq(getStringFlagWithEnvFallback(cmd, "repos", ""), ",") {
+			if repo = strings.TrimSpace(repo); repo != "" {
+				repos = append(repos, repo)
+			}
+		}
+
+		// Validate required fields
+		if org == "" {
+			return errors.New("org is required (set via --org flag, INPUT_ORG, or GITHUB_REPOSITORY_OWNER)")
+		}
+
+		if format != formatMarkdown && format != formatJSON {
+			return errors.Newf("unsupported format %q (markdown|json)", format)
+		}
+
+		log.Info("collecting ruleset insights",
+			"org", org,
+			"repos", len(repos),
+			"days", days,
+			"promote", promote,
+			"dry_run", dryRun,
+		)
+
+		client, err := setupGitHubClient(ctx, log, cmd)
+		if err != nil {
+			return err
+		}
+
+		report, err := github.RulesetInsights(ctx, log, client, org, repos, days, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if promote {
+			if err := github.PromoteReadyRulesets(ctx, log, client, report, dryRun); err != nil {
+				return err
+			}
+		}
+
+		var builder strings.Builder
+
+		if format == formatJSON {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return errors.Wrap(err, "marshaling ruleset insights")
+			}
+
+			builder.Write(data)
+			builder.WriteString("\n")
+		} else {
+			writeInsightsMarkdown(&builder, report)
+		}
+
+		return writeOutput(log, &builder, output)
+	},
+}
+
+// writeInsightsMarkdown renders a ruleset insights report as markdown.
+func writeInsightsMarkdown(builder *strings.Builder, report *github.RulesetInsightsReport) {
+	builder.WriteString("# 🔍 Ruleset Insights\n\n")
+	fmt.Fprintf(builder, "Rulesets in evaluate enforcement in **%s** over the last %d days.\n\n",
+		report.Org, report.Days)
+
+	var ready, violating int
+
+	for _, insight := range report.Rulesets {
+		if insight.Ready {
+			ready++
+		}
+
+		if len(insight.Violations) > 0 {
+			violating++
+		}
+	}
+
+	builder.WriteString("## Summary\n\n")
+	fmt.Fprintf(builder, "- Evaluated rulesets: %d\n", len(report.Rulesets))
+	fmt.Fprintf(builder, "- ✅ Ready for active: %d\n", ready)
+	fmt.Fprintf(builder, "- ⚠️ With violations: %d\n\n", violating)
+
+	if len(report.Rulesets) == 0 {
+		return
+	}
+
+	builder.WriteString("## Rulesets\n\n")
+	builder.WriteString("| Ruleset | Source | Repositories | Violations | Status |\n")
+	builder.WriteString("|---------|--------|--------------|------------|--------|\n")
+
+	for _, insight := range report.Rulesets {
+		var count int
+		for _, violation := range insight.Violations {
+			count += violation.Count
+		}
+
+		fmt.Fprintf(builder, "| `%s` | %s | %d | %d | %s |\n",
+			insight.Name, insight.Source, len(insight.Repositories), count,
+			insightStatus(insight))
+	}
+
+	builder.WriteString("\n")
+
+	for _, insight := range report.Rulesets {
+		if len(insight.Violations) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(builder, "### `%s`\n\n", insight.Name)
+		builder.WriteString("| Rule | Actor | Repository | Count | Last Seen |\n")
+		builder.WriteString("|------|-------|------------|-------|-----------|\n")
+
+		for _, violation := range insight.Violations {
+			fmt.Fprintf(builder, "| %s | %s | %s | %d | %s |\n",
+				violation.RuleType, violation.Actor, violation.Repository, violation.Count,
+				violation.LastSeen.UTC().Format(time.DateOnly))
+		}
+
+		builder.WriteString("\n")
+	}
+}
+
+// insightStatus describes whether a ruleset can be promoted to active.
+func insightStatus(insight *github.RulesetInsight) string {
+	switch {
+	case insight.Promoted:
+		return "🚀 Promoted"
+	case insight.Ready:
+		return "✅ Ready"
+	case len(insight.Violations) > 0:
+		return "⚠️ Violations"
+	default:
+		return "⏳ Changed recently"
+	}
+}
+
+func init() {
+	// Configure settings ruleset-insights command flags
+	settingsRulesetInsightsCmd.Flags().String(
+		"repos",
+		"",
+		"Comma-separated repositories to inspect (default: all unarchived repositories)",
+	)
+	settingsRulesetInsightsCmd.Flags().Int(
+		"days",
+		defaultInsightsDays,
+		"Days of rule suites to inspect; also the clean period required for promotion (1-30)",
+	)
+	settingsRulesetInsightsCmd.Flags().String(
+		"format",
+		formatMarkdown,
+		"Output format (markdown|json)",
+	)
+	settingsRulesetInsightsCmd.Flags().String(
+		"output",
+		"",
+		"Output file path (defaults to $GITHUB_STEP_SUMMARY, then stdout)",
+	)
+	settingsRulesetInsightsCmd.Flags().Bool(
+		"promote",
+		false,
+		"Switch rulesets without violations over the whole period to active enforcement",
+	)
+
+	settingsCmd.AddCommand(settingsRulesetInsightsCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/errors"
@@ -140,6 +141,48 @@ func getPersistentBoolFlagWithEnvFallback(cmd *cobra.Command, flagName string) b
 	return envVal == "true"
 }
 
+// getBoolFlagWithEnvFallback retrieves a bool flag value with environment variable fallback.
+// Priority: 1) explicit flag value (if changed), 2) INPUT_* env var, 3) flag default.
+func getBoolFlagWithEnvFallback(cmd *cobra.Command, flagName string) bool {
+	// Check if flag was explicitly set
+	if cmd.Flags().Changed(flagName) {
+		val, _ := cmd.Flags().GetBool(flagName)
+		return val
+	}
+
+	// Check INPUT_* env var
+	inputEnv := "INPUT_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+	if envVal := os.Getenv(inputEnv); envVal != "" {
+		return envVal == "true"
+	}
+
+	val, _ := cmd.Flags().GetBool(flagName)
+
+	return val
+}
+
+// getIntFlagWithEnvFallback retrieves an int flag value with environment variable fallback.
+// Priority: 1) explicit flag value (if changed), 2) INPUT_* env var, 3) flag default.
+func getIntFlagWithEnvFallback(cmd *cobra.Command, flagName string) (int, error) {
+	// Check if flag was explicitly set
+	if cmd.Flags().Changed(flagName) {
+		return cmd.Flags().GetInt(flagName)
+	}
+
+	// Check INPUT_* env var
+	inputEnv := "INPUT_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+	if envVal := os.Getenv(inputEnv); envVal != "" {
+		val, err := strconv.Atoi(envVal)
+		if err != nil {
+			return 0, errors.Wrapf(err, "parsing %s", inputEnv)
+		}
+
+		return val, nil
+	}
+
+	return cmd.Flags().GetInt(flagName)
+}
+
 // Cobra root command and initialization
 
 var rootCmd = &cobra.Command{
@@ -202,6 +245,12 @@ var smyklotCmd = &cobra.Command{
 	Long:  "Commands for synchronizing smyklot version references across repositories",
 }
 
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Combined files and smyklot synchronization commands",
+	Long:  "Commands for synchronizing files and smyklot workflows through a single pull request",
+}
+
 var settingsCmd = &cobra.Command{
 	Use:   "settings",
 	Short: "Repository settings synchronization commands",
@@ -241,6 +290,15 @@ type syncFunc[T any] func(
 	dryRun bool,
 ) (T, error)
 
+// syncBinder returns the sync function of a command, bound to the command-specific
+// flags read when the command runs. Invalid flags are reported before the sync starts.
+type syncBinder[T any] func(cmd *cobra.Command) (syncFunc[T], error)
+
+// withoutFlags binds a sync function reading no command-specific flags.
+func withoutFlags[T any](syncFn syncFunc[T]) syncBinder[T] {
+	return func(*cobra.Command) (syncFunc[T], error) { return syncFn, nil }
+}
+
 func getSyncParams(cmd *cobra.Command, configFlag string) (syncParams, error) {
 	// Use env fallback for org (GITHUB_REPOSITORY_OWNER) and repo (GITHUB_REPOSITORY)
 	org := getPersistentStringFlagWithEnvFallback(cmd, "org", "GITHUB_REPOSITORY_OWNER")
@@ -363,7 +421,7 @@ func createSyncCommand[T any](
 	configFlag string,
 	configFileLogKey string,
 	syncType string,
-	syncFn syncFunc[T],
+	bindSync syncBinder[T],
 ) *cobra.Command {
 	return &cobra.Command{
 		Use:   use,
@@ -378,6 +436,11 @@ func createSyncCommand[T any](
 				return err
 			}
 
+			syncFn, err := bindSync(cmd)
+			if err != nil {
+				return err
+			}
+
 			resultFile := getStringFlagWithEnvFallback(cmd, "result-file", "")
 
 			log.Info("starting "+syncType+" sync",
@@ -437,7 +500,7 @@ var labelsSyncCmd = createSyncCommand(
 	"labels-file",
 	"labels_file",
 	"label",
-	github.SyncLabels,
+	withoutFlags(github.SyncLabels),
 )
 
 var filesSyncCmd = &cobra.Command{
@@ -466,6 +529,8 @@ var filesSyncCmd = &cobra.Command{
 		// These only check INPUT_* env vars (no GitHub standard fallback)
 		branchPrefix := getStringFlagWithEnvFallback(cmd, "branch-prefix", "")
 		prLabelsStr := getStringFlagWithEnvFallback(cmd, "pr-labels", "")
+		mergeMethod := getStringFlagWithEnvFallback(cmd, "merge-method", "")
+		perFileCommits := getBoolFlagWithEnvFallback(cmd, "per-file-commits")
 
 		// Validate required fields
 		if org == "" {
@@ -480,10 +545,20 @@ var filesSyncCmd = &cobra.Command{
 			return errors.New("files-config is required (set via --files-config flag or INPUT_FILES_CONFIG)")
 		}
 
+		if err := github.ValidateMergeMethod(mergeMethod); err != nil {
+			return err
+		}
+
+		commitOpts, err := getCommitOptions(cmd)
+		if err != nil {
+			return err
+		}
+
 		log.Info("starting file sync",
 			"org", org,
 			"repo", repo,
 			"branch_prefix", branchPrefix,
+			"per_file_commits", perFileCommits,
 			"dry_run", dryRun,
 		)
 
@@ -523,6 +598,9 @@ var filesSyncCmd = &cobra.Command{
 			syncConfig,
 			branchPrefix,
 			prLabels,
+			mergeMethod,
+			perFileCommits,
+			commitOpts,
 			dryRun,
 		)
 		if err != nil {
@@ -631,6 +709,7 @@ var smyklotSyncCmd = &cobra.Command{
 		resultFile := getStringFlagWithEnvFallback(cmd, "result-file", "")
 		templatesDir := getStringFlagWithEnvFallback(cmd, "templates-dir", "smyklot-templates")
 		smyklotFilePath := getStringFlagWithEnvFallback(cmd, "smyklot-file", "")
+		mergeMethod := getStringFlagWithEnvFallback(cmd, "merge-method", "")
 
 		// Validate required fields
 		if org == "" {
@@ -653,6 +732,15 @@ var smyklotSyncCmd = &cobra.Command{
 			return errors.New("sha is required (set via --sha flag or INPUT_SHA)")
 		}
 
+		if err := github.ValidateMergeMethod(mergeMethod); err != nil {
+			return err
+		}
+
+		commitOpts, err := getCommitOptions(cmd)
+		if err != nil {
+			return err
+		}
+
 		log.Info("starting smyklot sync",
 			"org", org,
 			"repo", repo,
@@ -694,6 +782,8 @@ var smyklotSyncCmd = &cobra.Command{
 			syncConfig,
 			templatesDir,
 			smyklotFilePath,
+			mergeMethod,
+			commitOpts,
 			dryRun,
 		)
 		if err != nil {
@@ -716,16 +806,388 @@ var smyklotSyncCmd = &cobra.Command{
 	},
 }
 
+var bundleSyncCmd = createSyncCommand(
+	"sync",
+	"Sync files and smyklot workflows to a repository",
+	`Synchronize organization files and smyklot workflows to a target repository
+through a single branch and pull request. The bundle PR supersedes the
+standalone smyklot PR, which is closed when the bundle PR is published.`,
+	"files-config",
+	"files_config",
+	"bundle",
+	bindBundleSync,
+)
+
+// bindBundleSync binds bundle sync to the smyklot, pull request and commit flags.
+func bindBundleSync(cmd *cobra.Command) (syncFunc[*github.BundleSyncResult], error) {
+	smyklotVersion := getStringFlagWithEnvFallback(cmd, "version", "")
+	tag := getStringFlagWithEnvFallback(cmd, "tag", "")
+	sha := getStringFlagWithEnvFallback(cmd, "sha", "")
+	templatesDir := getStringFlagWithEnvFallback(cmd, "templates-dir", "smyklot-templates")
+	smyklotFilePath := getStringFlagWithEnvFallback(cmd, "smyklot-file", "")
+	branchPrefix := getStringFlagWithEnvFallback(cmd, "branch-prefix", "")
+	prLabelsStr := getStringFlagWithEnvFallback(cmd, "pr-labels", "")
+	mergeMethod := getStringFlagWithEnvFallback(cmd, "merge-method", "")
+	perFileCommits := getBoolFlagWithEnvFallback(cmd, "per-file-commits")
+
+	if smyklotVersion == "" {
+		return nil, errors.New("version is required (set via --version flag or INPUT_VERSION)")
+	}
+
+	if tag == "" {
+		return nil, errors.New("tag is required (set via --tag flag or INPUT_TAG)")
+	}
+
+	if sha == "" {
+		return nil, errors.New("sha is required (set via --sha flag or INPUT_SHA)")
+	}
+
+	if err := github.ValidateMergeMethod(mergeMethod); err != nil {
+		return nil, err
+	}
+
+	commitOpts, err := getCommitOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse PR labels
+	var prLabels []string
+	if prLabelsStr != "" {
+		prLabels = splitLabels(prLabelsStr)
+	}
+
+	return func(
+		ctx context.Context,
+		log *slog.Logger,
+		client *github.Client,
+		org string,
+		repo string,
+		filesConfig string,
+		syncConfig *configtypes.SyncConfig,
+		dryRun bool,
+	) (*github.BundleSyncResult, error) {
+		log.Info("syncing files and smyklot in a single PR",
+			"branch_prefix", branchPrefix,
+			"version", smyklotVersion,
+			"tag", tag,
+			"sha", sha,
+			"templates_dir", templatesDir,
+			"per_file_commits", perFileCommits,
+		)
+
+		return github.SyncBundle(
+			ctx,
+			log,
+			client,
+			org,
+			repo,
+			".github", // Source repo is always .github
+			filesConfig,
+			smyklotVersion,
+			tag,
+			sha,
+			syncConfig,
+			templatesDir,
+			smyklotFilePath,
+			branchPrefix,
+			prLabels,
+			mergeMethod,
+			perFileCommits,
+			commitOpts,
+			dryRun,
+		)
+	}, nil
+}
+
 var settingsSyncCmd = createSyncCommand(
 	"sync",
 	"Sync settings to a repository",
-	"Synchronize repository settings from a YAML file to a target repository",
+	`Synchronize repository settings from a YAML file to a target repository. Changes
+weakening the repository's security are blocked and reported unless --allow-weakening
+is set or the repository's sync config has a weakening_justification.`,
 	"settings-file",
 	"settings_file",
 	"settings",
-	github.SyncSettings,
+	bindSettingsSync,
 )
 
+// bindSettingsSync binds settings sync to the --allow-weakening flag.
+func bindSettingsSync(cmd *cobra.Command) (syncFunc[*github.SettingsSyncResult], error) {
+	allowWeakening := getBoolFlagWithEnvFallback(cmd, "allow-weakening")
+
+	return func(
+		ctx context.Context,
+		log *slog.Logger,
+		client *github.Client,
+		org string,
+		repo string,
+		settingsFile string,
+		syncConfig *configtypes.SyncConfig,
+		dryRun bool,
+	) (*github.SettingsSyncResult, error) {
+		if allowWeakening {
+			log.Info("allowing settings changes weakening security")
+		}
+
+		return github.SyncSettings(
+			ctx,
+			log,
+			client,
+			org,
+			repo,
+			settingsFile,
+			syncConfig,
+			allowWeakening,
+			dryRun,
+		)
+	}, nil
+}
+
+var settingsSyncOrgCmd = &cobra.Command{
+	Use:   "sync-org",
+	Short: "Sync organization rulesets",
+	Long: `Synchronize organization rulesets from the org_rulesets section of a settings
+YAML file. Runs once per organization rather than once per repository. Changes
+weakening the rulesets, including deleting them, are blocked and reported unless
+--allow-weakening is set.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		// Get flags with env fallback
+		org := getPersistentStringFlagWithEnvFallback(cmd, "org", "GITHUB_REPOSITORY_OWNER")
+		dryRun := getPersistentBoolFlagWithEnvFallback(cmd, "dry-run")
+		settingsFile := getStringFlagWithEnvFallback(cmd, "settings-file", "")
+		allowRemoval := getBoolFlagWithEnvFallback(cmd, "allow-removal")
+		allowWeakening := getBoolFlagWithEnvFallback(cmd, "allow-weakening")
+		resultFile := getStringFlagWithEnvFallback(cmd, "result-file", "")
+
+		// Validate required fields
+		if org == "" {
+			return errors.New("org is required (set via --org flag, INPUT_ORG, or GITHUB_REPOSITORY_OWNER)")
+		}
+
+		if settingsFile == "" {
+			return errors.New("settings-file is required (set via --settings-file flag or INPUT_SETTINGS_FILE)")
+		}
+
+		log.Info("starting organization settings sync",
+			"org", org,
+			"settings_file", settingsFile,
+			"allow_removal", allowRemoval,
+			"allow_weakening", allowWeakening,
+			"dry_run", dryRun,
+		)
+
+		client, err := setupGitHubClient(ctx, log, cmd)
+		if err != nil {
+			return err
+		}
+
+		result, err := github.SyncOrgRulesets(
+			ctx,
+			log,
+			client,
+			org,
+			settingsFile,
+			allowRemoval,
+			allowWeakening,
+			dryRun,
+		)
+		if err != nil {
+			// Write result file even on error (with failure status)
+			if writeErr := writeResultFile(log, resultFile, result); writeErr != nil {
+				log.Warn("failed to write result file", "error", writeErr)
+			}
+
+			return err
+		}
+
+		// Write result file on success
+		if err := writeResultFile(log, resultFile, result); err != nil {
+			return err
+		}
+
+		log.Info("organization settings sync completed successfully")
+
+		return nil
+	},
+}
+
+var settingsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export repository settings as a settings file",
+	Long: `Read the repository flags, features, security settings, branch protection rules
+and rulesets of a repository and print them as a settings YAML file. With
+--settings-file, print the sync-config.yml merge overrides that turn the organization
+settings into the repository's current settings instead.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		// Get flags with env fallback
+		org := getPersistentStringFlagWithEnvFallback(cmd, "org", "GITHUB_REPOSITORY_OWNER")
+		settingsFile := getStringFlagWithEnvFallback(cmd, "settings-file", "")
+		output := getStringFlagWithEnvFallback(cmd, "output", "")
+
+		repo := getStringFlagWithEnvFallback(cmd, "repo", "")
+		if repo == "" {
+			repo = getRepoFromEnv()
+		}
+
+		// Validate required fields
+		if org == "" {
+			return errors.New("org is required (set via --org flag, INPUT_ORG, or GITHUB_REPOSITORY_OWNER)")
+		}
+
+		if repo == "" {
+			return errors.New("repo is required (set via --repo flag, INPUT_REPO, or GITHUB_REPOSITORY)")
+		}
+
+		log.Info("exporting repository settings",
+			"org", org,
+			"repo", repo,
+			"settings_file", settingsFile,
+		)
+
+		client, err := setupGitHubClient(ctx, log, cmd)
+		if err != nil {
+			return err
+		}
+
+		data, err := github.ExportSettingsFile(ctx, log, client, org, repo, settingsFile)
+		if err != nil {
+			return err
+		}
+
+		if output == "" {
+			fmt.Print(string(data))
+
+			return nil
+		}
+
+		//nolint:mnd,gosec // exported settings are meant to be committed and shared
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			return errors.Wrap(err, "writing exported settings")
+		}
+
+		log.Info("exported settings written to file", "file", output)
+
+		return nil
+	},
+}
+
+var settingsMigrateProtectionCmd = &cobra.Command{
+	Use:   "migrate-protection",
+	Short: "Convert branch protection rules into rulesets",
+	Long: `Convert branch protection rules into equivalent rulesets and print them as a
+settings YAML file. The rules are read from --settings-file, or from the live
+protection of --repo. Settings that rulesets cannot express are reported as warnings.
+
+With --apply, the migration of a repository advances one stage per run: missing
+rulesets are created in evaluate enforcement, and once they have been promoted to
+active the branch protection rule is removed. Rules with unsupported settings keep
+their branch protection. With --apply, --settings-file names the settings file that
+syncs the repository instead: rules whose pattern it still lists under
+branch_protection are kept, as the next settings sync would recreate them.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		// Get flags with env fallback
+		org := getPersistentStringFlagWithEnvFallback(cmd, "org", "GITHUB_REPOSITORY_OWNER")
+		dryRun := getPersistentBoolFlagWithEnvFallback(cmd, "dry-run")
+		settingsFile := getStringFlagWithEnvFallback(cmd, "settings-file", "")
+		output := getStringFlagWithEnvFallback(cmd, "output", "")
+		apply := getBoolFlagWithEnvFallback(cmd, "apply")
+		repo := getStringFlagWithEnvFallback(cmd, "repo", "")
+
+		var (
+			migrations []github.ProtectionMigration
+			err        error
+		)
+
+		if settingsFile != "" && !apply {
+			log.Info("converting branch protection rules", "settings_file", settingsFile)
+
+			migrations, err = github.ConvertSettingsFileProtection(settingsFile)
+			if err != nil {
+				return err
+			}
+		} else {
+			if repo == "" {
+				repo = getRepoFromEnv()
+			}
+
+			// Validate required fields
+			if org == "" {
+				return errors.New("org is required (set via --org flag, INPUT_ORG, or GITHUB_REPOSITORY_OWNER)")
+			}
+
+			if repo == "" {
+				return errors.New("repo is required (set via --repo flag, INPUT_REPO, or GITHUB_REPOSITORY)")
+			}
+
+			log.Info("migrating branch protection rules",
+				"org", org,
+				"repo", repo,
+				"settings_file", settingsFile,
+				"apply", apply,
+				"dry_run", dryRun,
+			)
+
+			var client *github.Client
+
+			client, err = setupGitHubClient(ctx, log, cmd)
+			if err != nil {
+				return err
+			}
+
+			migrations, err = github.MigrateBranchProtection(
+				ctx, log, client, org, repo, settingsFile, apply, dryRun,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range migrations {
+			for _, unsupported := range migration.Unsupported {
+				log.Warn("branch protection setting has no ruleset equivalent",
+					"pattern", migration.Pattern,
+					"setting", unsupported,
+				)
+			}
+
+			if apply {
+				log.Info("branch protection migration", "pattern", migration.Pattern,
+					"status", migration.Status)
+			}
+		}
+
+		data, err := github.MarshalMigratedRulesets(migrations)
+		if err != nil {
+			return err
+		}
+
+		if output == "" {
+			fmt.Print(string(data))
+
+			return nil
+		}
+
+		//nolint:mnd,gosec // converted rulesets are meant to be committed and shared
+		if err := os.WriteFile(output, data, 0o644); err != nil {
+			return errors.Wrap(err, "writing converted rulesets")
+		}
+
+		log.Info("converted rulesets written to file", "file", output)
+
+		return nil
+	},
+}
+
 var reposListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List organization repositories",
@@ -848,6 +1310,11 @@ var configVerifyFileCmd = &cobra.Command{
 			return errors.New("generated-schema is required (path to externally generated schema file)")
 		}
 
+		commitOpts, err := getCommitOptions(cmd)
+		if err != nil {
+			return err
+		}
+
 		log.Info("verifying schema from external file",
 			"repo", repo,
 			"branch", branch,
@@ -883,6 +1350,7 @@ var configVerifyFileCmd = &cobra.Command{
 			branch,
 			schemaFile,
 			generatedSchema,
+			commitOpts,
 			dryRun,
 		)
 		if err != nil {
@@ -921,6 +1389,12 @@ func init() {
 	filesSyncCmd.Flags().String("config", "", "JSON sync config (optional)")
 	filesSyncCmd.Flags().String("branch-prefix", "chore/org-sync", "Branch name prefix")
 	filesSyncCmd.Flags().String("pr-labels", "ci/skip-all", "Comma-separated PR labels")
+	filesSyncCmd.Flags().String(
+		"merge-method",
+		"",
+		"Preferred auto-merge method (squash|merge|rebase), falls back to an allowed method",
+	)
+	filesSyncCmd.Flags().Bool("per-file-commits", false, "Create one commit per changed file")
 	filesSyncCmd.Flags().String("result-file", "", "Path to write result JSON (optional)")
 
 	// Configure files discover command flags
@@ -938,13 +1412,88 @@ func init() {
 		"Path to smyklot workflow templates directory",
 	)
 	smyklotSyncCmd.Flags().String("smyklot-file", "", "Path to smyklot.yml config file")
+	smyklotSyncCmd.Flags().String(
+		"merge-method",
+		"",
+		"Preferred auto-merge method (squash|merge|rebase), falls back to an allowed method",
+	)
 	smyklotSyncCmd.Flags().String("result-file", "", "Path to write result JSON (optional)")
 
+	// Configure bundle sync command flags
+	bundleSyncCmd.Flags().String("repo", "", "Target repository (e.g., 'myrepo')")
+	bundleSyncCmd.Flags().String("files-config", "", "JSON files config")
+	bundleSyncCmd.Flags().String("version", "", "Smyklot version (e.g., '1.9.2')")
+	bundleSyncCmd.Flags().String("tag", "", "Smyklot tag (e.g., 'v1.9.2')")
+	bundleSyncCmd.Flags().String("sha", "", "Smyklot commit SHA")
+	bundleSyncCmd.Flags().String("config", "", "JSON sync config (optional)")
+	bundleSyncCmd.Flags().String(
+		"templates-dir",
+		"smyklot-templates",
+		"Path to smyklot workflow templates directory",
+	)
+	bundleSyncCmd.Flags().String("smyklot-file", "", "Path to smyklot.yml config file")
+	bundleSyncCmd.Flags().String("branch-prefix", "chore/org-sync", "Branch name prefix")
+	bundleSyncCmd.Flags().String("pr-labels", "ci/skip-all", "Comma-separated PR labels")
+	bundleSyncCmd.Flags().String(
+		"merge-method",
+		"",
+		"Preferred auto-merge method (squash|merge|rebase), falls back to an allowed method",
+	)
+	bundleSyncCmd.Flags().Bool("per-file-commits", false, "Create one commit per changed file")
+	bundleSyncCmd.Flags().String("result-file", "", "Path to write result JSON (optional)")
+
 	// Configure settings sync command flags
 	settingsSyncCmd.Flags().String("repo", "", "Target repository (e.g., 'myrepo')")
 	settingsSyncCmd.Flags().String("settings-file", "", "Path to settings YAML file")
 	settingsSyncCmd.Flags().String("config", "", "JSON sync config (optional)")
 	settingsSyncCmd.Flags().String("result-file", "", "Path to write result JSON (optional)")
+	settingsSyncCmd.Flags().Bool(
+		"allow-weakening",
+		false,
+		"Apply changes weakening security, e.g. disabling secret scanning or deleting rulesets",
+	)
+
+	// Configure settings sync-org command flags
+	settingsSyncOrgCmd.Flags().String("settings-file", "", "Path to settings YAML file")
+	settingsSyncOrgCmd.Flags().Bool(
+		"allow-removal",
+		false,
+		"Delete org-sync/ organization rulesets not in the settings file",
+	)
+	settingsSyncOrgCmd.Flags().Bool(
+		"allow-weakening",
+		false,
+		"Apply changes weakening organization rulesets, e.g. deleting or deactivating them",
+	)
+	settingsSyncOrgCmd.Flags().String("result-file", "", "Path to write result JSON (optional)")
+
+	// Configure settings export command flags
+	settingsExportCmd.Flags().String("repo", "", "Target repository (e.g., 'myrepo')")
+	settingsExportCmd.Flags().String(
+		"settings-file",
+		"",
+		"Organization settings YAML file; export merge overrides relative to it (optional)",
+	)
+	settingsExportCmd.Flags().String("output", "", "Path to write the exported YAML (default: stdout)")
+
+	// Configure settings migrate-protection command flags
+	settingsMigrateProtectionCmd.Flags().String("repo", "", "Target repository (e.g., 'myrepo')")
+	settingsMigrateProtectionCmd.Flags().String(
+		"settings-file",
+		"",
+		"Settings YAML file to convert instead of the live protection of --repo "+
+			"(with --apply: keep rules this file still manages)",
+	)
+	settingsMigrateProtectionCmd.Flags().Bool(
+		"apply",
+		false,
+		"Create the rulesets in evaluate enforcement and remove protection once they are active",
+	)
+	settingsMigrateProtectionCmd.Flags().String(
+		"output",
+		"",
+		"Path to write the converted rulesets YAML (default: stdout)",
+	)
 
 	// Configure repos list command flags
 	reposListCmd.Flags().String("format", "json", "Output format (json|names)")
@@ -955,11 +1504,27 @@ func init() {
 	configVerifyFileCmd.Flags().String("schema-file", "schemas/sync-config.schema.json", "Path to committed schema file")
 	configVerifyFileCmd.Flags().String("generated-schema", "", "Path to externally generated schema file")
 
+	// Configure commit identity and signing flags for commands creating commits
+	for _, cmd := range []*cobra.Command{
+		filesSyncCmd,
+		smyklotSyncCmd,
+		bundleSyncCmd,
+		configVerifyFileCmd,
+	} {
+		addCommitFlags(cmd)
+	}
+
 	// Build command tree
 	labelsCmd.AddCommand(labelsSyncCmd)
 	filesCmd.AddCommand(filesSyncCmd, filesDiscoverCmd)
 	smyklotCmd.AddCommand(smyklotSyncCmd)
-	settingsCmd.AddCommand(settingsSyncCmd)
+	bundleCmd.AddCommand(bundleSyncCmd)
+	settingsCmd.AddCommand(
+		settingsSyncCmd,
+		settingsSyncOrgCmd,
+		settingsExportCmd,
+		settingsMigrateProtectionCmd,
+	)
 	reposCmd.AddCommand(reposListCmd)
 	configCmd.AddCommand(configVerifyFileCmd)
 
@@ -968,6 +1533,7 @@ func init() {
 	rootCmd.AddCommand(labelsCmd)
 	rootCmd.AddCommand(filesCmd)
 	rootCmd.AddCommand(smyklotCmd)
+	rootCmd.AddCommand(bundleCmd)
 	rootCmd.AddCommand(settingsCmd)
 	rootCmd.AddCommand(reposCmd)
 	rootCmd.AddCommand(configCmd)
@@ -22,6 +22,7 @@ const (
 	syncTypeFiles    = "files"
 	syncTypeSettings = "settings"
 	syncTypeSmyklot  = "smyklot"
+	syncTypeBundle   = "bundle"
 
 	filterAll       = "all"
 	filterFailures  = "failures"
@@ -132,7 +133,13 @@ func generateSingleWorkflowSummary(
 // inferSyncType infers the sync type from result file names in the directory.
 func inferSyncType(log *logger.Logger, resultsDir string) (string, error) {
 	// Check for each type pattern
-	types := []string{syncTypeLabels, syncTypeFiles, syncTypeSettings, syncTypeSmyklot}
+	types := []string{
+		syncTypeLabels,
+		syncTypeFiles,
+		syncTypeSettings,
+		syncTypeSmyklot,
+		syncTypeBundle,
+	}
 
 	for _, syncType := range types {
 		pattern := filepath.Join(resultsDir, syncType+"-result-*.json")
@@ -256,6 +263,14 @@ func parseResultByType(syncType string, data []byte) (any, error) {
 
 		return &result, nil
 
+	case syncTypeBundle:
+		var result github.BundleSyncResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+
+		return &result, nil
+
 	default:
 		return nil, errors.Newf("unknown sync type: %s", syncType)
 	}
@@ -341,6 +356,8 @@ func extractStatus(result any) github.SyncStatus {
 		return r.Status
 	case *github.SmyklotSyncResult:
 		return r.Status
+	case *github.BundleSyncResult:
+		return r.Status
 	default:
 		return ""
 	}
@@ -571,6 +588,8 @@ func formatResultsTable(syncType string, results []any) string {
 		return formatSettingsTable(results)
 	case syncTypeSmyklot:
 		return formatSmyklotTable(results)
+	case syncTypeBundle:
+		return formatBundleTable(results)
 	default:
 		return ""
 	}
@@ -625,7 +644,7 @@ func formatFilesTable(results []any) string {
 
 		status := formatStatusWithError(r.Status, r.SkippedReason, r.ErrorMessage)
 		filesChanged := buildFilesChangedSummary(r.CreatedFiles, r.UpdatedFiles, r.DeletedFiles)
-		prLink := formatPRLink(r.PRURL, r.PRNumber)
+		prLink := formatPRLink(r.PRURL, r.PRNumber, r.AutoMerge)
 
 		fmt.Fprintf(&builder, "| %s | %s | %s | %s | %s |\n",
 			r.Repo, status, filesChanged, prLink,
@@ -689,13 +708,26 @@ func appendFileList(builder *strings.Builder, header string, files []string) {
 	}
 }
 
-// formatPRLink formats a PR link if URL is present.
-func formatPRLink(prURL string, prNumber int) string {
+// formatPRLink formats a PR link if URL is present, followed by the auto-merge route.
+func formatPRLink(prURL string, prNumber int, autoMerge *github.AutoMergeResult) string {
 	if prURL == "" {
 		return "-"
 	}
 
-	return fmt.Sprintf("[#%d](%s)", prNumber, prURL)
+	link := fmt.Sprintf("[#%d](%s)", prNumber, prURL)
+
+	if autoMerge == nil {
+		return link
+	}
+
+	switch autoMerge.Route {
+	case github.AutoMergeRouteAutoMerge:
+		return fmt.Sprintf("%s<br/>auto-merge (%s)", link, autoMerge.Method)
+	case github.AutoMergeRouteMergeQueue:
+		return link + "<br/>merge queue"
+	default:
+		return fmt.Sprintf("%s<br/>auto-merge %s", link, autoMerge.Route)
+	}
 }
 
 // formatSettingsTable formats settings results as a markdown table.
@@ -720,8 +752,22 @@ func formatSettingsTable(results []any) string {
 			status = fmt.Sprintf("%s %s: %s", statusEmoji, r.Status, r.ErrorMessage)
 		}
 
-		fmt.Fprintf(&builder, "| %s | %s | %d | %s |\n",
-			r.Repo, status, r.ChangesApplied,
+		changes := strconv.Itoa(r.ChangesApplied) +
+			buildProfileSummary(r.Profile) +
+			buildBranchProtectionSummary(r.BranchProtection) +
+			buildActionsSummary(r.Actions, r.Ignored) +
+			buildSecuritySummary(r.Security, r.Unsupported) +
+			buildRulesetsSummary(r.Rulesets) +
+			buildEnvironmentsSummary(r.Environments) +
+			buildVariablesSummary(r.Variables) +
+			buildSecretsSummary(r.Secrets) +
+			buildWebhooksSummary(r.Webhooks) +
+			buildAccessSummary(r.Access) +
+			buildCustomPropertiesSummary(r.CustomProperties) +
+			buildBlockedSummary(r.Blocked)
+
+		fmt.Fprintf(&builder, "| %s | %s | %s | %s |\n",
+			r.Repo, status, changes,
 			formatDuration(time.Duration(r.Duration)))
 	}
 
@@ -730,6 +776,237 @@ func formatSettingsTable(results []any) string {
 	return builder.String()
 }
 
+// buildBranchProtectionSummary lists changed branch protection fields per rule
+// pattern, or per branch when protection was applied branch by branch.
+func buildBranchProtectionSummary(diffs []github.BranchProtectionDiff) string {
+	var builder strings.Builder
+
+	for _, diff := range diffs {
+		fields := make([]string, 0, len(diff.Fields))
+		for _, field := range diff.Fields {
+			fields = append(fields, field.Field)
+		}
+
+		target := diff.Branch
+		if target == "" {
+			target = diff.Pattern
+		}
+
+		fmt.Fprintf(&builder, "<br/>• `%s`: %s", target, strings.Join(fields, ", "))
+	}
+
+	return builder.String()
+}
+
+// buildProfileSummary names the settings profile applied to a repository.
+func buildProfileSummary(profile string) string {
+	if profile == "" {
+		return ""
+	}
+
+	return "<br/>• profile: `" + profile + "`"
+}
+
+// buildActionsSummary lists changed Actions settings and the ignored ones.
+func buildActionsSummary(diffs []github.FieldDiff, ignored []string) string {
+	var builder strings.Builder
+
+	if len(diffs) > 0 {
+		fields := make([]string, 0, len(diffs))
+		for _, diff := range diffs {
+			fields = append(fields, diff.Field)
+		}
+
+		builder.WriteString("<br/>• actions: " + strings.Join(fields, ", "))
+	}
+
+	if len(ignored) > 0 {
+		builder.WriteString("<br/>• ignored: " + strings.Join(ignored, ", "))
+	}
+
+	return builder.String()
+}
+
+// buildSecuritySummary lists changed security settings and the ones the repository
+// does not support.
+func buildSecuritySummary(diffs []github.FieldDiff, unsupported []string) string {
+	var builder strings.Builder
+
+	if len(diffs) > 0 {
+		fields := make([]string, 0, len(diffs))
+		for _, diff := range diffs {
+			fields = append(fields, strings.TrimPrefix(diff.Field, "security."))
+		}
+
+		builder.WriteString("<br/>• security: " + strings.Join(fields, ", "))
+	}
+
+	if len(unsupported) > 0 {
+		settings := make([]string, 0, len(unsupported))
+		for _, setting := range unsupported {
+			settings = append(settings, strings.TrimPrefix(setting, "security."))
+		}
+
+		builder.WriteString("<br/>• unsupported: " + strings.Join(settings, ", "))
+	}
+
+	return builder.String()
+}
+
+// buildRulesetsSummary lists created, updated and deleted rulesets.
+func buildRulesetsSummary(outcomes []github.RulesetOutcome) string {
+	var builder strings.Builder
+
+	for _, outcome := range outcomes {
+		if outcome.Action == github.ResourceActionUnchanged {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "<br/>• ruleset `%s`: %s", outcome.Name, outcome.Action)
+
+		if len(outcome.Fields) > 0 {
+			fields := make([]string, 0, len(outcome.Fields))
+			for _, field := range outcome.Fields {
+				fields = append(fields, field.Field)
+			}
+
+			fmt.Fprintf(&builder, " (%s)", strings.Join(fields, ", "))
+		}
+	}
+
+	return builder.String()
+}
+
+// buildEnvironmentsSummary lists created, updated and deleted environments.
+func buildEnvironmentsSummary(outcomes []github.EnvironmentOutcome) string {
+	var builder strings.Builder
+
+	for _, outcome := range outcomes {
+		if outcome.Action == github.ResourceActionUnchanged {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "<br/>• environment `%s`: %s", outcome.Name, outcome.Action)
+
+		if len(outcome.Fields) > 0 {
+			fields := make([]string, 0, len(outcome.Fields))
+			for _, field := range outcome.Fields {
+				fields = append(fields, field.Field)
+			}
+
+			fmt.Fprintf(&builder, " (%s)", strings.Join(fields, ", "))
+		}
+	}
+
+	return builder.String()
+}
+
+// buildVariablesSummary lists created and updated variables.
+func buildVariablesSummary(diffs []github.FieldDiff) string {
+	if len(diffs) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		names = append(names, strings.TrimPrefix(diff.Field, "variables."))
+	}
+
+	return "<br/>• variables: " + strings.Join(names, ", ")
+}
+
+// buildSecretsSummary lists created and updated secrets.
+func buildSecretsSummary(outcomes []github.SecretOutcome) string {
+	var builder strings.Builder
+
+	for _, outcome := range outcomes {
+		if outcome.Action == github.ResourceActionUnchanged {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "<br/>• secret `%s`: %s", outcome.Name, outcome.Action)
+	}
+
+	return builder.String()
+}
+
+// buildWebhooksSummary lists created, updated and deleted webhooks.
+func buildWebhooksSummary(outcomes []github.WebhookOutcome) string {
+	var builder strings.Builder
+
+	for _, outcome := range outcomes {
+		if outcome.Action == github.ResourceActionUnchanged {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "<br/>• webhook `%s`: %s", outcome.URL, outcome.Action)
+
+		if len(outcome.Fields) > 0 {
+			fields := make([]string, 0, len(outcome.Fields))
+			for _, field := range outcome.Fields {
+				fields = append(fields, field.Field)
+			}
+
+			fmt.Fprintf(&builder, " (%s)", strings.Join(fields, ", "))
+		}
+	}
+
+	return builder.String()
+}
+
+// buildAccessSummary lists granted, changed and revoked team and collaborator access.
+func buildAccessSummary(changes []github.AccessChange) string {
+	var builder strings.Builder
+
+	for _, change := range changes {
+		switch {
+		case change.Current == "":
+			fmt.Fprintf(&builder, "<br/>• %s `%s`: %s %s",
+				change.Kind, change.Name, change.Change, change.Desired)
+		case change.Change == github.AccessRevoked:
+			fmt.Fprintf(&builder, "<br/>• %s `%s`: %s %s",
+				change.Kind, change.Name, change.Change, change.Current)
+		default:
+			fmt.Fprintf(&builder, "<br/>• %s `%s`: %s %s → %s",
+				change.Kind, change.Name, change.Change, change.Current, change.Desired)
+		}
+	}
+
+	return builder.String()
+}
+
+// buildCustomPropertiesSummary lists changed custom property values.
+func buildCustomPropertiesSummary(diffs []github.FieldDiff) string {
+	if len(diffs) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		names = append(names, strings.TrimPrefix(diff.Field, "custom_properties."))
+	}
+
+	return "<br/>• custom properties: " + strings.Join(names, ", ")
+}
+
+// buildBlockedSummary lists the changes not applied because they would weaken the
+// repository's security.
+func buildBlockedSummary(blocked []github.BlockedChange) string {
+	var builder strings.Builder
+
+	for _, change := range blocked {
+		target := change.Section
+		if change.Target != "" {
+			target += " `" + change.Target + "`"
+		}
+
+		fmt.Fprintf(&builder, "<br/>• 🛑 blocked %s: %s", target,
+			strings.TrimPrefix(change.Field, "security."))
+	}
+
+	return builder.String()
+}
+
 // formatSmyklotTable formats smyklot results as a markdown table.
 //
 //nolint:dupl // Similar table structure to formatFilesTable but different result types and fields
@@ -751,7 +1028,7 @@ func formatSmyklotTable(results []any) string {
 			r.ReplacedFiles,
 			r.VersionOnlyFiles,
 		)
-		prLink := formatPRLink(r.PRURL, r.PRNumber)
+		prLink := formatPRLink(r.PRURL, r.PRNumber, r.AutoMerge)
 
 		fmt.Fprintf(&builder, "| %s | %s | %s | %s | %s |\n",
 			r.Repo, status, workflowsChanged, prLink,
@@ -763,6 +1040,38 @@ func formatSmyklotTable(results []any) string {
 	return builder.String()
 }
 
+// formatBundleTable formats bundle results as a markdown table.
+func formatBundleTable(results []any) string {
+	var builder strings.Builder
+
+	builder.WriteString("| Repository | Status | Files Changed | Workflows Changed | PR | Duration |\n")
+	builder.WriteString("|------------|--------|---------------|-------------------|----|----------|\n")
+
+	for _, result := range results {
+		r, ok := result.(*github.BundleSyncResult)
+		if !ok {
+			continue
+		}
+
+		status := formatStatusWithError(r.Status, r.SkippedReason, r.ErrorMessage)
+		filesChanged := buildFilesChangedSummary(r.CreatedFiles, r.UpdatedFiles, r.DeletedFiles)
+		workflowsChanged := buildWorkflowsChangedSummary(
+			r.InstalledFiles,
+			r.ReplacedFiles,
+			r.VersionOnlyFiles,
+		)
+		prLink := formatPRLink(r.PRURL, r.PRNumber, r.AutoMerge)
+
+		fmt.Fprintf(&builder, "| %s | %s | %s | %s | %s | %s |\n",
+			r.Repo, status, filesChanged, workflowsChanged, prLink,
+			formatDuration(time.Duration(r.Duration)))
+	}
+
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
 // buildWorkflowsChangedSummary builds a summary of workflow changes for display.
 func buildWorkflowsChangedSummary(
 	installed []string,
@@ -821,6 +1130,8 @@ func calculateStats(results []any) stats {
 			s.updateTiming(r.StartedAt, r.CompletedAt)
 		case *github.SmyklotSyncResult:
 			s.updateTiming(r.StartedAt, r.CompletedAt)
+		case *github.BundleSyncResult:
+			s.updateTiming(r.StartedAt, r.CompletedAt)
 		}
 	}
 
@@ -911,6 +1222,8 @@ func getSyncTypeEmoji(syncType string) string {
 		return "⚙️"
 	case syncTypeSmyklot:
 		return "🤖"
+	case syncTypeBundle:
+		return "📦"
 	default:
 		return "🔄"
 	}
@@ -975,7 +1288,7 @@ func formatDuration(d time.Duration) string {
 
 func init() {
 	// Configure summary generate command flags
-	summaryGenerateCmd.Flags().String("type", "", "Sync type (labels|files|settings|smyklot|all)")
+	summaryGenerateCmd.Flags().String("type", "", "Sync type (labels|files|settings|smyklot|bundle|all)")
 	summaryGenerateCmd.Flags().String("results-dir", "", "Directory containing result JSON files")
 	summaryGenerateCmd.Flags().String(
 		"output",
@@ -165,18 +165,59 @@ type SettingsConfig struct {
 	// Settings sections to merge with repo-specific overrides instead of replacing. Allows
 	// customizing specific fields while inheriting org defaults
 	Merge []SettingsMergeConfig `json:"merge" yaml:"merge"`
+	// When true, rulesets created by the organization sync (names starting with "org-sync/"),
+	// deployment environments and webhooks that are NOT in the central settings will be
+	// DELETED. Rulesets without the prefix are never removed
+	AllowRemoval bool `json:"allow_removal" jsonschema:"default=false" yaml:"allow_removal"`
+	// Custom property values of this repository keyed by property name. Take precedence over
+	// the custom_properties defaults of the central settings. Use null to clear a value
+	CustomProperties map[string]any `json:"custom_properties" yaml:"custom_properties"`
+	// Name of the settings profile to apply, overriding profile selection by topic, custom
+	// property or repository name
+	Profile string `json:"profile" jsonschema:"minLength=1" yaml:"profile"`
+	// Reason for allowing changes that weaken the repository's security, such as disabling
+	// secret scanning, allowing force pushes, removing required status checks or deleting
+	// rulesets. Without it such changes are blocked and reported
+	WeakeningJustification string `json:"weakening_justification" jsonschema:"minLength=1" yaml:"weakening_justification"`
+}
+
+// Defines a named settings profile layered on top of the base settings. Repositories are
+// assigned the first profile whose selectors match, unless sync.settings.profile names one
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type SettingsProfileConfig struct {
+	// Profile name (e.g., "library", "service", "archive-ready")
+	Name string `json:"name" jsonschema:"minLength=1,required" yaml:"name"`
+	// Selectors assigning repositories to this profile. A repository matches when any
+	// selector matches
+	Match SettingsProfileMatchConfig `json:"match" yaml:"match"`
+	// Settings deep-merged onto the base settings. Structure matches the settings section,
+	// arrays such as branch_protection replace the base ones
+	Settings map[string]any `json:"settings" yaml:"settings"`
+}
+
+// Selects the repositories a settings profile applies to
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type SettingsProfileMatchConfig struct {
+	// Repositories with any of these topics
+	Topics []string `json:"topics" jsonschema:"uniqueItems=true" yaml:"topics"`
+	// Repositories with any of these custom property values, keyed by property name
+	CustomProperties map[string]string `json:"custom_properties" yaml:"custom_properties"`
+	// Regular expression matched against the repository name
+	NamePattern string `json:"name_pattern" jsonschema:"format=regex" yaml:"name_pattern"`
 }
 
 // Configures merge behavior for specific settings sections, allowing repo-specific customization
 // of fields while inheriting org defaults. Use section names like "repository", "features",
-// "security" for top-level sections, or branch protection patterns and ruleset names for array
-// items
+// "security", "actions", "access" for top-level sections, or branch protection patterns,
+// ruleset names and environment names for array items
 //
 //nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
 type SettingsMergeConfig struct {
-	// Section identifier to merge. Use "repository", "features", or "security" for top-level
-	// sections. For branch protection rules, use the pattern (e.g., "main", "release/*"). For
-	// rulesets, use the ruleset name
+	// Section identifier to merge. Use "repository", "features", "security", "actions", or
+	// "access" for top-level sections. For branch protection rules, use the pattern (e.g., "main",
+	// "release/*"). For rulesets and environments, use their name
 	Section string `json:"section" jsonschema:"minLength=1,required" yaml:"section"`
 	// Merge strategy to use. deep-merge (default) recursively merges nested objects; shallow-merge
 	// only merges top-level keys
@@ -207,6 +248,51 @@ type RepositorySettingsConfig struct {
 	SquashMergeCommitTitle *string `json:"squash_merge_commit_title" jsonschema:"enum=COMMIT_OR_PR_TITLE,enum=PR_TITLE" yaml:"squash_merge_commit_title"`
 	// Message format for squash merge commits (PR_BODY, COMMIT_MESSAGES, or BLANK)
 	SquashMergeCommitMessage *string `json:"squash_merge_commit_message" jsonschema:"enum=PR_BODY,enum=COMMIT_MESSAGES,enum=BLANK" yaml:"squash_merge_commit_message"`
+	// Title format for merge commits (PR_TITLE or MERGE_MESSAGE)
+	MergeCommitTitle *string `json:"merge_commit_title" jsonschema:"enum=PR_TITLE,enum=MERGE_MESSAGE" yaml:"merge_commit_title"`
+	// Message format for merge commits (PR_BODY, PR_TITLE, or BLANK)
+	MergeCommitMessage *string `json:"merge_commit_message" jsonschema:"enum=PR_BODY,enum=PR_TITLE,enum=BLANK" yaml:"merge_commit_message"`
+	// Require contributors to sign off on commits made through the web interface
+	WebCommitSignoffRequired *bool `json:"web_commit_signoff_required" yaml:"web_commit_signoff_required"`
+	// Allow forking of private repositories (requires the organization to allow it)
+	AllowForking *bool `json:"allow_forking" yaml:"allow_forking"`
+	// Make the repository available as a template for new repositories
+	IsTemplate *bool `json:"is_template" yaml:"is_template"`
+	// Enable downloads for the repository
+	HasDownloads *bool `json:"has_downloads" yaml:"has_downloads"`
+	// Repository visibility (public, private, or internal)
+	Visibility *string `json:"visibility" jsonschema:"enum=public,enum=private,enum=internal" yaml:"visibility"`
+	// Default branch of the repository. The branch must already exist
+	DefaultBranch *string `json:"default_branch" jsonschema:"minLength=1" yaml:"default_branch"`
+	// Repository description. Supports {{REPO_NAME}}, {{OWNER}}, {{DEFAULT_BRANCH}} and
+	// {{LANGUAGE}} placeholders
+	Description *string `json:"description" yaml:"description"`
+	// Repository homepage URL. Supports {{REPO_NAME}}, {{OWNER}}, {{DEFAULT_BRANCH}} and
+	// {{LANGUAGE}} placeholders
+	Homepage *string `json:"homepage" yaml:"homepage"`
+	// Repository topics
+	Topics *TopicsConfig `json:"topics" yaml:"topics"`
+}
+
+// TopicsMode controls how configured topics are applied to a repository.
+type TopicsMode string
+
+const (
+	// TopicsModeAddOnly adds missing topics and keeps topics set on the repository.
+	TopicsModeAddOnly TopicsMode = "add-only"
+	// TopicsModeExact replaces the repository topics with the configured ones.
+	TopicsModeExact TopicsMode = "exact"
+)
+
+// Configures repository topics and whether topics not listed are kept
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type TopicsConfig struct {
+	// Topics to set (lowercase letters, numbers and hyphens)
+	Names []string `json:"names" jsonschema:"uniqueItems=true,required" yaml:"names"`
+	// How topics are applied: add-only keeps existing topics, exact removes topics not listed.
+	// Default: add-only
+	Mode TopicsMode `json:"mode" jsonschema:"default=add-only,enum=add-only,enum=exact" yaml:"mode"`
 }
 
 // Controls which GitHub features are enabled for the repository (Issues, Wiki, Projects,
@@ -224,8 +310,9 @@ type FeaturesConfig struct {
 	HasDiscussions *bool `json:"has_discussions" yaml:"has_discussions"`
 }
 
-// Configures GitHub Advanced Security features including secret scanning and Dependabot
-// security updates
+// Configures GitHub Advanced Security features including secret scanning, code scanning,
+// Dependabot and private vulnerability reporting. Features not available for a repository
+// are reported as unsupported instead of failing the sync
 //
 //nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
 type SecurityConfig struct {
@@ -234,8 +321,67 @@ type SecurityConfig struct {
 	// Enable secret scanning push protection (requires GitHub Advanced Security and secret
 	// scanning enabled)
 	SecretScanningPushProtection *string `json:"secret_scanning_push_protection" jsonschema:"enum=enabled,enum=disabled" yaml:"secret_scanning_push_protection"`
+	// Enable validity checks of secrets detected by secret scanning
+	SecretScanningValidityChecks *string `json:"secret_scanning_validity_checks" jsonschema:"enum=enabled,enum=disabled" yaml:"secret_scanning_validity_checks"`
+	// Enable secret scanning of non-provider patterns such as private keys and passwords
+	SecretScanningNonProviderPatterns *string `json:"secret_scanning_non_provider_patterns" jsonschema:"enum=enabled,enum=disabled" yaml:"secret_scanning_non_provider_patterns"`
 	// Enable Dependabot security updates
 	DependabotSecurityUpdates *string `json:"dependabot_security_updates" jsonschema:"enum=enabled,enum=disabled" yaml:"dependabot_security_updates"`
+	// Enable Dependabot vulnerability alerts and the dependency graph
+	VulnerabilityAlerts *string `json:"vulnerability_alerts" jsonschema:"enum=enabled,enum=disabled" yaml:"vulnerability_alerts"`
+	// Allow security researchers to privately report vulnerabilities (public repositories only)
+	PrivateVulnerabilityReporting *string `json:"private_vulnerability_reporting" jsonschema:"enum=enabled,enum=disabled" yaml:"private_vulnerability_reporting"`
+	// Code scanning default setup
+	CodeScanningDefaultSetup *CodeScanningDefaultSetupConfig `json:"code_scanning_default_setup" yaml:"code_scanning_default_setup"`
+}
+
+// Configures code scanning default setup, which analyzes a repository with CodeQL without a
+// workflow file
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type CodeScanningDefaultSetupConfig struct {
+	// Whether default setup is configured (configured or not-configured)
+	State *string `json:"state" jsonschema:"enum=configured,enum=not-configured" yaml:"state"`
+	// CodeQL languages to analyze (e.g., "go", "javascript-typescript", "actions"). Defaults to
+	// the languages detected in the repository
+	Languages []string `json:"languages" yaml:"languages"`
+	// CodeQL query suite (default or extended)
+	QuerySuite *string `json:"query_suite" jsonschema:"enum=default,enum=extended" yaml:"query_suite"`
+}
+
+// Configures GitHub Actions permissions, the default GITHUB_TOKEN permissions and artifact
+// retention
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type ActionsConfig struct {
+	// Enable GitHub Actions for the repository
+	Enabled *bool `json:"enabled" yaml:"enabled"`
+	// Which actions and reusable workflows may run (all, local_only, or selected)
+	AllowedActions *string `json:"allowed_actions" jsonschema:"enum=all,enum=local_only,enum=selected" yaml:"allowed_actions"`
+	// Actions allowed when allowed_actions is selected
+	SelectedActions *SelectedActionsConfig `json:"selected_actions" yaml:"selected_actions"`
+	// Default permissions of the GITHUB_TOKEN (read or write)
+	DefaultWorkflowPermissions *string `json:"default_workflow_permissions" jsonschema:"enum=read,enum=write" yaml:"default_workflow_permissions"`
+	// Allow GitHub Actions to create and approve pull requests
+	CanApprovePullRequestReviews *bool `json:"can_approve_pull_request_reviews" yaml:"can_approve_pull_request_reviews"`
+	// Which outside contributors need approval before fork pull request workflows run
+	// (first_time_contributors_new_to_github, first_time_contributors, or
+	// all_external_contributors)
+	ForkPRApprovalPolicy *string `json:"fork_pr_approval_policy" jsonschema:"enum=first_time_contributors_new_to_github,enum=first_time_contributors,enum=all_external_contributors" yaml:"fork_pr_approval_policy"`
+	// Days to keep workflow artifacts and logs (1-400, limited by the organization maximum)
+	ArtifactAndLogRetentionDays *int `json:"artifact_and_log_retention_days" jsonschema:"maximum=400,minimum=1" yaml:"artifact_and_log_retention_days"`
+}
+
+// Lists the actions and reusable workflows allowed to run when allowed_actions is selected
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type SelectedActionsConfig struct {
+	// Allow actions created by GitHub
+	GithubOwnedAllowed *bool `json:"github_owned_allowed" yaml:"github_owned_allowed"`
+	// Allow actions by verified Marketplace creators
+	VerifiedAllowed *bool `json:"verified_allowed" yaml:"verified_allowed"`
+	// Action and reusable workflow patterns to allow (e.g., "smykla-labs/*", "docker/login-action@*")
+	PatternsAllowed []string `json:"patterns_allowed" yaml:"patterns_allowed"`
 }
 
 // Configures protection rules for branches including required status checks, required reviews,
@@ -243,7 +389,8 @@ type SecurityConfig struct {
 //
 //nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
 type BranchProtectionRuleConfig struct {
-	// Branch name pattern
+	// Branch name pattern in GitHub fnmatch syntax, where "*" does not match "/" and "**/" matches
+	// nested paths
 	Pattern string `json:"pattern" jsonschema:"examples=main,examples=release/*,minLength=1,required" yaml:"pattern"`
 	// Required status checks configuration
 	RequiredStatusChecks *RequiredStatusChecks `json:"required_status_checks" yaml:"required_status_checks"`
@@ -273,6 +420,13 @@ type RequiredStatusChecks struct {
 	// Required status check contexts. Empty array inherits repo's existing checks (hybrid
 	// approach)
 	Contexts []string `json:"contexts" yaml:"contexts"`
+	// Require the checks of workflow jobs that run on pull requests to the default branch,
+	// in addition to the listed contexts
+	Discover *bool `json:"discover" yaml:"discover"`
+	// Glob patterns a discovered check must match to be required (default: all checks)
+	Include []string `json:"include" jsonschema:"minLength=1,uniqueItems=true" yaml:"include"`
+	// Glob patterns of discovered checks that are not required
+	Exclude []string `json:"exclude" jsonschema:"minLength=1,uniqueItems=true" yaml:"exclude"`
 }
 
 // Configures pull request review requirements including approval count, code owner reviews,
@@ -292,13 +446,14 @@ type RequiredReviews struct {
 	BypassPullRequestAllowances *BypassPullRequestAllowances `json:"bypass_pull_request_allowances" yaml:"bypass_pull_request_allowances"`
 }
 
-// BypassPullRequestAllowances defines who can bypass pull request requirements.
+// BypassPullRequestAllowances defines who can bypass pull request requirements. Teams and apps
+// are given by slug and checked against the organization at sync time.
 type BypassPullRequestAllowances struct {
 	// GitHub usernames that can bypass pull request requirements
 	Users []string `json:"users" yaml:"users"`
-	// GitHub team slugs that can bypass pull request requirements
+	// GitHub team slugs that can bypass pull request requirements (e.g., "maintainers")
 	Teams []string `json:"teams" yaml:"teams"`
-	// GitHub App slugs that can bypass pull request requirements
+	// GitHub App slugs that can bypass pull request requirements (e.g., "renovate")
 	Apps []string `json:"apps" yaml:"apps"`
 }
 
@@ -349,14 +504,91 @@ type RefNameCondition struct {
 	Exclude []string `json:"exclude" jsonschema:"minLength=1,pattern=^refs/,uniqueItems=true" yaml:"exclude"`
 }
 
-// Defines an actor (user, team, app, or role) who can bypass ruleset requirements
+// Configures an organization-level ruleset applied to repositories selected by name patterns or
+// custom property values
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type OrgRulesetConfig struct {
+	// Ruleset name (unique per organization)
+	Name string `json:"name" jsonschema:"minLength=1,required" yaml:"name"`
+	// Target type (branch, tag, or push)
+	Target string `json:"target" jsonschema:"enum=branch,enum=tag,enum=push,required" yaml:"target"`
+	// Enforcement level (active, disabled, or evaluate)
+	Enforcement string `json:"enforcement" jsonschema:"enum=active,enum=disabled,enum=evaluate,required" yaml:"enforcement"`
+	// Conditions selecting the repositories and refs the ruleset applies to
+	Conditions *OrgRulesetConditionsConfig `json:"conditions" jsonschema:"required" yaml:"conditions"`
+	// Actors who can bypass this ruleset
+	BypassActors []BypassActorConfig `json:"bypass_actors" yaml:"bypass_actors"`
+	// Rules to enforce
+	Rules *RulesetRulesConfig `json:"rules" yaml:"rules"`
+}
+
+// Defines which repositories and refs an organization ruleset applies to. Repositories are
+// selected either by name or by custom property values
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type OrgRulesetConditionsConfig struct {
+	// Ref name patterns (branch/tag names)
+	RefName *RefNameCondition `json:"ref_name" yaml:"ref_name"`
+	// Repository name patterns
+	RepositoryName *RepositoryNameCondition `json:"repository_name" yaml:"repository_name"`
+	// Repository custom property values
+	RepositoryProperty *RepositoryPropertyCondition `json:"repository_property" yaml:"repository_property"`
+}
+
+// Specifies include/exclude patterns for repository names
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type RepositoryNameCondition struct {
+	// Repository name patterns to include (e.g., "*", "service-*")
+	Include []string `json:"include" jsonschema:"minLength=1,uniqueItems=true" yaml:"include"`
+	// Repository name patterns to exclude
+	Exclude []string `json:"exclude" jsonschema:"minLength=1,uniqueItems=true" yaml:"exclude"`
+	// Prevent renaming repositories so they stop matching the include patterns
+	Protected *bool `json:"protected" yaml:"protected"`
+}
+
+// Specifies custom property values repositories must or must not have
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type RepositoryPropertyCondition struct {
+	// Properties repositories must match
+	Include []RepositoryPropertyTarget `json:"include" yaml:"include"`
+	// Properties repositories must not match
+	Exclude []RepositoryPropertyTarget `json:"exclude" yaml:"exclude"`
+}
+
+// Matches a custom property against a list of values
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type RepositoryPropertyTarget struct {
+	// Custom property name
+	Name string `json:"name" jsonschema:"minLength=1,required" yaml:"name"`
+	// Values matching the property
+	PropertyValues []string `json:"property_values" jsonschema:"minItems=1,required" yaml:"property_values"`
+	// Property source (custom or system)
+	Source string `json:"source" jsonschema:"enum=custom,enum=system" yaml:"source"`
+}
+
+// Defines an actor (user, team, app, or role) who can bypass ruleset requirements. Give either
+// a numeric actor_id with actor_type, or exactly one of team, app, role or org_admin, which is
+// resolved to its ID at sync time
 //
 //nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
 type BypassActorConfig struct {
 	// Actor ID (user ID, team ID, app ID, or role ID)
-	ActorID int64 `json:"actor_id" jsonschema:"required" yaml:"actor_id"`
+	ActorID int64 `json:"actor_id" jsonschema:"oneof_required=actor_id" yaml:"actor_id"`
 	// Actor type (Integration, OrganizationAdmin, RepositoryRole, or Team)
-	ActorType string `json:"actor_type" jsonschema:"enum=Integration,enum=OrganizationAdmin,enum=RepositoryRole,enum=Team,required" yaml:"actor_type"`
+	ActorType string `json:"actor_type" jsonschema:"enum=Integration,enum=OrganizationAdmin,enum=RepositoryRole,enum=Team,oneof_required=actor_id" yaml:"actor_type"`
+	// Team slug in the organization (e.g., "maintainers")
+	Team string `json:"team" jsonschema:"minLength=1,oneof_required=team" yaml:"team"`
+	// GitHub App slug (e.g., "renovate")
+	App string `json:"app" jsonschema:"minLength=1,oneof_required=app" yaml:"app"`
+	// Repository role name: maintain, write, admin, or a custom repository role of the
+	// organization
+	Role string `json:"role" jsonschema:"minLength=1,oneof_required=role" yaml:"role"`
+	// Organization administrators
+	OrgAdmin *bool `json:"org_admin" jsonschema:"oneof_required=org_admin" yaml:"org_admin"`
 	// Bypass mode (always or pull_request)
 	BypassMode string `json:"bypass_mode" jsonschema:"enum=always,enum=pull_request,required" yaml:"bypass_mode"`
 }
@@ -383,6 +615,116 @@ type RulesetRulesConfig struct {
 	Creation *bool `json:"creation" yaml:"creation"`
 	// Restrict updates to refs
 	Update *bool `json:"update" yaml:"update"`
+	// Commit messages must match a pattern
+	CommitMessagePattern *PatternRuleConfig `json:"commit_message_pattern" yaml:"commit_message_pattern"`
+	// Commit author email addresses must match a pattern
+	CommitAuthorEmailPattern *PatternRuleConfig `json:"commit_author_email_pattern" yaml:"commit_author_email_pattern"`
+	// Branch names must match a pattern
+	BranchNamePattern *PatternRuleConfig `json:"branch_name_pattern" yaml:"branch_name_pattern"`
+	// Tag names must match a pattern
+	TagNamePattern *PatternRuleConfig `json:"tag_name_pattern" yaml:"tag_name_pattern"`
+	// Prevent pushes that change the listed file paths (push rulesets only)
+	FilePathRestriction *FilePathRestrictionRuleConfig `json:"file_path_restriction" yaml:"file_path_restriction"`
+	// Prevent pushes containing files larger than a limit (push rulesets only)
+	MaxFileSize *MaxFileSizeRuleConfig `json:"max_file_size" yaml:"max_file_size"`
+	// Prevent pushes containing files with the listed extensions (push rulesets only)
+	FileExtensionRestriction *FileExtensionRestrictionRuleConfig `json:"file_extension_restriction" yaml:"file_extension_restriction"`
+	// Environments that must be successfully deployed to before merging
+	RequiredDeployments *RequiredDeploymentsRuleConfig `json:"required_deployments" yaml:"required_deployments"`
+	// Require merges to go through a merge queue
+	MergeQueue *MergeQueueRuleConfig `json:"merge_queue" yaml:"merge_queue"`
+	// Workflows that must pass before merging (organization rulesets only)
+	Workflows *WorkflowsRuleConfig `json:"workflows" yaml:"workflows"`
+}
+
+// Restricts commit messages, author emails, branch names, or tag names to a pattern
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type PatternRuleConfig struct {
+	// How to match the pattern (starts_with, ends_with, contains, regex)
+	Operator string `json:"operator" jsonschema:"enum=starts_with,enum=ends_with,enum=contains,enum=regex,required" yaml:"operator"`
+	// Pattern to match
+	Pattern string `json:"pattern" jsonschema:"minLength=1,required" yaml:"pattern"`
+	// Fail when the pattern matches instead of when it does not
+	Negate *bool `json:"negate" yaml:"negate"`
+	// Name shown for the rule in GitHub
+	Name string `json:"name" yaml:"name"`
+}
+
+// Prevents pushes that change files at the listed paths
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type FilePathRestrictionRuleConfig struct {
+	// File paths that cannot be changed (e.g., ".github/workflows/**")
+	RestrictedFilePaths []string `json:"restricted_file_paths" jsonschema:"minItems=1,required" yaml:"restricted_file_paths"`
+}
+
+// Limits the size of files that can be pushed
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type MaxFileSizeRuleConfig struct {
+	// Maximum file size in megabytes (1-100)
+	MaxFileSize int64 `json:"max_file_size" jsonschema:"maximum=100,minimum=1,required" yaml:"max_file_size"`
+}
+
+// Prevents pushes that include files with the listed extensions
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type FileExtensionRestrictionRuleConfig struct {
+	// File extensions that cannot be pushed (e.g., ".exe")
+	RestrictedFileExtensions []string `json:"restricted_file_extensions" jsonschema:"minItems=1,required" yaml:"restricted_file_extensions"`
+}
+
+// Requires successful deployments to environments before merging
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type RequiredDeploymentsRuleConfig struct {
+	// Environment names that must be deployed to successfully
+	RequiredDeploymentEnvironments []string `json:"required_deployment_environments" jsonschema:"minItems=1,required" yaml:"required_deployment_environments"`
+}
+
+// Configures the merge queue required for merging
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type MergeQueueRuleConfig struct {
+	// Minutes to wait for required checks to report before failing (1-360)
+	CheckResponseTimeoutMinutes *int `json:"check_response_timeout_minutes" jsonschema:"default=60,maximum=360,minimum=1" yaml:"check_response_timeout_minutes"`
+	// Whether all entries (ALLGREEN) or only the head entry (HEADGREEN) must pass checks
+	GroupingStrategy string `json:"grouping_strategy" jsonschema:"default=ALLGREEN,enum=ALLGREEN,enum=HEADGREEN" yaml:"grouping_strategy"`
+	// Maximum number of entries to build at once (0-100)
+	MaxEntriesToBuild *int `json:"max_entries_to_build" jsonschema:"default=5,maximum=100,minimum=0" yaml:"max_entries_to_build"`
+	// Maximum number of entries to merge in one group (0-100)
+	MaxEntriesToMerge *int `json:"max_entries_to_merge" jsonschema:"default=5,maximum=100,minimum=0" yaml:"max_entries_to_merge"`
+	// Merge method used by the queue (MERGE, SQUASH, REBASE)
+	MergeMethod string `json:"merge_method" jsonschema:"default=MERGE,enum=MERGE,enum=SQUASH,enum=REBASE" yaml:"merge_method"`
+	// Minimum number of entries to merge in one group (0-100)
+	MinEntriesToMerge *int `json:"min_entries_to_merge" jsonschema:"default=1,maximum=100,minimum=0" yaml:"min_entries_to_merge"`
+	// Minutes to wait for the minimum group size before merging (0-360)
+	MinEntriesToMergeWaitMinutes *int `json:"min_entries_to_merge_wait_minutes" jsonschema:"default=5,maximum=360,minimum=0" yaml:"min_entries_to_merge_wait_minutes"`
+}
+
+// Requires workflows to pass before merging
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type WorkflowsRuleConfig struct {
+	// When true, do not require the workflows on branches when they are first created
+	DoNotEnforceOnCreate *bool `json:"do_not_enforce_on_create" yaml:"do_not_enforce_on_create"`
+	// Workflows that must pass
+	Workflows []WorkflowRuleConfig `json:"workflows" jsonschema:"minItems=1,required" yaml:"workflows"`
+}
+
+// Identifies a workflow file that must pass
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type WorkflowRuleConfig struct {
+	// Path of the workflow file (e.g., ".github/workflows/ci.yml")
+	Path string `json:"path" jsonschema:"minLength=1,required" yaml:"path"`
+	// ID of the repository containing the workflow
+	RepositoryID *int64 `json:"repository_id" jsonschema:"required" yaml:"repository_id"`
+	// Branch or tag of the workflow file
+	Ref string `json:"ref" yaml:"ref"`
+	// Commit SHA of the workflow file
+	SHA string `json:"sha" yaml:"sha"`
 }
 
 // Configures pull request requirements including reviews, code owners, and merge strategies
@@ -414,6 +756,13 @@ type StatusChecksRuleConfig struct {
 	// When true, do not enforce status check requirements on branches created from protected
 	// branches. New branches can temporarily bypass checks when first created
 	DoNotEnforceOnCreate *bool `json:"do_not_enforce_on_create" yaml:"do_not_enforce_on_create"`
+	// Require the checks of workflow jobs that run on pull requests to the default branch,
+	// in addition to the listed checks. Not supported in organization rulesets
+	Discover *bool `json:"discover" yaml:"discover"`
+	// Glob patterns a discovered check must match to be required (default: all checks)
+	Include []string `json:"include" jsonschema:"minLength=1,uniqueItems=true" yaml:"include"`
+	// Glob patterns of discovered checks that are not required
+	Exclude []string `json:"exclude" jsonschema:"minLength=1,uniqueItems=true" yaml:"exclude"`
 }
 
 // Defines a single required status check with context name and optional integration ID
@@ -446,6 +795,113 @@ type CodeScanningToolConfig struct {
 	SecurityAlertsThreshold string `json:"security_alerts_threshold" jsonschema:"enum=none,enum=critical,enum=high_or_higher,enum=medium_or_higher,enum=all,required" yaml:"security_alerts_threshold"`
 }
 
+// Configures a deployment environment with its protection rules and the branches and tags
+// allowed to deploy to it
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type EnvironmentConfig struct {
+	// Environment name (e.g., "production")
+	Name string `json:"name" jsonschema:"minLength=1,required" yaml:"name"`
+	// Users and teams who must approve deployments to this environment
+	Reviewers *EnvironmentReviewersConfig `json:"reviewers" yaml:"reviewers"`
+	// Minutes to wait before deployments to this environment proceed (0-43200)
+	WaitTimer *int `json:"wait_timer" jsonschema:"maximum=43200,minimum=0" yaml:"wait_timer"`
+	// Prevent the user who triggered a deployment from approving it
+	PreventSelfReview *bool `json:"prevent_self_review" yaml:"prevent_self_review"`
+	// Allow repository administrators to bypass the protection rules. Default: true
+	CanAdminsBypass *bool `json:"can_admins_bypass" yaml:"can_admins_bypass"`
+	// Branches and tags allowed to deploy. Leave unset to keep the current policy; set it
+	// without protected_branches, branches or tags to allow all branches
+	DeploymentBranchPolicy *DeploymentBranchPolicyConfig `json:"deployment_branch_policy" yaml:"deployment_branch_policy"`
+}
+
+// Lists the users and teams required to review deployments. At most six reviewers are allowed
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type EnvironmentReviewersConfig struct {
+	// User logins
+	Users []string `json:"users" jsonschema:"uniqueItems=true" yaml:"users"`
+	// Team slugs in the organization
+	Teams []string `json:"teams" jsonschema:"uniqueItems=true" yaml:"teams"`
+}
+
+// Restricts deployments to protected branches or to branches and tags matching name patterns
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type DeploymentBranchPolicyConfig struct {
+	// Allow only branches with branch protection rules to deploy. Cannot be combined with
+	// branches or tags
+	ProtectedBranches bool `json:"protected_branches" jsonschema:"default=false" yaml:"protected_branches"`
+	// Branch name patterns allowed to deploy (e.g., "main", "release/*")
+	Branches []string `json:"branches" jsonschema:"uniqueItems=true" yaml:"branches"`
+	// Tag name patterns allowed to deploy (e.g., "v*")
+	Tags []string `json:"tags" jsonschema:"uniqueItems=true" yaml:"tags"`
+}
+
+// Defines a GitHub Actions repository variable
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type VariableConfig struct {
+	// Variable name (letters, numbers and underscores, not starting with a number)
+	Name string `json:"name" jsonschema:"pattern=^[A-Za-z_][A-Za-z0-9_]*$,required" yaml:"name"`
+	// Variable value. Supports {{REPO_NAME}}, {{OWNER}}, {{DEFAULT_BRANCH}} and {{LANGUAGE}}
+	// placeholders
+	Value string `json:"value" jsonschema:"required" yaml:"value"`
+}
+
+// Defines a GitHub Actions repository secret whose value is read from the environment or a
+// file of the machine running the sync. Give exactly one of from_env or from_file
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type SecretConfig struct {
+	// Secret name (letters, numbers and underscores, not starting with a number)
+	Name string `json:"name" jsonschema:"pattern=^[A-Za-z_][A-Za-z0-9_]*$,required" yaml:"name"`
+	// Environment variable holding the secret value
+	FromEnv string `json:"from_env" jsonschema:"minLength=1" yaml:"from_env"`
+	// Path of a file holding the secret value
+	FromFile string `json:"from_file" jsonschema:"minLength=1" yaml:"from_file"`
+	// Write the secret even when it already exists. Secrets cannot be read back, so existing
+	// secrets are otherwise left untouched
+	Force bool `json:"force" jsonschema:"default=false" yaml:"force"`
+}
+
+// Defines a repository webhook, matched to existing webhooks by URL
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type WebhookConfig struct {
+	// URL the payloads are delivered to
+	URL string `json:"url" jsonschema:"format=uri,required" yaml:"url"`
+	// Media type of the payloads (json or form). Default: json
+	ContentType string `json:"content_type" jsonschema:"enum=json,enum=form,default=json" yaml:"content_type"`
+	// Events that trigger the webhook (e.g., "push", "pull_request", or "*" for all). Default:
+	// push
+	Events []string `json:"events" jsonschema:"uniqueItems=true" yaml:"events"`
+	// Deliver payloads when the webhook is triggered. Default: true
+	Active *bool `json:"active" yaml:"active"`
+	// Skip TLS certificate verification of the URL. Default: false
+	InsecureSSL bool `json:"insecure_ssl" jsonschema:"default=false" yaml:"insecure_ssl"`
+	// Environment variable holding the secret used to sign payloads. GitHub never returns
+	// webhook secrets, so changes are detected through a fingerprint stored in the
+	// ORG_SYNC_WEBHOOK_FINGERPRINTS repository variable, keyed by the
+	// ORG_SYNC_WEBHOOK_FINGERPRINT_KEY environment variable
+	SecretFromEnv string `json:"secret_from_env" jsonschema:"minLength=1" yaml:"secret_from_env"`
+}
+
+// Configures which teams and collaborators can access the repository. Permissions are pull,
+// triage, push, maintain, admin, or the name of a custom repository role
+//
+//nolint:staticcheck // ST1021: Descriptive comment preferred over struct name prefix
+type AccessConfig struct {
+	// Team permissions keyed by team slug
+	Teams map[string]string `json:"teams" yaml:"teams"`
+	// Outside collaborator permissions keyed by user login. Collaborators who are not members
+	// of the repository yet are invited
+	Collaborators map[string]string `json:"collaborators" yaml:"collaborators"`
+	// When true, teams and direct collaborators NOT listed here lose their access to the
+	// repository and pending invitations are cancelled
+	AllowRemoval bool `json:"allow_removal" jsonschema:"default=false" yaml:"allow_removal"`
+}
+
 // Organization-wide smyklot configuration file controlling version sync and workflow
 // installation across all repositories
 //
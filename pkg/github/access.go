@@ -0,0 +1,393 @@
+package github
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// Kinds of access grants recorded in AccessChange.Kind.
+const (
+	accessKindTeam         = "team"
+	accessKindCollaborator = "collaborator"
+)
+
+const (
+	// collaboratorsAffiliation lists collaborators granted access directly rather than
+	// through a team or organization membership.
+	collaboratorsAffiliation = "direct"
+	// accessPerPage is the page size used when listing teams, collaborators and
+	// invitations.
+	accessPerPage = 100
+)
+
+// permissionRanks orders the built-in repository roles. Custom roles are not ranked.
+var permissionRanks = map[string]int{
+	"pull":     1,
+	"triage":   2,
+	"push":     3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+// accessChange is a planned access operation. InvitationID is set when a pending
+// invitation is revoked instead of a collaborator.
+type accessChange struct {
+	AccessChange
+
+	InvitationID int64
+}
+
+// accessGrant is the current access of a team or collaborator.
+type accessGrant struct {
+	Permission   string
+	InvitationID int64
+}
+
+// SyncAccess synchronizes team and collaborator permissions from configuration to
+// target repository. Unlisted teams and direct collaborators lose access only when
+// the access config allows removal.
+func SyncAccess(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	access *configtypes.AccessConfig,
+	exclude []string,
+	dryRun bool,
+) ([]AccessChange, error) {
+	if isSettingExcluded("access", exclude) {
+		log.Debug("access sync excluded by config")
+
+		return nil, nil
+	}
+
+	if len(access.Teams) == 0 && len(access.Collaborators) == 0 && !access.AllowRemoval {
+		log.Debug("no access configured")
+
+		return nil, nil
+	}
+
+	changes, err := planAccess(ctx, client, org, repo, access, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	logAccessChanges(log, changes, dryRun)
+
+	if dryRun {
+		log.Info("dry-run mode: skipping access changes", "count", len(changes))
+
+		return accessChanges(changes), nil
+	}
+
+	if err := applyAccessChanges(ctx, client, org, repo, changes); err != nil {
+		return accessChanges(changes), err
+	}
+
+	log.Info("access synced successfully", "count", len(changes))
+
+	return accessChanges(changes), nil
+}
+
+// planAccess compares configured permissions with the current grants and returns
+// the grants to add, change or revoke.
+func planAccess(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	access *configtypes.AccessConfig,
+	exclude []string,
+) ([]accessChange, error) {
+	teams, err := listTeamGrants(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching team access")
+	}
+
+	collaborators, err := listCollaboratorGrants(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching collaborator access")
+	}
+
+	changes := planAccessGrants(
+		accessKindTeam, teams, access.Teams, access.AllowRemoval, exclude,
+	)
+	changes = append(changes, planAccessGrants(
+		accessKindCollaborator, collaborators, access.Collaborators, access.AllowRemoval, exclude,
+	)...)
+
+	return changes, nil
+}
+
+// planAccessGrants compares the desired permissions of one kind of grant with the
+// current ones. Names are compared case-insensitively as GitHub does.
+func planAccessGrants(
+	kind string,
+	current map[string]accessGrant,
+	desired map[string]string,
+	allowRemoval bool,
+	exclude []string,
+) []accessChange {
+	var changes []accessChange
+
+	configured := make(map[string]bool, len(desired))
+
+	for _, name := range slices.Sorted(maps.Keys(desired)) {
+		configured[strings.ToLower(name)] = true
+
+		if isSettingExcluded("access."+kind+"s."+name, exclude) {
+			continue
+		}
+
+		permission := normalizePermission(desired[name])
+		grant, ok := current[strings.ToLower(name)]
+
+		if ok && grant.Permission == permission {
+			continue
+		}
+
+		change := accessChange{
+			AccessChange: AccessChange{
+				Kind:    kind,
+				Name:    name,
+				Current: grant.Permission,
+				Desired: permission,
+				Change:  classifyAccessChange(grant.Permission, permission),
+			},
+		}
+
+		changes = append(changes, change)
+	}
+
+	if !allowRemoval {
+		return changes
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		if configured[name] || isSettingExcluded("access."+kind+"s."+name, exclude) {
+			continue
+		}
+
+		changes = append(changes, accessChange{
+			AccessChange: AccessChange{
+				Kind:    kind,
+				Name:    name,
+				Current: current[name].Permission,
+				Change:  AccessRevoked,
+			},
+			InvitationID: current[name].InvitationID,
+		})
+	}
+
+	return changes
+}
+
+// normalizePermission maps role names returned for collaborators and invitations to
+// the permission names used when granting access.
+func normalizePermission(permission string) string {
+	switch permission = strings.ToLower(permission); permission {
+	case "read":
+		return "pull"
+	case "write":
+		return "push"
+	default:
+		return permission
+	}
+}
+
+// classifyAccessChange reports whether a permission change grants, escalates or
+// reduces access. New grants above read access count as escalations. Changes
+// involving custom roles cannot be ranked.
+func classifyAccessChange(current string, desired string) AccessChangeType {
+	desiredRank, desiredOK := permissionRanks[desired]
+
+	if current == "" {
+		if desiredOK && desiredRank > permissionRanks["pull"] {
+			return AccessEscalated
+		}
+
+		return AccessGranted
+	}
+
+	currentRank, currentOK := permissionRanks[current]
+
+	switch {
+	case !currentOK || !desiredOK:
+		return AccessChanged
+	case desiredRank > currentRank:
+		return AccessEscalated
+	default:
+		return AccessReduced
+	}
+}
+
+// listTeamGrants returns the permission of each team with access to a repository,
+// keyed by lowercase team slug.
+func listTeamGrants(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (map[string]accessGrant, error) {
+	grants := make(map[string]accessGrant)
+	opts := &github.ListOptions{PerPage: accessPerPage}
+
+	for {
+		teams, resp, err := client.Repositories.ListTeams(ctx, org, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing teams")
+		}
+
+		for _, team := range teams {
+			grants[strings.ToLower(team.GetSlug())] = accessGrant{
+				Permission: normalizePermission(team.GetPermission()),
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return grants, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// listCollaboratorGrants returns the permission of each direct collaborator and
+// pending invitation of a repository, keyed by lowercase login.
+func listCollaboratorGrants(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (map[string]accessGrant, error) {
+	grants := make(map[string]accessGrant)
+	opts := &github.ListCollaboratorsOptions{
+		Affiliation: collaboratorsAffiliation,
+		ListOptions: github.ListOptions{PerPage: accessPerPage},
+	}
+
+	for {
+		users, resp, err := client.Repositories.ListCollaborators(ctx, org, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing collaborators")
+		}
+
+		for _, user := range users {
+			grants[strings.ToLower(user.GetLogin())] = accessGrant{
+				Permission: normalizePermission(user.GetRoleName()),
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	invitationOpts := &github.ListOptions{PerPage: accessPerPage}
+
+	for {
+		invitations, resp, err := client.Repositories.ListInvitations(
+			ctx, org, repo, invitationOpts,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing invitations")
+		}
+
+		for _, invitation := range invitations {
+			grants[strings.ToLower(invitation.GetInvitee().GetLogin())] = accessGrant{
+				Permission:   normalizePermission(invitation.GetPermissions()),
+				InvitationID: invitation.GetID(),
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return grants, nil
+		}
+
+		invitationOpts.Page = resp.NextPage
+	}
+}
+
+// applyAccessChanges grants, changes and revokes access as planned. Collaborators
+// who are not members of the repository yet receive an invitation.
+func applyAccessChanges(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	changes []accessChange,
+) error {
+	for _, change := range changes {
+		var err error
+
+		switch {
+		case change.Kind == accessKindTeam && change.Change == AccessRevoked:
+			_, err = client.Teams.RemoveTeamRepoBySlug(ctx, org, change.Name, org, repo)
+		case change.Kind == accessKindTeam:
+			_, err = client.Teams.AddTeamRepoBySlug(ctx, org, change.Name, org, repo,
+				&github.TeamAddTeamRepoOptions{Permission: change.Desired})
+		case change.Change == AccessRevoked && change.InvitationID != 0:
+			_, err = client.Repositories.DeleteInvitation(ctx, org, repo, change.InvitationID)
+		case change.Change == AccessRevoked:
+			_, err = client.Repositories.RemoveCollaborator(ctx, org, repo, change.Name)
+		default:
+			_, _, err = client.Repositories.AddCollaborator(ctx, org, repo, change.Name,
+				&github.RepositoryAddCollaboratorOptions{Permission: change.Desired})
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "updating access of %s %q", change.Kind, change.Name)
+		}
+	}
+
+	return nil
+}
+
+// logAccessChanges logs planned access changes. Escalations and revocations are
+// logged as warnings so they stand out in dry-run output.
+func logAccessChanges(log *logger.Logger, changes []accessChange, dryRun bool) {
+	msg := "access changed"
+	if dryRun {
+		msg = "would change access"
+	}
+
+	for _, change := range changes {
+		args := []any{
+			"change", change.Change,
+			"kind", change.Kind,
+			"name", change.Name,
+			"current", change.Current,
+			"desired", change.Desired,
+		}
+
+		if change.Change == AccessEscalated || change.Change == AccessRevoked {
+			log.Warn(msg, args...)
+
+			continue
+		}
+
+		log.Info(msg, args...)
+	}
+}
+
+// accessChanges returns the exported changes of planned access operations.
+func accessChanges(changes []accessChange) []AccessChange {
+	exported := make([]AccessChange, 0, len(changes))
+
+	for _, change := range changes {
+		exported = append(exported, change.AccessChange)
+	}
+
+	return exported
+}
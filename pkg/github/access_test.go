@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func accessResponses() map[string]string {
+	return map[string]string{
+		"GET /repos/org/repo/teams": `[
+			{"slug":"core","permission":"push"},
+			{"slug":"docs","permission":"admin"},
+			{"slug":"legacy","permission":"pull"}]`,
+		"GET /repos/org/repo/collaborators": `[
+			{"login":"alice","role_name":"write"},
+			{"login":"mallory","role_name":"admin"}]`,
+		"GET /repos/org/repo/invitations": `[
+			{"id":7,"invitee":{"login":"eve"},"permissions":"read"}]`,
+		"PUT /orgs/org/teams/core/repos/org/repo":      ``,
+		"PUT /orgs/org/teams/docs/repos/org/repo":      ``,
+		"PUT /orgs/org/teams/ops/repos/org/repo":       ``,
+		"DELETE /orgs/org/teams/legacy/repos/org/repo": ``,
+		"PUT /repos/org/repo/collaborators/bob":        `{"id":1}`,
+		"DELETE /repos/org/repo/collaborators/mallory": ``,
+		"DELETE /repos/org/repo/invitations/7":         ``,
+	}
+}
+
+var testAccess = configtypes.AccessConfig{
+	Teams: map[string]string{
+		"core": "maintain",
+		"docs": "triage",
+		"ops":  "push",
+	},
+	Collaborators: map[string]string{
+		"alice": "push",
+		"bob":   "pull",
+	},
+}
+
+func TestSyncAccess(t *testing.T) {
+	t.Parallel()
+
+	access := testAccess
+	access.AllowRemoval = true
+
+	fake, client := newFakeGitHub(t, accessResponses())
+
+	changes, err := SyncAccess(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		&access, nil, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncAccess() error = %v", err)
+	}
+
+	want := []AccessChange{
+		{Kind: "team", Name: "core", Current: "push", Desired: "maintain", Change: AccessEscalated},
+		{Kind: "team", Name: "docs", Current: "admin", Desired: "triage", Change: AccessReduced},
+		{Kind: "team", Name: "ops", Desired: "push", Change: AccessEscalated},
+		{Kind: "team", Name: "legacy", Current: "pull", Change: AccessRevoked},
+		{Kind: "collaborator", Name: "bob", Desired: "pull", Change: AccessGranted},
+		{Kind: "collaborator", Name: "eve", Current: "pull", Change: AccessRevoked},
+		{Kind: "collaborator", Name: "mallory", Current: "admin", Change: AccessRevoked},
+	}
+
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+
+	body := fake.body("PUT /orgs/org/teams/core/repos/org/repo")
+	if !strings.Contains(body, `"permission":"maintain"`) {
+		t.Errorf("team permission not sent: %s", body)
+	}
+
+	for _, key := range []string{
+		"DELETE /orgs/org/teams/legacy/repos/org/repo",
+		"DELETE /repos/org/repo/collaborators/mallory",
+		"DELETE /repos/org/repo/invitations/7",
+		"PUT /repos/org/repo/collaborators/bob",
+	} {
+		if !fake.called(key) {
+			t.Errorf("expected request %s", key)
+		}
+	}
+
+	if fake.called("PUT /repos/org/repo/collaborators/alice") {
+		t.Error("unchanged collaborator should not be updated")
+	}
+}
+
+func TestSyncAccessDryRun(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, accessResponses())
+
+	changes, err := SyncAccess(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		&testAccess, []string{"access.teams.docs"}, true,
+	)
+	if err != nil {
+		t.Fatalf("SyncAccess() error = %v", err)
+	}
+
+	want := []AccessChange{
+		{Kind: "team", Name: "core", Current: "push", Desired: "maintain", Change: AccessEscalated},
+		{Kind: "team", Name: "ops", Desired: "push", Change: AccessEscalated},
+		{Kind: "collaborator", Name: "bob", Desired: "pull", Change: AccessGranted},
+	}
+
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("changes mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, key := range fake.requests {
+		if !strings.HasPrefix(key, "GET ") {
+			t.Errorf("unexpected request in dry-run: %s", key)
+		}
+	}
+}
+
+func TestClassifyAccessChange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		want    AccessChangeType
+	}{
+		{name: "new grant", desired: "pull", want: AccessGranted},
+		{name: "new grant above read", desired: "maintain", want: AccessEscalated},
+		{name: "new custom role grant", desired: "security-auditor", want: AccessGranted},
+		{name: "escalation", current: "pull", desired: "admin", want: AccessEscalated},
+		{name: "reduction", current: "maintain", desired: "triage", want: AccessReduced},
+		{name: "custom role", current: "push", desired: "security-auditor", want: AccessChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := classifyAccessChange(tt.current, tt.desired); got != tt.want {
+				t.Errorf("classifyAccessChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,500 @@
+package github
+
+import (
+	"context"
+	"slices"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// allowedActionsSelected is the allowed_actions value under which the allowed
+// actions are picked individually.
+const allowedActionsSelected = "selected"
+
+// actionsChange is a planned update of GitHub Actions settings. Each request is
+// nil when its settings are already in sync. Unsupported lists the settings not
+// available for the repository, and Ignored the configured settings that have no
+// effect with the resulting Actions permissions.
+type actionsChange struct {
+	Fields       []FieldDiff
+	Unsupported  []string
+	Ignored      []string
+	Permissions  *github.ActionsPermissionsRepository
+	Allowed      *github.ActionsAllowed
+	Workflow     *github.DefaultWorkflowPermissionRepository
+	ForkApproval *github.ContributorApprovalPermissions
+	Retention    *github.ArtifactPeriodOpt
+}
+
+// planActionsSettings compares the Actions settings of a repository with the
+// desired state. Only settings the config sets are fetched and compared. Settings
+// unavailable for the repository are recorded as unsupported instead of failing.
+// Returns nil when nothing needs updating and nothing is unsupported.
+func planActionsSettings(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	current *github.Repository,
+	exclude []string,
+) (*actionsChange, error) {
+	if isSettingExcluded("actions", exclude) {
+		return nil, nil //nolint:nilnil // excluded section has no changes
+	}
+
+	change := &actionsChange{
+		Unsupported: unavailableActionsSettings(desired, current, exclude),
+	}
+
+	steps := []func(context.Context, *Client, string, string,
+		*configtypes.ActionsConfig, []string, *actionsChange) error{
+		planActionsPermissions,
+		planSelectedActions,
+		planWorkflowPermissions,
+		planForkPRApproval,
+		planArtifactRetention,
+	}
+
+	for _, step := range steps {
+		if err := step(ctx, client, org, repo, desired, exclude, change); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(change.Fields) == 0 && len(change.Unsupported) == 0 && len(change.Ignored) == 0 {
+		return nil, nil //nolint:nilnil // in sync
+	}
+
+	return change, nil
+}
+
+// unavailableActionsSettings returns the configured Actions settings the repository
+// does not offer. The fork pull request approval policy only applies to public
+// repositories.
+func unavailableActionsSettings(
+	desired *configtypes.ActionsConfig,
+	current *github.Repository,
+	exclude []string,
+) []string {
+	var unsupported []string
+
+	const forkPRApproval = "actions.fork_pr_approval_policy"
+
+	if desired.ForkPRApprovalPolicy != nil && current.GetPrivate() &&
+		!isSettingExcluded(forkPRApproval, exclude) {
+		unsupported = append(unsupported, forkPRApproval)
+	}
+
+	return unsupported
+}
+
+// planActionsPermissions compares whether Actions is enabled and which actions are
+// allowed.
+func planActionsPermissions(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	exclude []string,
+	change *actionsChange,
+) error {
+	enabled := desired.Enabled
+	if isSettingExcluded("actions.enabled", exclude) {
+		enabled = nil
+	}
+
+	allowed := desired.AllowedActions
+	if isSettingExcluded("actions.allowed_actions", exclude) {
+		allowed = nil
+	}
+
+	if enabled == nil && allowed == nil {
+		return nil
+	}
+
+	current, _, err := client.Repositories.GetActionsPermissions(ctx, org, repo)
+	if err != nil {
+		return errors.Wrap(err, "getting actions permissions")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	if enabled != nil {
+		change.Fields = appendDiff(change.Fields, "actions.enabled",
+			current.GetEnabled(), *enabled)
+	}
+
+	// Allowed actions only apply while Actions is enabled
+	if allowed != nil && (enabled == nil || *enabled) {
+		change.Fields = appendDiff(change.Fields, "actions.allowed_actions",
+			current.GetAllowedActions(), *allowed)
+	} else {
+		allowed = nil
+	}
+
+	if len(change.Fields) == fieldsBefore {
+		return nil
+	}
+
+	// The API requires enabled on every update
+	change.Permissions = &github.ActionsPermissionsRepository{
+		Enabled:        github.Ptr(current.GetEnabled()),
+		AllowedActions: allowed,
+	}
+
+	if enabled != nil {
+		change.Permissions.Enabled = enabled
+	}
+
+	return nil
+}
+
+// planSelectedActions compares the actions allowed when allowed_actions is
+// selected. GitHub rejects selected actions with any other allowed_actions, so they
+// are ignored unless the resulting permissions select the allowed actions.
+func planSelectedActions(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	exclude []string,
+	change *actionsChange,
+) error {
+	selected := desired.SelectedActions
+	if selected == nil || isSettingExcluded("actions.selected_actions", exclude) {
+		return nil
+	}
+
+	applies, err := selectedActionsApply(ctx, client, org, repo, desired, exclude)
+	if err != nil {
+		return err
+	}
+
+	if !applies {
+		change.Ignored = append(change.Ignored, "actions.selected_actions")
+
+		return nil
+	}
+
+	current, _, err := client.Repositories.GetActionsAllowed(ctx, org, repo)
+	if err != nil {
+		// Selected actions only exist while allowed_actions is selected
+		if isNotFoundError(err) || isConflictError(err) {
+			current = &github.ActionsAllowed{}
+		} else {
+			return errors.Wrap(err, "getting allowed actions")
+		}
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	update := &github.ActionsAllowed{
+		GithubOwnedAllowed: github.Ptr(current.GetGithubOwnedAllowed()),
+		VerifiedAllowed:    github.Ptr(current.GetVerifiedAllowed()),
+		PatternsAllowed:    current.PatternsAllowed,
+	}
+
+	if selected.GithubOwnedAllowed != nil {
+		change.Fields = appendDiff(change.Fields, "actions.selected_actions.github_owned_allowed",
+			current.GetGithubOwnedAllowed(), *selected.GithubOwnedAllowed)
+		update.GithubOwnedAllowed = selected.GithubOwnedAllowed
+	}
+
+	if selected.VerifiedAllowed != nil {
+		change.Fields = appendDiff(change.Fields, "actions.selected_actions.verified_allowed",
+			current.GetVerifiedAllowed(), *selected.VerifiedAllowed)
+		update.VerifiedAllowed = selected.VerifiedAllowed
+	}
+
+	if selected.PatternsAllowed != nil {
+		change.Fields = appendSetDiff(change.Fields, "actions.selected_actions.patterns_allowed",
+			current.PatternsAllowed, selected.PatternsAllowed)
+		update.PatternsAllowed = selected.PatternsAllowed
+	}
+
+	if len(change.Fields) > fieldsBefore {
+		change.Allowed = update
+	}
+
+	return nil
+}
+
+// selectedActionsApply reports whether Actions stays enabled with allowed_actions
+// set to selected once the desired permissions are applied. The current permissions
+// are only fetched for the settings the config leaves unset.
+func selectedActionsApply(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	exclude []string,
+) (bool, error) {
+	enabled := desired.Enabled
+	if isSettingExcluded("actions.enabled", exclude) {
+		enabled = nil
+	}
+
+	allowed := desired.AllowedActions
+	if isSettingExcluded("actions.allowed_actions", exclude) {
+		allowed = nil
+	}
+
+	if enabled != nil && !*enabled {
+		return false, nil
+	}
+
+	if allowed != nil {
+		return *allowed == allowedActionsSelected, nil
+	}
+
+	current, _, err := client.Repositories.GetActionsPermissions(ctx, org, repo)
+	if err != nil {
+		return false, errors.Wrap(err, "getting actions permissions")
+	}
+
+	if enabled == nil && !current.GetEnabled() {
+		return false, nil
+	}
+
+	return current.GetAllowedActions() == allowedActionsSelected, nil
+}
+
+// planWorkflowPermissions compares the default GITHUB_TOKEN permissions and
+// whether Actions can approve pull requests.
+func planWorkflowPermissions(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	exclude []string,
+	change *actionsChange,
+) error {
+	permissions := desired.DefaultWorkflowPermissions
+	if isSettingExcluded("actions.default_workflow_permissions", exclude) {
+		permissions = nil
+	}
+
+	canApprove := desired.CanApprovePullRequestReviews
+	if isSettingExcluded("actions.can_approve_pull_request_reviews", exclude) {
+		canApprove = nil
+	}
+
+	if permissions == nil && canApprove == nil {
+		return nil
+	}
+
+	current, _, err := client.Repositories.GetDefaultWorkflowPermissions(ctx, org, repo)
+	if err != nil {
+		return errors.Wrap(err, "getting default workflow permissions")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	update := &github.DefaultWorkflowPermissionRepository{
+		DefaultWorkflowPermissions:   current.DefaultWorkflowPermissions,
+		CanApprovePullRequestReviews: current.CanApprovePullRequestReviews,
+	}
+
+	if permissions != nil {
+		change.Fields = appendDiff(change.Fields, "actions.default_workflow_permissions",
+			current.GetDefaultWorkflowPermissions(), *permissions)
+		update.DefaultWorkflowPermissions = permissions
+	}
+
+	if canApprove != nil {
+		change.Fields = appendDiff(change.Fields, "actions.can_approve_pull_request_reviews",
+			current.GetCanApprovePullRequestReviews(), *canApprove)
+		update.CanApprovePullRequestReviews = canApprove
+	}
+
+	if len(change.Fields) > fieldsBefore {
+		change.Workflow = update
+	}
+
+	return nil
+}
+
+// planForkPRApproval compares which outside contributors need approval before fork
+// pull request workflows run.
+func planForkPRApproval(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	exclude []string,
+	change *actionsChange,
+) error {
+	const field = "actions.fork_pr_approval_policy"
+
+	policy := desired.ForkPRApprovalPolicy
+	if policy == nil || isSettingExcluded(field, exclude) ||
+		slices.Contains(change.Unsupported, field) {
+		return nil
+	}
+
+	current, _, err := client.Actions.GetForkPRContributorApprovalPermissions(ctx, org, repo)
+	if err != nil {
+		if isUnsupportedFeatureError(err) {
+			change.Unsupported = append(change.Unsupported, field)
+
+			return nil
+		}
+
+		return errors.Wrap(err, "getting fork pull request approval policy")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	change.Fields = appendDiff(change.Fields, field, current.ApprovalPolicy, *policy)
+
+	if len(change.Fields) > fieldsBefore {
+		change.ForkApproval = &github.ContributorApprovalPermissions{ApprovalPolicy: *policy}
+	}
+
+	return nil
+}
+
+// planArtifactRetention compares the artifact and log retention period.
+func planArtifactRetention(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.ActionsConfig,
+	exclude []string,
+	change *actionsChange,
+) error {
+	days := desired.ArtifactAndLogRetentionDays
+	if days == nil || isSettingExcluded("actions.artifact_and_log_retention_days", exclude) {
+		return nil
+	}
+
+	current, _, err := client.Repositories.GetArtifactAndLogRetentionPeriod(ctx, org, repo)
+	if err != nil {
+		return errors.Wrap(err, "getting artifact and log retention period")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	change.Fields = appendDiff(change.Fields, "actions.artifact_and_log_retention_days",
+		current.GetDays(), *days)
+
+	if len(change.Fields) > fieldsBefore {
+		change.Retention = &github.ArtifactPeriodOpt{Days: days}
+	}
+
+	return nil
+}
+
+// applyActionsChange applies planned Actions settings. Permissions are updated
+// first since selected actions can only be set once allowed_actions is selected.
+func applyActionsChange(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	change *actionsChange,
+) error {
+	if change.Permissions != nil {
+		_, _, err := client.Repositories.UpdateActionsPermissions(
+			ctx, org, repo, *change.Permissions,
+		)
+		if err != nil {
+			return errors.Wrap(err, "updating actions permissions")
+		}
+	}
+
+	if change.Allowed != nil {
+		_, _, err := client.Repositories.EditActionsAllowed(ctx, org, repo, *change.Allowed)
+		if err != nil {
+			return errors.Wrap(err, "updating allowed actions")
+		}
+	}
+
+	if change.Workflow != nil {
+		_, _, err := client.Repositories.UpdateDefaultWorkflowPermissions(
+			ctx, org, repo, *change.Workflow,
+		)
+		if err != nil {
+			return errors.Wrap(err, "updating default workflow permissions")
+		}
+	}
+
+	if change.ForkApproval != nil {
+		_, err := client.Actions.UpdateForkPRContributorApprovalPermissions(
+			ctx, org, repo, *change.ForkApproval,
+		)
+		if err != nil {
+			return errors.Wrap(err, "updating fork pull request approval policy")
+		}
+	}
+
+	if change.Retention != nil {
+		_, err := client.Repositories.UpdateArtifactAndLogRetentionPeriod(
+			ctx, org, repo, *change.Retention,
+		)
+		if err != nil {
+			return errors.Wrap(err, "updating artifact and log retention period")
+		}
+	}
+
+	return nil
+}
+
+// logActionsChange logs planned Actions settings changes and the ignored settings.
+func logActionsChange(log *logger.Logger, change *actionsChange) {
+	for _, setting := range ignoredActionsSettings(change) {
+		log.Warn("ignoring actions setting without effect on the resulting permissions",
+			"setting", setting,
+		)
+	}
+
+	if len(actionsDiffs(change)) == 0 {
+		return
+	}
+
+	log.Info("actions settings to update:")
+
+	for _, diff := range change.Fields {
+		log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+	}
+}
+
+// actionsDiffs returns the field diffs of a planned Actions change.
+func actionsDiffs(change *actionsChange) []FieldDiff {
+	if change == nil {
+		return nil
+	}
+
+	return slices.Clone(change.Fields)
+}
+
+// unsupportedActionsSettings returns the Actions settings the repository does not
+// support.
+func unsupportedActionsSettings(change *actionsChange) []string {
+	if change == nil {
+		return nil
+	}
+
+	return slices.Clone(change.Unsupported)
+}
+
+// ignoredActionsSettings returns the configured Actions settings that have no effect
+// with the resulting Actions permissions.
+func ignoredActionsSettings(change *actionsChange) []string {
+	if change == nil {
+		return nil
+	}
+
+	return slices.Clone(change.Ignored)
+}
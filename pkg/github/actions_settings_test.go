@@ -0,0 +1,287 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestPlanActionsSettings(t *testing.T) {
+	t.Parallel()
+
+	responses := map[string]string{
+		"GET /repos/org/repo/actions/permissions": `{"enabled":true,"allowed_actions":"all"}`,
+		"GET /repos/org/repo/actions/permissions/workflow": `{
+			"default_workflow_permissions":"write","can_approve_pull_request_reviews":false}`,
+		"GET /repos/org/repo/actions/permissions/artifact-and-log-retention": `{"days":90}`,
+		"GET /repos/org/repo/actions/permissions/fork-pr-contributor-approval": `{
+			"approval_policy":"first_time_contributors"}`,
+	}
+
+	desired := &configtypes.ActionsConfig{
+		Enabled:        github.Ptr(true),
+		AllowedActions: github.Ptr("selected"),
+		SelectedActions: &configtypes.SelectedActionsConfig{
+			GithubOwnedAllowed: github.Ptr(true),
+			PatternsAllowed:    []string{"smykla-labs/*"},
+		},
+		DefaultWorkflowPermissions:   github.Ptr("read"),
+		CanApprovePullRequestReviews: github.Ptr(false),
+		ArtifactAndLogRetentionDays:  github.Ptr(90),
+		ForkPRApprovalPolicy:         github.Ptr("all_external_contributors"),
+	}
+
+	public := &github.Repository{Private: github.Ptr(false)}
+
+	tests := []struct {
+		name            string
+		current         *github.Repository
+		exclude         []string
+		want            []string
+		wantUnsupported []string
+	}{
+		{
+			name:    "changed fields",
+			current: public,
+			want: []string{
+				"actions.allowed_actions",
+				"actions.selected_actions.github_owned_allowed",
+				"actions.selected_actions.patterns_allowed",
+				"actions.default_workflow_permissions",
+				"actions.fork_pr_approval_policy",
+			},
+		},
+		{
+			name:    "excluded fields",
+			current: public,
+			exclude: []string{
+				"actions.selected_actions",
+				"actions.default_workflow_permissions",
+				"actions.fork_pr_approval_policy",
+			},
+			want: []string{"actions.allowed_actions"},
+		},
+		{
+			name:    "excluded section",
+			current: public,
+			exclude: []string{"actions"},
+		},
+		{
+			name:    "fork approval unsupported on private repository",
+			current: &github.Repository{Private: github.Ptr(true)},
+			want: []string{
+				"actions.allowed_actions",
+				"actions.selected_actions.github_owned_allowed",
+				"actions.selected_actions.patterns_allowed",
+				"actions.default_workflow_permissions",
+			},
+			wantUnsupported: []string{"actions.fork_pr_approval_policy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, responses)
+
+			change, err := planActionsSettings(
+				context.Background(), client, "org", "repo", desired, tt.current, tt.exclude,
+			)
+			if err != nil {
+				t.Fatalf("planActionsSettings() error = %v", err)
+			}
+
+			var fields []string
+			for _, diff := range actionsDiffs(change) {
+				fields = append(fields, diff.Field)
+			}
+
+			if diff := cmp.Diff(tt.want, fields); diff != "" {
+				t.Errorf("planActionsSettings() fields mismatch (-want +got):\n%s", diff)
+			}
+
+			unsupported := unsupportedActionsSettings(change)
+			if diff := cmp.Diff(tt.wantUnsupported, unsupported); diff != "" {
+				t.Errorf("planActionsSettings() unsupported mismatch (-want +got):\n%s", diff)
+			}
+
+			forkApproval := "GET /repos/org/repo/actions/permissions/fork-pr-contributor-approval"
+			if tt.wantUnsupported != nil && fake.called(forkApproval) {
+				t.Error("fetched the fork approval policy of a private repository")
+			}
+		})
+	}
+}
+
+func TestPlanSelectedActionsIgnored(t *testing.T) {
+	t.Parallel()
+
+	selected := &configtypes.SelectedActionsConfig{GithubOwnedAllowed: github.Ptr(true)}
+
+	tests := []struct {
+		name        string
+		current     string
+		desired     configtypes.ActionsConfig
+		exclude     []string
+		want        []string
+		wantIgnored []string
+	}{
+		{
+			name:    "selected allowed actions",
+			current: `{"enabled":true,"allowed_actions":"all"}`,
+			desired: configtypes.ActionsConfig{
+				AllowedActions:  github.Ptr("selected"),
+				SelectedActions: selected,
+			},
+			want: []string{
+				"actions.allowed_actions",
+				"actions.selected_actions.github_owned_allowed",
+			},
+		},
+		{
+			name:    "all actions allowed",
+			current: `{"enabled":true,"allowed_actions":"selected"}`,
+			desired: configtypes.ActionsConfig{
+				AllowedActions:  github.Ptr("all"),
+				SelectedActions: selected,
+			},
+			want:        []string{"actions.allowed_actions"},
+			wantIgnored: []string{"actions.selected_actions"},
+		},
+		{
+			name:    "actions disabled",
+			current: `{"enabled":true,"allowed_actions":"selected"}`,
+			desired: configtypes.ActionsConfig{
+				Enabled:         github.Ptr(false),
+				AllowedActions:  github.Ptr("selected"),
+				SelectedActions: selected,
+			},
+			want:        []string{"actions.enabled"},
+			wantIgnored: []string{"actions.selected_actions"},
+		},
+		{
+			name:    "current allowed actions selected",
+			current: `{"enabled":true,"allowed_actions":"selected"}`,
+			desired: configtypes.ActionsConfig{SelectedActions: selected},
+			want:    []string{"actions.selected_actions.github_owned_allowed"},
+		},
+		{
+			name:    "current allowed actions kept by exclude",
+			current: `{"enabled":true,"allowed_actions":"local_only"}`,
+			desired: configtypes.ActionsConfig{
+				AllowedActions:  github.Ptr("selected"),
+				SelectedActions: selected,
+			},
+			exclude:     []string{"actions.allowed_actions"},
+			wantIgnored: []string{"actions.selected_actions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/actions/permissions": tt.current,
+			})
+
+			change, err := planActionsSettings(
+				context.Background(), client, "org", "repo", &tt.desired,
+				&github.Repository{}, tt.exclude,
+			)
+			if err != nil {
+				t.Fatalf("planActionsSettings() error = %v", err)
+			}
+
+			var fields []string
+			for _, diff := range actionsDiffs(change) {
+				fields = append(fields, diff.Field)
+			}
+
+			if diff := cmp.Diff(tt.want, fields); diff != "" {
+				t.Errorf("planActionsSettings() fields mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantIgnored, ignoredActionsSettings(change)); diff != "" {
+				t.Errorf("planActionsSettings() ignored mismatch (-want +got):\n%s", diff)
+			}
+
+			if tt.wantIgnored != nil &&
+				fake.called("GET /repos/org/repo/actions/permissions/selected-actions") {
+				t.Error("fetched selected actions that have no effect")
+			}
+		})
+	}
+}
+
+func TestApplyActionsChange(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"PUT /repos/org/repo/actions/permissions":                  `{}`,
+		"PUT /repos/org/repo/actions/permissions/selected-actions": `{}`,
+	})
+
+	change := &actionsChange{
+		Permissions: &github.ActionsPermissionsRepository{
+			Enabled:        github.Ptr(true),
+			AllowedActions: github.Ptr("selected"),
+		},
+		Allowed: &github.ActionsAllowed{
+			GithubOwnedAllowed: github.Ptr(true),
+			PatternsAllowed:    []string{"smykla-labs/*"},
+		},
+	}
+
+	if err := applyActionsChange(context.Background(), client, "org", "repo", change); err != nil {
+		t.Fatalf("applyActionsChange() error = %v", err)
+	}
+
+	want := []string{
+		"PUT /repos/org/repo/actions/permissions",
+		"PUT /repos/org/repo/actions/permissions/selected-actions",
+	}
+
+	if diff := cmp.Diff(want, fake.requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestApplySettingsMergeActions(t *testing.T) {
+	t.Parallel()
+
+	orgSettings := &SettingsDefinition{
+		Actions: configtypes.ActionsConfig{
+			Enabled:                    github.Ptr(true),
+			DefaultWorkflowPermissions: github.Ptr("read"),
+		},
+	}
+
+	syncConfig := &configtypes.SyncConfig{}
+	syncConfig.Sync.Settings.Merge = []configtypes.SettingsMergeConfig{
+		{
+			Section:   "actions",
+			Overrides: map[string]any{"default_workflow_permissions": "write"},
+		},
+	}
+
+	merged, err := ApplySettingsMerge(logger.New("error"), orgSettings, syncConfig)
+	if err != nil {
+		t.Fatalf("ApplySettingsMerge() error = %v", err)
+	}
+
+	want := configtypes.ActionsConfig{
+		Enabled:                    github.Ptr(true),
+		DefaultWorkflowPermissions: github.Ptr("write"),
+	}
+
+	if diff := cmp.Diff(want, merged.Actions); diff != "" {
+		t.Errorf("merged actions mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,301 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// Merge methods supported by auto-merge, in order of preference when no
+// method is configured.
+const (
+	MergeMethodSquash = "squash"
+	MergeMethodMerge  = "merge"
+	MergeMethodRebase = "rebase"
+)
+
+var (
+	// ErrNoMergeMethodAllowed is returned when a repository allows none of the merge methods.
+	ErrNoMergeMethodAllowed = errors.New("no merge method allowed by repository")
+	// ErrInvalidMergeMethod is returned when a configured merge method is not recognized.
+	ErrInvalidMergeMethod = errors.New("invalid merge method")
+)
+
+// defaultMergeMethodOrder is the fallback order used when the preferred method is unavailable.
+var defaultMergeMethodOrder = []string{MergeMethodSquash, MergeMethodMerge, MergeMethodRebase}
+
+// ValidateMergeMethod checks that method is empty (automatic) or a supported merge method.
+func ValidateMergeMethod(method string) error {
+	if method == "" || slices.Contains(defaultMergeMethodOrder, method) {
+		return nil
+	}
+
+	return errors.Wrapf(ErrInvalidMergeMethod, "%q (expected squash, merge or rebase)", method)
+}
+
+// enableAutoMerge arranges for a PR to merge once its requirements are met.
+//
+// When the base branch requires a merge queue, the PR is handed to the queue.
+// Otherwise auto-merge is enabled with a merge method the repository actually
+// allows, honouring preferredMethod when possible. The returned result records
+// which route was taken, and is populated even when an error is returned.
+func enableAutoMerge(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	prNumber int,
+	preferredMethod string,
+) (*AutoMergeResult, error) {
+	log.Debug("enabling auto-merge", "pr", prNumber, "preferred_method", preferredMethod)
+
+	pr, _, err := client.PullRequests.Get(ctx, org, repo, prNumber)
+	if err != nil {
+		return failedAutoMerge(err), errors.Wrap(err, "getting PR")
+	}
+
+	baseBranch := pr.GetBase().GetRef()
+
+	branchRules, _, err := client.Repositories.GetRulesForBranch(ctx, org, repo, baseBranch, nil)
+	if err != nil {
+		// Rules are only used to refine the decision; fall back to repository flags
+		log.Debug("failed to fetch branch rules", "branch", baseBranch, "error", err)
+	}
+
+	if queue := mergeQueueRule(branchRules); queue != nil {
+		return queuePullRequest(ctx, log, client, pr, queue)
+	}
+
+	repository, _, err := client.Repositories.Get(ctx, org, repo)
+	if err != nil {
+		return failedAutoMerge(err), errors.Wrap(err, "getting repository")
+	}
+
+	if !repository.GetAllowAutoMerge() {
+		log.Info("auto-merge is disabled in repository settings, skipping")
+
+		return &AutoMergeResult{
+			Route:  AutoMergeRouteSkipped,
+			Reason: "auto-merge is disabled in repository settings",
+		}, nil
+	}
+
+	method, err := selectMergeMethod(
+		preferredMethod,
+		repository,
+		rulesetAllowedMergeMethods(branchRules),
+	)
+	if err != nil {
+		return failedAutoMerge(err), err
+	}
+
+	if preferredMethod != "" && method != preferredMethod {
+		log.Info("preferred merge method not allowed, falling back",
+			"preferred", preferredMethod,
+			"method", method,
+		)
+	}
+
+	if err := enablePullRequestAutoMerge(ctx, client, pr.GetNodeID(), method); err != nil {
+		return failedAutoMerge(err), errors.Wrap(err, "enabling auto-merge")
+	}
+
+	log.Debug("auto-merge enabled", "method", method)
+
+	return &AutoMergeResult{
+		Route:  AutoMergeRouteAutoMerge,
+		Method: method,
+	}, nil
+}
+
+// queuePullRequest hands a PR to the merge queue of its base branch. Auto-merge is
+// enabled and GitHub adds the PR to the queue once its checks and reviews pass, so
+// the PR's mergeable state, usually still unknown for a fresh PR, does not matter.
+// When GitHub rejects auto-merge, typically because the requirements are already
+// met, the PR is enqueued right away. Either way it merges through the queue.
+func queuePullRequest(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	pr *github.PullRequest,
+	queue *github.MergeQueueRuleParameters,
+) (*AutoMergeResult, error) {
+	method := strings.ToLower(string(queue.MergeMethod))
+	result := &AutoMergeResult{
+		Route:  AutoMergeRouteMergeQueue,
+		Method: method,
+	}
+
+	// The merge queue decides the merge method
+	err := enablePullRequestAutoMerge(ctx, client, pr.GetNodeID(), "")
+	if err == nil {
+		log.Debug("auto-merge enabled, PR joins merge queue once requirements pass",
+			"method", method,
+		)
+
+		return result, nil
+	}
+
+	log.Debug("enabling auto-merge rejected, enqueuing PR instead", "error", err)
+
+	if err := enqueuePullRequest(ctx, client, pr.GetNodeID()); err != nil {
+		return failedAutoMerge(err), err
+	}
+
+	log.Debug("PR added to merge queue", "method", method)
+
+	return result, nil
+}
+
+// enablePullRequestAutoMerge enables auto-merge of a PR. An empty method leaves
+// the choice to GitHub, as required for branches with a merge queue.
+func enablePullRequestAutoMerge(
+	ctx context.Context,
+	client *Client,
+	nodeID string,
+	method string,
+) error {
+	mutation := `
+mutation($prId: ID!, $mergeMethod: PullRequestMergeMethod) {
+  enablePullRequestAutoMerge(input: {
+    pullRequestId: $prId,
+    mergeMethod: $mergeMethod
+  }) {
+    pullRequest {
+      autoMergeRequest {
+        enabledAt
+      }
+    }
+  }
+}`
+
+	variables := map[string]any{
+		"prId":        nodeID,
+		"mergeMethod": nil,
+	}
+
+	if method != "" {
+		variables["mergeMethod"] = strings.ToUpper(method)
+	}
+
+	return executeGraphQL(ctx, client, mutation, variables, nil)
+}
+
+// enqueuePullRequest adds a PR to the merge queue of its base branch.
+func enqueuePullRequest(ctx context.Context, client *Client, nodeID string) error {
+	mutation := `
+mutation($prId: ID!) {
+  enqueuePullRequest(input: {
+    pullRequestId: $prId
+  }) {
+    mergeQueueEntry {
+      position
+    }
+  }
+}`
+
+	variables := map[string]any{
+		"prId": nodeID,
+	}
+
+	return errors.Wrap(
+		executeGraphQL(ctx, client, mutation, variables, nil),
+		"enqueuing PR in merge queue",
+	)
+}
+
+// selectMergeMethod picks the merge method used for auto-merge.
+//
+// A method is available when the repository's allow_*_merge flag permits it and,
+// if rulesets restrict allowed merge methods, the rulesets permit it as well.
+// The preferred method wins when available; otherwise the first available method
+// in squash, merge, rebase order is used.
+func selectMergeMethod(
+	preferred string,
+	repository *github.Repository,
+	rulesetAllowed []string,
+) (string, error) {
+	allowedByRepo := map[string]bool{
+		MergeMethodSquash: repository.GetAllowSquashMerge(),
+		MergeMethodMerge:  repository.GetAllowMergeCommit(),
+		MergeMethodRebase: repository.GetAllowRebaseMerge(),
+	}
+
+	isAvailable := func(method string) bool {
+		if !allowedByRepo[method] {
+			return false
+		}
+
+		return rulesetAllowed == nil || slices.Contains(rulesetAllowed, method)
+	}
+
+	if preferred != "" && isAvailable(preferred) {
+		return preferred, nil
+	}
+
+	for _, method := range defaultMergeMethodOrder {
+		if isAvailable(method) {
+			return method, nil
+		}
+	}
+
+	return "", errors.WithStack(ErrNoMergeMethodAllowed)
+}
+
+// mergeQueueRule returns the merge queue parameters if the branch requires a merge queue.
+func mergeQueueRule(rules *github.BranchRules) *github.MergeQueueRuleParameters {
+	if rules == nil || len(rules.MergeQueue) == 0 {
+		return nil
+	}
+
+	return &rules.MergeQueue[0].Parameters
+}
+
+// rulesetAllowedMergeMethods returns the merge methods allowed by every pull request
+// rule applying to the branch. Returns nil when no rule restricts merge methods, and
+// an empty non-nil slice when the rules leave no method in common.
+func rulesetAllowedMergeMethods(rules *github.BranchRules) []string {
+	if rules == nil {
+		return nil
+	}
+
+	var allowed []string
+
+	for _, rule := range rules.PullRequest {
+		if len(rule.Parameters.AllowedMergeMethods) == 0 {
+			continue
+		}
+
+		methods := make([]string, 0, len(rule.Parameters.AllowedMergeMethods))
+		for _, method := range rule.Parameters.AllowedMergeMethods {
+			methods = append(methods, string(method))
+		}
+
+		if allowed == nil {
+			allowed = methods
+
+			continue
+		}
+
+		// Multiple rulesets apply: only methods allowed by all of them remain usable
+		allowed = slices.DeleteFunc(allowed, func(method string) bool {
+			return !slices.Contains(methods, method)
+		})
+	}
+
+	return allowed
+}
+
+// failedAutoMerge builds an auto-merge result for a failed attempt.
+func failedAutoMerge(err error) *AutoMergeResult {
+	return &AutoMergeResult{
+		Route:  AutoMergeRouteFailed,
+		Reason: err.Error(),
+	}
+}
@@ -0,0 +1,278 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestSelectMergeMethod(t *testing.T) {
+	t.Parallel()
+
+	allMethods := &github.Repository{
+		AllowSquashMerge: github.Ptr(true),
+		AllowMergeCommit: github.Ptr(true),
+		AllowRebaseMerge: github.Ptr(true),
+	}
+
+	tests := []struct {
+		name           string
+		preferred      string
+		repository     *github.Repository
+		rulesetAllowed []string
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:       "defaults to squash when all methods allowed",
+			repository: allMethods,
+			want:       MergeMethodSquash,
+		},
+		{
+			name:       "uses preferred method when allowed",
+			preferred:  MergeMethodRebase,
+			repository: allMethods,
+			want:       MergeMethodRebase,
+		},
+		{
+			name:      "falls back when preferred method disabled in repository",
+			preferred: MergeMethodSquash,
+			repository: &github.Repository{
+				AllowSquashMerge: github.Ptr(false),
+				AllowMergeCommit: github.Ptr(true),
+				AllowRebaseMerge: github.Ptr(true),
+			},
+			want: MergeMethodMerge,
+		},
+		{
+			name: "only rebase allowed",
+			repository: &github.Repository{
+				AllowSquashMerge: github.Ptr(false),
+				AllowMergeCommit: github.Ptr(false),
+				AllowRebaseMerge: github.Ptr(true),
+			},
+			want: MergeMethodRebase,
+		},
+		{
+			name:           "ruleset restricts allowed methods",
+			preferred:      MergeMethodSquash,
+			repository:     allMethods,
+			rulesetAllowed: []string{MergeMethodRebase},
+			want:           MergeMethodRebase,
+		},
+		{
+			name: "ruleset and repository have no method in common",
+			repository: &github.Repository{
+				AllowSquashMerge: github.Ptr(true),
+			},
+			rulesetAllowed: []string{MergeMethodMerge},
+			wantErr:        true,
+		},
+		{
+			name:           "empty ruleset intersection allows nothing",
+			repository:     allMethods,
+			rulesetAllowed: []string{},
+			wantErr:        true,
+		},
+		{
+			name:       "repository allows no method",
+			repository: &github.Repository{},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := selectMergeMethod(tt.preferred, tt.repository, tt.rulesetAllowed)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoMergeMethodAllowed) {
+					t.Errorf("selectMergeMethod() error = %v, want ErrNoMergeMethodAllowed", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("selectMergeMethod() unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("selectMergeMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesetAllowedMergeMethods(t *testing.T) {
+	t.Parallel()
+
+	pullRequestRule := func(methods ...github.PullRequestMergeMethod) *github.PullRequestBranchRule {
+		return &github.PullRequestBranchRule{
+			Parameters: github.PullRequestRuleParameters{AllowedMergeMethods: methods},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		rules *github.BranchRules
+		want  []string
+	}{
+		{
+			name:  "nil rules do not restrict",
+			rules: nil,
+			want:  nil,
+		},
+		{
+			name:  "pull request rule without allowed methods does not restrict",
+			rules: &github.BranchRules{PullRequest: []*github.PullRequestBranchRule{pullRequestRule()}},
+			want:  nil,
+		},
+		{
+			name: "single rule",
+			rules: &github.BranchRules{PullRequest: []*github.PullRequestBranchRule{
+				pullRequestRule(github.PullRequestMergeMethodSquash, github.PullRequestMergeMethodRebase),
+			}},
+			want: []string{MergeMethodSquash, MergeMethodRebase},
+		},
+		{
+			name: "multiple rules intersect",
+			rules: &github.BranchRules{PullRequest: []*github.PullRequestBranchRule{
+				pullRequestRule(github.PullRequestMergeMethodSquash, github.PullRequestMergeMethodRebase),
+				pullRequestRule(github.PullRequestMergeMethodRebase, github.PullRequestMergeMethodMerge),
+			}},
+			want: []string{MergeMethodRebase},
+		},
+		{
+			name: "disjoint rules leave nothing",
+			rules: &github.BranchRules{PullRequest: []*github.PullRequestBranchRule{
+				pullRequestRule(github.PullRequestMergeMethodSquash),
+				pullRequestRule(github.PullRequestMergeMethodMerge),
+			}},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := rulesetAllowedMergeMethods(tt.rules)
+
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("rulesetAllowedMergeMethods() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMergeMethod(t *testing.T) {
+	t.Parallel()
+
+	for _, method := range []string{"", MergeMethodSquash, MergeMethodMerge, MergeMethodRebase} {
+		if err := ValidateMergeMethod(method); err != nil {
+			t.Errorf("ValidateMergeMethod(%q) unexpected error: %v", method, err)
+		}
+	}
+
+	if err := ValidateMergeMethod("fast-forward"); !errors.Is(err, ErrInvalidMergeMethod) {
+		t.Errorf("ValidateMergeMethod() error = %v, want ErrInvalidMergeMethod", err)
+	}
+}
+
+func TestEnableAutoMergeWithMergeQueue(t *testing.T) {
+	t.Parallel()
+
+	const (
+		enqueueKey   = "POST /graphql enqueuePullRequest"
+		autoMergeKey = "POST /graphql enablePullRequestAutoMerge"
+	)
+
+	tests := []struct {
+		name           string
+		mergeableState string
+		autoMerge      string
+		enqueue        string
+		want           *AutoMergeResult
+		wantEnqueue    bool
+		wantErr        bool
+	}{
+		{
+			name:           "PR in unknown state joins the queue through auto-merge",
+			mergeableState: "unknown",
+			autoMerge:      `{"data":{"enablePullRequestAutoMerge":{"pullRequest":{}}}}`,
+			want:           &AutoMergeResult{Route: AutoMergeRouteMergeQueue, Method: "squash"},
+		},
+		{
+			name:           "PR with pending checks joins the queue through auto-merge",
+			mergeableState: "blocked",
+			autoMerge:      `{"data":{"enablePullRequestAutoMerge":{"pullRequest":{}}}}`,
+			want:           &AutoMergeResult{Route: AutoMergeRouteMergeQueue, Method: "squash"},
+		},
+		{
+			name:           "rejected auto-merge enqueues the PR",
+			mergeableState: "clean",
+			autoMerge:      `{"errors":[{"message":"Pull request is in clean status"}]}`,
+			enqueue:        `{"data":{"enqueuePullRequest":{"mergeQueueEntry":{"position":1}}}}`,
+			want:           &AutoMergeResult{Route: AutoMergeRouteMergeQueue, Method: "squash"},
+			wantEnqueue:    true,
+		},
+		{
+			name:           "rejected enqueue fails",
+			mergeableState: "dirty",
+			autoMerge:      `{"errors":[{"message":"Pull request is not mergeable"}]}`,
+			enqueue:        `{"errors":[{"message":"Pull request is not mergeable"}]}`,
+			want: &AutoMergeResult{
+				Route: AutoMergeRouteFailed,
+				Reason: "enqueuing PR in merge queue: " +
+					"Pull request is not mergeable: GraphQL request failed",
+			},
+			wantEnqueue: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/pulls/1": `{"node_id":"PR_1","mergeable_state":"` +
+					tt.mergeableState + `","base":{"ref":"main"}}`,
+				"GET /repos/org/repo/rules/branches/main": `[{"type":"merge_queue",` +
+					`"parameters":{"merge_method":"SQUASH"}}]`,
+				enqueueKey:   tt.enqueue,
+				autoMergeKey: tt.autoMerge,
+			})
+
+			got, err := enableAutoMerge(
+				context.Background(), logger.New("error"), client, "org", "repo", 1, "rebase",
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("enableAutoMerge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("enableAutoMerge() mismatch (-want +got):\n%s", diff)
+			}
+
+			if fake.called(enqueueKey) != tt.wantEnqueue {
+				t.Errorf("enqueued = %v, want %v", fake.called(enqueueKey), tt.wantEnqueue)
+			}
+
+			body := fake.body(autoMergeKey)
+			if !strings.Contains(body, `"mergeMethod":null`) {
+				t.Errorf("auto-merge request = %s, want merge method left to the queue", body)
+			}
+		})
+	}
+}
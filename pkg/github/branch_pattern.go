@@ -0,0 +1,162 @@
+package github
+
+import (
+	"strings"
+)
+
+// branchPatternWildcards are the characters that make a branch protection pattern
+// match more than a single branch name.
+const branchPatternWildcards = "*?["
+
+// matchBranchPattern reports whether a branch name matches a branch protection
+// pattern. GitHub matches patterns with fnmatch and FNM_PATHNAME: wildcards do not
+// match "/", and "**/" matches zero or more path segments.
+func matchBranchPattern(branch, pattern string) bool {
+	return matchPathSegments(strings.Split(pattern, "/"), strings.Split(branch, "/"))
+}
+
+// matchPathSegments matches pattern segments against name segments.
+func matchPathSegments(patterns []string, names []string) bool {
+	for len(patterns) > 0 {
+		// "**/" matches any number of segments, "**" at the end behaves like "*"
+		if patterns[0] == "**" && len(patterns) > 1 {
+			for i := range len(names) + 1 {
+				if matchPathSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 || !matchSegment(patterns[0], names[0]) {
+			return false
+		}
+
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
+
+// matchSegment matches a single path segment against a pattern supporting "*",
+// "?", bracket expressions and backslash escapes.
+func matchSegment(pattern, name string) bool {
+	var (
+		starPattern = -1
+		starName    = 0
+		p, n        int
+	)
+
+	for n < len(name) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				for p < len(pattern) && pattern[p] == '*' {
+					p++
+				}
+
+				starPattern, starName = p, n
+
+				continue
+			case '?':
+				p++
+				n++
+
+				continue
+			case '[':
+				if matched, width, ok := matchBracket(pattern[p:], name[n]); ok {
+					if matched {
+						p += width
+						n++
+
+						continue
+					}
+
+					break
+				}
+
+				if name[n] == '[' {
+					p++
+					n++
+
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == name[n] {
+					p += 2
+					n++
+
+					continue
+				}
+			default:
+				if pattern[p] == name[n] {
+					p++
+					n++
+
+					continue
+				}
+			}
+		}
+
+		// Backtrack to the last star, letting it consume one more character
+		if starPattern < 0 {
+			return false
+		}
+
+		starName++
+		p, n = starPattern, starName
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// matchBracket matches a character against a bracket expression at the start of
+// pattern. It returns whether the character matched, the width of the expression,
+// and false when the expression is not terminated.
+func matchBracket(pattern string, char byte) (bool, int, bool) {
+	i := 1
+	negate := false
+
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		negate = true
+		i++
+	}
+
+	matched := false
+
+	for first := true; i < len(pattern); first = false {
+		if pattern[i] == ']' && !first {
+			return matched != negate, i + 1, true
+		}
+
+		low := pattern[i]
+		if low == '\\' && i+1 < len(pattern) {
+			i++
+			low = pattern[i]
+		}
+
+		i++
+		high := low
+
+		if i+1 < len(pattern) && pattern[i] == '-' && pattern[i+1] != ']' {
+			high = pattern[i+1]
+			if high == '\\' && i+2 < len(pattern) {
+				i++
+				high = pattern[i+1]
+			}
+
+			i += 2
+		}
+
+		if low <= char && char <= high {
+			matched = true
+		}
+	}
+
+	return false, 0, false
+}
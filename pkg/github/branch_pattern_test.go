@@ -0,0 +1,41 @@
+package github
+
+import "testing"
+
+func TestMatchBranchPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		branch  string
+		want    bool
+	}{
+		{pattern: "main", branch: "main", want: true},
+		{pattern: "main", branch: "main2", want: false},
+		{pattern: "release/*", branch: "release/1.0", want: true},
+		{pattern: "release/*", branch: "release/1.0/hotfix", want: false},
+		{pattern: "release/**/*", branch: "release/1.0", want: true},
+		{pattern: "release/**/*", branch: "release/1.0/hotfix", want: true},
+		{pattern: "**/*", branch: "feature/a/b", want: true},
+		{pattern: "*", branch: "feature/a", want: false},
+		{pattern: "**", branch: "feature/a", want: false},
+		{pattern: "*[Rr]elease*", branch: "pre-Release-1", want: true},
+		{pattern: "v[0-9].?", branch: "v1.x", want: true},
+		{pattern: "v[!0-9]*", branch: "v1", want: false},
+		{pattern: `feat\*`, branch: "feat*", want: true},
+		{pattern: `feat\*`, branch: "feature", want: false},
+		{pattern: "a*b*c", branch: "axxbyyc", want: true},
+		{pattern: "a*b*c", branch: "axxbyy", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.branch, func(t *testing.T) {
+			t.Parallel()
+
+			if got := matchBranchPattern(tt.branch, tt.pattern); got != tt.want {
+				t.Errorf("matchBranchPattern(%q, %q) = %v, want %v",
+					tt.branch, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
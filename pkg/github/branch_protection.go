@@ -0,0 +1,356 @@
+package github
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// branchProtectionChange is a planned protection update for a pattern branch
+// protection rule or, when Branch is set, for a single branch.
+type branchProtectionChange struct {
+	BranchProtectionDiff
+
+	Request *github.ProtectionRequest
+	// Current is the existing protection, nil when the rule or branch is unprotected.
+	Current *github.Protection
+	// RepositoryID is the repository node ID used to create a pattern rule.
+	RepositoryID string
+	// RuleID is the node ID of the pattern rule to update, empty to create one.
+	RuleID string
+	// Delete removes the pattern rule RuleID instead of updating it.
+	Delete bool
+}
+
+// planBranchProtection compares branch protection with the desired state. Rules
+// are managed as pattern branch protection rules so branches created later are
+// protected too. When the GraphQL API cannot be used, it falls back to protecting
+// each existing branch matching the rules.
+func planBranchProtection(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	rules []configtypes.BranchProtectionRuleConfig,
+) ([]branchProtectionChange, error) {
+	for i := range rules {
+		if rules[i].RequiredReviews == nil {
+			continue
+		}
+
+		err := checkPullRequestAllowances(
+			ctx, client, org, rules[i].RequiredReviews.BypassPullRequestAllowances,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking bypass allowances of %q", rules[i].Pattern)
+		}
+	}
+
+	changes, err := planPatternProtection(ctx, log, client, org, repo, rules)
+	if err == nil {
+		return changes, nil
+	}
+
+	if !errors.Is(err, ErrGraphQL) {
+		return nil, err
+	}
+
+	log.Warn("branch protection rules unavailable, protecting existing branches instead",
+		"error", err,
+	)
+
+	return planBranchProtectionPerBranch(ctx, log, client, org, repo, rules)
+}
+
+// planBranchProtectionPerBranch compares the current protection of every branch
+// matching the rules with the desired state and returns the branches that need
+// updating.
+func planBranchProtectionPerBranch(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	rules []configtypes.BranchProtectionRuleConfig,
+) ([]branchProtectionChange, error) {
+	// Get all branches in the repository
+	branches, err := fetchBranches(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching branches")
+	}
+
+	log.Debug("fetched branches", "count", len(branches))
+
+	var changes []branchProtectionChange
+
+	for i := range rules {
+		rule := &rules[i]
+
+		// Find matching branches
+		matchingBranches := findMatchingBranches(branches, rule.Pattern)
+
+		log.Debug("found matching branches",
+			"pattern", rule.Pattern,
+			"count", len(matchingBranches),
+		)
+
+		for _, branch := range matchingBranches {
+			current, err := fetchBranchProtection(ctx, client, org, repo, branch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "fetching protection of branch %q", branch)
+			}
+
+			req := buildProtectionRequest(rule, current)
+
+			diffs := diffBranchProtection(current, req, rule)
+			if len(diffs) == 0 {
+				log.Debug("branch protection up to date", "branch", branch, "pattern", rule.Pattern)
+
+				continue
+			}
+
+			changes = append(changes, branchProtectionChange{
+				BranchProtectionDiff: BranchProtectionDiff{
+					Pattern: rule.Pattern,
+					Branch:  branch,
+					Fields:  diffs,
+				},
+				Request: req,
+				Current: current,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// diffBranchProtection compares current protection (nil when unprotected) with
+// the desired request field by field, using the config field names.
+func diffBranchProtection(
+	current *github.Protection,
+	desired *github.ProtectionRequest,
+	rule *configtypes.BranchProtectionRuleConfig,
+) []FieldDiff {
+	var diffs []FieldDiff
+
+	if current == nil {
+		diffs = append(diffs, FieldDiff{Field: "protected", Current: false, Desired: true})
+		current = &github.Protection{}
+	}
+
+	diffs = diffStatusChecks(diffs, current.RequiredStatusChecks, desired.RequiredStatusChecks)
+	diffs = diffReviews(
+		diffs, current.RequiredPullRequestReviews, desired.RequiredPullRequestReviews, rule,
+	)
+
+	diffs = appendDiff(diffs, "enforce_admins",
+		current.EnforceAdmins != nil && current.EnforceAdmins.Enabled,
+		desired.EnforceAdmins,
+	)
+	diffs = appendDiff(diffs, "require_linear_history",
+		current.RequireLinearHistory != nil && current.RequireLinearHistory.Enabled,
+		getBoolValue(desired.RequireLinearHistory),
+	)
+	diffs = appendDiff(diffs, "allow_force_pushes",
+		current.AllowForcePushes != nil && current.AllowForcePushes.Enabled,
+		getBoolValue(desired.AllowForcePushes),
+	)
+	diffs = appendDiff(diffs, "allow_deletions",
+		current.AllowDeletions != nil && current.AllowDeletions.Enabled,
+		getBoolValue(desired.AllowDeletions),
+	)
+	diffs = appendDiff(diffs, "required_conversation_resolution",
+		current.RequiredConversationResolution != nil &&
+			current.RequiredConversationResolution.Enabled,
+		getBoolValue(desired.RequiredConversationResolution),
+	)
+
+	return diffRestrictions(diffs, current.Restrictions, desired.Restrictions)
+}
+
+// diffStatusChecks compares required status checks.
+func diffStatusChecks(
+	diffs []FieldDiff,
+	current *github.RequiredStatusChecks,
+	desired *github.RequiredStatusChecks,
+) []FieldDiff {
+	if current == nil || desired == nil {
+		return appendDiff(diffs, "required_status_checks", current != nil, desired != nil)
+	}
+
+	diffs = appendDiff(diffs, "required_status_checks.strict", current.Strict, desired.Strict)
+
+	return appendSetDiff(diffs, "required_status_checks.contexts",
+		current.GetContexts(), desired.GetContexts())
+}
+
+// diffReviews compares required pull request reviews. Optional fields the rule
+// leaves unset are not compared.
+func diffReviews(
+	diffs []FieldDiff,
+	current *github.PullRequestReviewsEnforcement,
+	desired *github.PullRequestReviewsEnforcementRequest,
+	rule *configtypes.BranchProtectionRuleConfig,
+) []FieldDiff {
+	if current == nil || desired == nil {
+		return appendDiff(diffs, "required_reviews", current != nil, desired != nil)
+	}
+
+	diffs = appendDiff(diffs, "required_reviews.count",
+		current.RequiredApprovingReviewCount, desired.RequiredApprovingReviewCount)
+	diffs = appendDiff(diffs, "required_reviews.dismiss_stale",
+		current.DismissStaleReviews, desired.DismissStaleReviews)
+	diffs = appendDiff(diffs, "required_reviews.require_code_owner_reviews",
+		current.RequireCodeOwnerReviews, desired.RequireCodeOwnerReviews)
+
+	if desired.RequireLastPushApproval != nil {
+		diffs = appendDiff(diffs, "required_reviews.require_last_push_approval",
+			current.RequireLastPushApproval, *desired.RequireLastPushApproval)
+	}
+
+	if rule.RequiredReviews.BypassPullRequestAllowances == nil {
+		return diffs
+	}
+
+	bypass := desired.BypassPullRequestAllowancesRequest
+	currentBypass := current.BypassPullRequestAllowances
+
+	if currentBypass == nil {
+		currentBypass = &github.BypassPullRequestAllowances{}
+	}
+
+	const bypassField = "required_reviews.bypass_pull_request_allowances"
+
+	diffs = appendSetDiff(diffs, bypassField+".users",
+		userLogins(currentBypass.Users), bypass.Users)
+	diffs = appendSetDiff(diffs, bypassField+".teams",
+		teamSlugs(currentBypass.Teams), bypass.Teams)
+
+	return appendSetDiff(diffs, bypassField+".apps",
+		appSlugs(currentBypass.Apps), bypass.Apps)
+}
+
+// diffRestrictions compares push restrictions.
+func diffRestrictions(
+	diffs []FieldDiff,
+	current *github.BranchRestrictions,
+	desired *github.BranchRestrictionsRequest,
+) []FieldDiff {
+	if current == nil || desired == nil {
+		return appendDiff(diffs, "restrictions", current != nil, desired != nil)
+	}
+
+	diffs = appendSetDiff(diffs, "restrictions.users", userLogins(current.Users), desired.Users)
+	diffs = appendSetDiff(diffs, "restrictions.teams", teamSlugs(current.Teams), desired.Teams)
+
+	return appendSetDiff(diffs, "restrictions.apps", appSlugs(current.Apps), desired.Apps)
+}
+
+// applyBranchProtectionChanges applies planned branch protection updates.
+func applyBranchProtectionChanges(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	changes []branchProtectionChange,
+) error {
+	for _, change := range changes {
+		if change.Branch == "" {
+			if err := applyPatternProtectionChange(ctx, client, org, change); err != nil {
+				return err
+			}
+
+			log.Debug("applied branch protection rule",
+				"pattern", change.Pattern,
+				"created", change.RuleID == "",
+				"deleted", change.Delete,
+				"fields", len(change.Fields),
+			)
+
+			continue
+		}
+
+		_, _, err := client.Repositories.UpdateBranchProtection(
+			ctx, org, repo, change.Branch, change.Request,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "updating branch protection for %q", change.Branch)
+		}
+
+		log.Debug("applied branch protection",
+			"branch", change.Branch,
+			"pattern", change.Pattern,
+			"fields", len(change.Fields),
+		)
+	}
+
+	return nil
+}
+
+// logBranchProtectionChanges logs planned branch protection field changes.
+func logBranchProtectionChanges(log *logger.Logger, changes []branchProtectionChange) {
+	for _, change := range changes {
+		if change.Branch == "" {
+			log.Info("branch protection rule changes", "pattern", change.Pattern)
+		} else {
+			log.Info("branch protection changes",
+				"branch", change.Branch,
+				"pattern", change.Pattern,
+			)
+		}
+
+		for _, diff := range change.Fields {
+			log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+		}
+	}
+}
+
+// branchProtectionDiffs returns the exported diffs of planned changes.
+func branchProtectionDiffs(changes []branchProtectionChange) []BranchProtectionDiff {
+	diffs := make([]BranchProtectionDiff, 0, len(changes))
+
+	for _, change := range changes {
+		diffs = append(diffs, change.BranchProtectionDiff)
+	}
+
+	return diffs
+}
+
+// userLogins returns the logins of users.
+func userLogins(users []*github.User) []string {
+	logins := make([]string, 0, len(users))
+
+	for _, user := range users {
+		logins = append(logins, user.GetLogin())
+	}
+
+	return logins
+}
+
+// teamSlugs returns the slugs of teams.
+func teamSlugs(teams []*github.Team) []string {
+	slugs := make([]string, 0, len(teams))
+
+	for _, team := range teams {
+		slugs = append(slugs, team.GetSlug())
+	}
+
+	return slugs
+}
+
+// appSlugs returns the slugs of apps.
+func appSlugs(apps []*github.App) []string {
+	slugs := make([]string, 0, len(apps))
+
+	for _, app := range apps {
+		slugs = append(slugs, app.GetSlug())
+	}
+
+	return slugs
+}
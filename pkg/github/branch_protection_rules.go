@@ -0,0 +1,553 @@
+package github
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// ErrUnresolvedProtectionActor is returned when a user, team or app named in push
+// restrictions or pull request bypass allowances does not exist.
+var ErrUnresolvedProtectionActor = errors.New("unresolved branch protection actor")
+
+// branchProtectionRulesQuery lists the branch protection rules of a repository.
+const branchProtectionRulesQuery = `
+query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    id
+    branchProtectionRules(first: 100, after: $cursor) {
+      nodes {
+        id
+        pattern
+        requiresStatusChecks
+        requiresStrictStatusChecks
+        requiredStatusCheckContexts
+        requiresApprovingReviews
+        requiredApprovingReviewCount
+        dismissesStaleReviews
+        requiresCodeOwnerReviews
+        requireLastPushApproval
+        isAdminEnforced
+        requiresLinearHistory
+        allowsForcePushes
+        allowsDeletions
+        requiresConversationResolution
+        restrictsPushes
+        pushAllowances(first: 100) {
+          nodes { actor { ...protectionActor } }
+        }
+        bypassPullRequestAllowances(first: 100) {
+          nodes { actor { ...protectionActor } }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+}
+
+fragment protectionActor on BranchActorAllowanceActor {
+  __typename
+  ... on User { login }
+  ... on Team { slug }
+  ... on App { slug }
+}`
+
+// createBranchProtectionRuleMutation creates a pattern branch protection rule.
+const createBranchProtectionRuleMutation = `
+mutation($input: CreateBranchProtectionRuleInput!) {
+  createBranchProtectionRule(input: $input) {
+    branchProtectionRule { id }
+  }
+}`
+
+// updateBranchProtectionRuleMutation updates a pattern branch protection rule.
+const updateBranchProtectionRuleMutation = `
+mutation($input: UpdateBranchProtectionRuleInput!) {
+  updateBranchProtectionRule(input: $input) {
+    branchProtectionRule { id }
+  }
+}`
+
+// protectionActor is a user, team or app of a branch protection allowance.
+type protectionActor struct {
+	TypeName string `json:"__typename"`
+	Login    string `json:"login"`
+	Slug     string `json:"slug"`
+}
+
+// protectionAllowances is a connection of branch protection allowances.
+type protectionAllowances struct {
+	Nodes []struct {
+		Actor protectionActor `json:"actor"`
+	} `json:"nodes"`
+}
+
+// branchProtectionRuleNode is a branch protection rule returned by GraphQL.
+type branchProtectionRuleNode struct {
+	ID                             string               `json:"id"`
+	Pattern                        string               `json:"pattern"`
+	RequiresStatusChecks           bool                 `json:"requiresStatusChecks"`
+	RequiresStrictStatusChecks     bool                 `json:"requiresStrictStatusChecks"`
+	RequiredStatusCheckContexts    []string             `json:"requiredStatusCheckContexts"`
+	RequiresApprovingReviews       bool                 `json:"requiresApprovingReviews"`
+	RequiredApprovingReviewCount   int                  `json:"requiredApprovingReviewCount"`
+	DismissesStaleReviews          bool                 `json:"dismissesStaleReviews"`
+	RequiresCodeOwnerReviews       bool                 `json:"requiresCodeOwnerReviews"`
+	RequireLastPushApproval        bool                 `json:"requireLastPushApproval"`
+	IsAdminEnforced                bool                 `json:"isAdminEnforced"`
+	RequiresLinearHistory          bool                 `json:"requiresLinearHistory"`
+	AllowsForcePushes              bool                 `json:"allowsForcePushes"`
+	AllowsDeletions                bool                 `json:"allowsDeletions"`
+	RequiresConversationResolution bool                 `json:"requiresConversationResolution"`
+	RestrictsPushes                bool                 `json:"restrictsPushes"`
+	PushAllowances                 protectionAllowances `json:"pushAllowances"`
+	BypassPullRequestAllowances    protectionAllowances `json:"bypassPullRequestAllowances"`
+}
+
+// branchProtectionRules is the repository node ID and its branch protection rules
+// keyed by pattern.
+type branchProtectionRules struct {
+	RepositoryID string
+	Rules        map[string]*branchProtectionRuleNode
+}
+
+// planPatternProtection compares each configured rule with the branch protection
+// rule of the same pattern. Pattern rules also protect branches created later.
+// Unconfigured rules shadowing a configured pattern are deleted.
+func planPatternProtection(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	rules []configtypes.BranchProtectionRuleConfig,
+) ([]branchProtectionChange, error) {
+	existing, err := fetchBranchProtectionRules(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching branch protection rules")
+	}
+
+	log.Debug("fetched branch protection rules", "count", len(existing.Rules))
+
+	var changes []branchProtectionChange
+
+	for i := range rules {
+		rule := &rules[i]
+
+		var (
+			current *github.Protection
+			ruleID  string
+		)
+
+		if node, ok := existing.Rules[rule.Pattern]; ok {
+			current = node.protection()
+			ruleID = node.ID
+		}
+
+		req := buildProtectionRequest(rule, current)
+
+		diffs := diffBranchProtection(current, req, rule)
+		if len(diffs) == 0 {
+			log.Debug("branch protection rule up to date", "pattern", rule.Pattern)
+
+			continue
+		}
+
+		changes = append(changes, branchProtectionChange{
+			BranchProtectionDiff: BranchProtectionDiff{
+				Pattern: rule.Pattern,
+				Fields:  diffs,
+			},
+			Request:      req,
+			Current:      current,
+			RepositoryID: existing.RepositoryID,
+			RuleID:       ruleID,
+		})
+	}
+
+	return append(changes, planShadowingRuleDeletions(log, existing, rules)...), nil
+}
+
+// planShadowingRuleDeletions plans the deletion of unconfigured rules named after a
+// single branch that a configured pattern matches, such as release/1.0 for release/*.
+// Such rules are left behind by per-branch protection and take priority over the
+// pattern, so the configured protection would never reach the branch.
+func planShadowingRuleDeletions(
+	log *logger.Logger,
+	existing *branchProtectionRules,
+	rules []configtypes.BranchProtectionRuleConfig,
+) []branchProtectionChange {
+	configured := make(map[string]bool, len(rules))
+	for i := range rules {
+		configured[rules[i].Pattern] = true
+	}
+
+	var changes []branchProtectionChange
+
+	for _, pattern := range slices.Sorted(maps.Keys(existing.Rules)) {
+		if configured[pattern] || strings.ContainsAny(pattern, branchPatternWildcards) {
+			continue
+		}
+
+		index := slices.IndexFunc(rules, func(rule configtypes.BranchProtectionRuleConfig) bool {
+			return matchBranchPattern(pattern, rule.Pattern)
+		})
+		if index < 0 {
+			continue
+		}
+
+		log.Warn("branch protection rule shadows a configured pattern, deleting it",
+			"rule", pattern,
+			"configured_pattern", rules[index].Pattern,
+		)
+
+		node := existing.Rules[pattern]
+
+		changes = append(changes, branchProtectionChange{
+			BranchProtectionDiff: BranchProtectionDiff{
+				Pattern: pattern,
+				Fields:  []FieldDiff{{Field: deletedField, Current: false, Desired: true}},
+			},
+			Current: node.protection(),
+			RuleID:  node.ID,
+			Delete:  true,
+		})
+	}
+
+	return changes
+}
+
+// fetchBranchProtectionRules lists the branch protection rules of a repository.
+func fetchBranchProtectionRules(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (*branchProtectionRules, error) {
+	result := &branchProtectionRules{Rules: make(map[string]*branchProtectionRuleNode)}
+
+	var cursor *string
+
+	for {
+		var data struct {
+			Repository struct {
+				ID                    string `json:"id"`
+				BranchProtectionRules struct {
+					Nodes    []*branchProtectionRuleNode `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"branchProtectionRules"`
+			} `json:"repository"`
+		}
+
+		variables := map[string]any{
+			"owner":  org,
+			"name":   repo,
+			"cursor": cursor,
+		}
+
+		err := executeGraphQL(ctx, client, branchProtectionRulesQuery, variables, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		result.RepositoryID = data.Repository.ID
+
+		for _, node := range data.Repository.BranchProtectionRules.Nodes {
+			result.Rules[node.Pattern] = node
+		}
+
+		pageInfo := data.Repository.BranchProtectionRules.PageInfo
+		if !pageInfo.HasNextPage {
+			return result, nil
+		}
+
+		cursor = github.Ptr(pageInfo.EndCursor)
+	}
+}
+
+// protection converts a branch protection rule to the REST protection shape so it
+// can be compared with the same diff as per-branch protection.
+func (n *branchProtectionRuleNode) protection() *github.Protection {
+	protection := &github.Protection{
+		EnforceAdmins:        &github.AdminEnforcement{Enabled: n.IsAdminEnforced},
+		RequireLinearHistory: &github.RequireLinearHistory{Enabled: n.RequiresLinearHistory},
+		AllowForcePushes:     &github.AllowForcePushes{Enabled: n.AllowsForcePushes},
+		AllowDeletions:       &github.AllowDeletions{Enabled: n.AllowsDeletions},
+		RequiredConversationResolution: &github.RequiredConversationResolution{
+			Enabled: n.RequiresConversationResolution,
+		},
+	}
+
+	if n.RequiresStatusChecks {
+		contexts := nonNilStrings(n.RequiredStatusCheckContexts)
+		protection.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   n.RequiresStrictStatusChecks,
+			Contexts: &contexts,
+		}
+	}
+
+	if n.RequiresApprovingReviews {
+		users, teams, apps := n.BypassPullRequestAllowances.actors()
+
+		protection.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcement{
+			RequiredApprovingReviewCount: n.RequiredApprovingReviewCount,
+			DismissStaleReviews:          n.DismissesStaleReviews,
+			RequireCodeOwnerReviews:      n.RequiresCodeOwnerReviews,
+			RequireLastPushApproval:      n.RequireLastPushApproval,
+			BypassPullRequestAllowances: &github.BypassPullRequestAllowances{
+				Users: users,
+				Teams: teams,
+				Apps:  apps,
+			},
+		}
+	}
+
+	if n.RestrictsPushes {
+		users, teams, apps := n.PushAllowances.actors()
+
+		protection.Restrictions = &github.BranchRestrictions{
+			Users: users,
+			Teams: teams,
+			Apps:  apps,
+		}
+	}
+
+	return protection
+}
+
+// actors splits allowances into users, teams and apps.
+func (a protectionAllowances) actors() ([]*github.User, []*github.Team, []*github.App) {
+	var (
+		users []*github.User
+		teams []*github.Team
+		apps  []*github.App
+	)
+
+	for _, node := range a.Nodes {
+		switch node.Actor.TypeName {
+		case "User":
+			users = append(users, &github.User{Login: github.Ptr(node.Actor.Login)})
+		case "Team":
+			teams = append(teams, &github.Team{Slug: github.Ptr(node.Actor.Slug)})
+		case "App":
+			apps = append(apps, &github.App{Slug: github.Ptr(node.Actor.Slug)})
+		}
+	}
+
+	return users, teams, apps
+}
+
+// applyPatternProtectionChange creates, updates or deletes a branch protection rule.
+func applyPatternProtectionChange(
+	ctx context.Context,
+	client *Client,
+	org string,
+	change branchProtectionChange,
+) error {
+	if change.Delete {
+		variables := map[string]any{
+			"input": map[string]any{"branchProtectionRuleId": change.RuleID},
+		}
+
+		err := executeGraphQL(ctx, client, deleteBranchProtectionRuleMutation, variables, nil)
+
+		return errors.Wrapf(err, "deleting branch protection rule %q", change.Pattern)
+	}
+
+	input, err := branchProtectionRuleInput(ctx, client, org, change.Pattern, change.Request)
+	if err != nil {
+		return err
+	}
+
+	mutation := updateBranchProtectionRuleMutation
+
+	if change.RuleID == "" {
+		mutation = createBranchProtectionRuleMutation
+		input["repositoryId"] = change.RepositoryID
+	} else {
+		input["branchProtectionRuleId"] = change.RuleID
+	}
+
+	variables := map[string]any{"input": input}
+
+	if err := executeGraphQL(ctx, client, mutation, variables, nil); err != nil {
+		return errors.Wrapf(err, "saving branch protection rule %q", change.Pattern)
+	}
+
+	return nil
+}
+
+// branchProtectionRuleInput converts a protection request to the input of the
+// branch protection rule mutations, resolving actors to node IDs.
+func branchProtectionRuleInput(
+	ctx context.Context,
+	client *Client,
+	org string,
+	pattern string,
+	req *github.ProtectionRequest,
+) (map[string]any, error) {
+	input := map[string]any{
+		"pattern":                        pattern,
+		"requiresStatusChecks":           req.RequiredStatusChecks != nil,
+		"requiresApprovingReviews":       req.RequiredPullRequestReviews != nil,
+		"isAdminEnforced":                req.EnforceAdmins,
+		"requiresLinearHistory":          getBoolValue(req.RequireLinearHistory),
+		"allowsForcePushes":              getBoolValue(req.AllowForcePushes),
+		"allowsDeletions":                getBoolValue(req.AllowDeletions),
+		"requiresConversationResolution": getBoolValue(req.RequiredConversationResolution),
+		"restrictsPushes":                req.Restrictions != nil,
+	}
+
+	if checks := req.RequiredStatusChecks; checks != nil {
+		input["requiresStrictStatusChecks"] = checks.Strict
+		input["requiredStatusCheckContexts"] = nonNilStrings(checks.GetContexts())
+	}
+
+	if reviews := req.RequiredPullRequestReviews; reviews != nil {
+		input["requiredApprovingReviewCount"] = reviews.RequiredApprovingReviewCount
+		input["dismissesStaleReviews"] = reviews.DismissStaleReviews
+		input["requiresCodeOwnerReviews"] = reviews.RequireCodeOwnerReviews
+
+		if reviews.RequireLastPushApproval != nil {
+			input["requireLastPushApproval"] = *reviews.RequireLastPushApproval
+		}
+
+		if bypass := reviews.BypassPullRequestAllowancesRequest; bypass != nil {
+			ids, err := client.resolveActorNodeIDs(
+				ctx, org, bypass.Users, bypass.Teams, bypass.Apps,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "resolving pull request bypass allowances")
+			}
+
+			input["bypassPullRequestActorIds"] = ids
+		}
+	}
+
+	if restrictions := req.Restrictions; restrictions != nil {
+		ids, err := client.resolveActorNodeIDs(
+			ctx, org, restrictions.Users, restrictions.Teams, restrictions.Apps,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "resolving push restrictions")
+		}
+
+		input["pushActorIds"] = ids
+	}
+
+	return input, nil
+}
+
+// resolveActorNodeIDs returns the node IDs of users, teams and apps, naming every
+// one that does not exist in the error.
+func (c *Client) resolveActorNodeIDs(
+	ctx context.Context,
+	org string,
+	users []string,
+	teams []string,
+	apps []string,
+) ([]string, error) {
+	ids := make([]string, 0, len(users)+len(teams)+len(apps))
+
+	var unresolved []string
+
+	resolve := func(kind string, name string, lookup func() (string, error)) error {
+		id, err := c.cachedNodeID(kind+":"+name, lookup)
+		if err != nil {
+			return errors.Wrapf(err, "resolving %s %q", kind, name)
+		}
+
+		if id == "" {
+			unresolved = append(unresolved, kind+" "+strconv.Quote(name))
+
+			return nil
+		}
+
+		ids = append(ids, id)
+
+		return nil
+	}
+
+	for _, login := range users {
+		err := resolve("user", login, func() (string, error) {
+			user, _, err := c.Users.Get(ctx, login)
+
+			return user.GetNodeID(), err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, slug := range teams {
+		err := resolve("team", slug, func() (string, error) {
+			team, _, err := c.Teams.GetTeamBySlug(ctx, org, slug)
+
+			return team.GetNodeID(), err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, slug := range apps {
+		err := resolve("app", slug, func() (string, error) {
+			app, _, err := c.Apps.Get(ctx, slug)
+
+			return app.GetNodeID(), err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return nil, errors.Wrapf(
+			ErrUnresolvedProtectionActor, "%s", strings.Join(unresolved, ", "),
+		)
+	}
+
+	return ids, nil
+}
+
+// cachedNodeID returns the cached node ID for key, calling lookup on a cache miss.
+// Actors that do not exist are cached as an empty ID.
+func (c *Client) cachedNodeID(key string, lookup func() (string, error)) (string, error) {
+	c.actors.mu.Lock()
+	defer c.actors.mu.Unlock()
+
+	if id, ok := c.actors.nodeIDs[key]; ok {
+		return id, nil
+	}
+
+	id, err := lookup()
+	if err != nil {
+		if !isNotFoundError(err) {
+			return "", err
+		}
+
+		id = ""
+	}
+
+	if c.actors.nodeIDs == nil {
+		c.actors.nodeIDs = make(map[string]string)
+	}
+
+	c.actors.nodeIDs[key] = id
+
+	return id, nil
+}
@@ -0,0 +1,234 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestPlanBranchProtectionPatternRules(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, map[string]string{
+		"POST /graphql": `{"data":{"repository":{"id":"R_1","branchProtectionRules":{
+			"nodes":[{
+				"id":"BPR_1","pattern":"main",
+				"requiresStatusChecks":true,"requiresStrictStatusChecks":true,
+				"requiredStatusCheckContexts":["lint","test"],
+				"isAdminEnforced":true
+			}],
+			"pageInfo":{"hasNextPage":false}
+		}}}}`,
+	})
+
+	rules := []configtypes.BranchProtectionRuleConfig{
+		{
+			Pattern: "main",
+			RequiredStatusChecks: &configtypes.RequiredStatusChecks{
+				Strict:   github.Ptr(true),
+				Contexts: []string{"test", "lint"},
+			},
+			EnforceAdmins: github.Ptr(true),
+		},
+		{
+			Pattern:        "release/**/*",
+			EnforceAdmins:  github.Ptr(true),
+			AllowDeletions: github.Ptr(false),
+		},
+	}
+
+	changes, err := planBranchProtection(
+		context.Background(), logger.New("error"), client, "org", "repo", rules,
+	)
+	if err != nil {
+		t.Fatalf("planBranchProtection() error = %v", err)
+	}
+
+	want := []branchProtectionChange{
+		{
+			BranchProtectionDiff: BranchProtectionDiff{
+				Pattern: "release/**/*",
+				Fields: []FieldDiff{
+					{Field: "protected", Current: false, Desired: true},
+					{Field: "enforce_admins", Current: false, Desired: true},
+				},
+			},
+			RepositoryID: "R_1",
+		},
+	}
+
+	if diff := cmp.Diff(want, changes,
+		cmp.FilterPath(func(path cmp.Path) bool {
+			return path.Last().String() == ".Request"
+		}, cmp.Ignore()),
+	); diff != "" {
+		t.Errorf("planBranchProtection() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanBranchProtectionDeletesShadowingRules(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, map[string]string{
+		"POST /graphql": `{"data":{"repository":{"id":"R_1","branchProtectionRules":{
+			"nodes":[
+				{"id":"BPR_1","pattern":"release/*","isAdminEnforced":true},
+				{"id":"BPR_2","pattern":"release/1.0"},
+				{"id":"BPR_3","pattern":"hotfix"},
+				{"id":"BPR_4","pattern":"feature/*"}
+			],
+			"pageInfo":{"hasNextPage":false}
+		}}}}`,
+	})
+
+	rules := []configtypes.BranchProtectionRuleConfig{
+		{Pattern: "release/*", EnforceAdmins: github.Ptr(true)},
+	}
+
+	changes, err := planBranchProtection(
+		context.Background(), logger.New("error"), client, "org", "repo", rules,
+	)
+	if err != nil {
+		t.Fatalf("planBranchProtection() error = %v", err)
+	}
+
+	want := []branchProtectionChange{
+		{
+			BranchProtectionDiff: BranchProtectionDiff{
+				Pattern: "release/1.0",
+				Fields:  []FieldDiff{{Field: deletedField, Current: false, Desired: true}},
+			},
+			RuleID: "BPR_2",
+			Delete: true,
+		},
+	}
+
+	if diff := cmp.Diff(want, changes,
+		cmp.FilterPath(func(path cmp.Path) bool {
+			return path.Last().String() == ".Current"
+		}, cmp.Ignore()),
+	); diff != "" {
+		t.Errorf("planBranchProtection() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanBranchProtectionFallsBackToBranches(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"POST /graphql": `{"errors":[{"type":"FORBIDDEN","message":"Resource not accessible"}]}`,
+		"GET /repos/org/repo/branches": `[{"name":"main"},{"name":"release/1.0"},
+			{"name":"release/1.0/hotfix"}]`,
+	})
+
+	rules := []configtypes.BranchProtectionRuleConfig{
+		{Pattern: "release/*", EnforceAdmins: github.Ptr(true)},
+	}
+
+	changes, err := planBranchProtection(
+		context.Background(), logger.New("error"), client, "org", "repo", rules,
+	)
+	if err != nil {
+		t.Fatalf("planBranchProtection() error = %v", err)
+	}
+
+	if !fake.called("GET /repos/org/repo/branches/release/1.0/protection") {
+		t.Error("expected protection of release/1.0 to be fetched")
+	}
+
+	var branches []string
+	for _, change := range changes {
+		branches = append(branches, change.Branch)
+	}
+
+	if diff := cmp.Diff([]string{"release/1.0"}, branches); diff != "" {
+		t.Errorf("protected branches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestApplyPatternProtectionChange(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"POST /graphql": `{"data":{"createBranchProtectionRule":{
+			"branchProtectionRule":{"id":"BPR_2"}}}}`,
+		"GET /orgs/org/teams/maintainers": `{"id":1,"node_id":"T_1","slug":"maintainers"}`,
+	})
+
+	rule := &configtypes.BranchProtectionRuleConfig{
+		Pattern:       "release/*",
+		EnforceAdmins: github.Ptr(true),
+		Restrictions:  &configtypes.BranchRestrictionsConfig{Teams: []string{"maintainers"}},
+	}
+
+	change := branchProtectionChange{
+		BranchProtectionDiff: BranchProtectionDiff{Pattern: rule.Pattern},
+		Request:              buildProtectionRequest(rule, nil),
+		RepositoryID:         "R_1",
+	}
+
+	err := applyPatternProtectionChange(context.Background(), client, "org", change)
+	if err != nil {
+		t.Fatalf("applyPatternProtectionChange() error = %v", err)
+	}
+
+	var body struct {
+		Variables struct {
+			Input map[string]any `json:"input"`
+		} `json:"variables"`
+	}
+
+	if err = json.Unmarshal([]byte(fake.body("POST /graphql")), &body); err != nil {
+		t.Fatalf("decoding request body: %v", err)
+	}
+
+	want := map[string]any{
+		"repositoryId":                   "R_1",
+		"pattern":                        "release/*",
+		"requiresStatusChecks":           false,
+		"requiresApprovingReviews":       false,
+		"isAdminEnforced":                true,
+		"requiresLinearHistory":          false,
+		"allowsForcePushes":              false,
+		"allowsDeletions":                false,
+		"requiresConversationResolution": false,
+		"restrictsPushes":                true,
+		"pushActorIds":                   []any{"T_1"},
+	}
+
+	if diff := cmp.Diff(want, body.Variables.Input); diff != "" {
+		t.Errorf("mutation input mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestApplyPatternProtectionChangeDeletesRule(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"POST /graphql": `{"data":{"deleteBranchProtectionRule":{"clientMutationId":null}}}`,
+	})
+
+	change := branchProtectionChange{
+		BranchProtectionDiff: BranchProtectionDiff{Pattern: "release/1.0"},
+		RuleID:               "BPR_2",
+		Delete:               true,
+	}
+
+	err := applyPatternProtectionChange(context.Background(), client, "org", change)
+	if err != nil {
+		t.Fatalf("applyPatternProtectionChange() error = %v", err)
+	}
+
+	body := fake.body("POST /graphql")
+	if !strings.Contains(body, "deleteBranchProtectionRule") ||
+		!strings.Contains(body, `"branchProtectionRuleId":"BPR_2"`) {
+		t.Errorf("request = %s, want deletion of BPR_2", body)
+	}
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+)
+
+func TestDiffBranchProtection(t *testing.T) {
+	t.Parallel()
+
+	rule := configtypes.BranchProtectionRuleConfig{
+		Pattern: "main",
+		RequiredStatusChecks: &configtypes.RequiredStatusChecks{
+			Strict:   github.Ptr(true),
+			Contexts: []string{"test", "lint"},
+		},
+		RequiredReviews: &configtypes.RequiredReviews{
+			RequiredApprovingReviewCount: github.Ptr(1),
+			DismissStaleReviews:          github.Ptr(true),
+		},
+		EnforceAdmins:        github.Ptr(true),
+		RequireLinearHistory: github.Ptr(true),
+	}
+
+	inSync := func() *github.Protection {
+		return &github.Protection{
+			RequiredStatusChecks: &github.RequiredStatusChecks{
+				Strict:   true,
+				Contexts: &[]string{"lint", "test"},
+			},
+			RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{
+				RequiredApprovingReviewCount: 1,
+				DismissStaleReviews:          true,
+			},
+			EnforceAdmins:        &github.AdminEnforcement{Enabled: true},
+			RequireLinearHistory: &github.RequireLinearHistory{Enabled: true},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		current func() *github.Protection
+		want    []string
+	}{
+		{
+			name:    "in sync with contexts in different order",
+			current: inSync,
+		},
+		{
+			name: "higher current review count is kept",
+			current: func() *github.Protection {
+				protection := inSync()
+				protection.RequiredPullRequestReviews.RequiredApprovingReviewCount = 3
+
+				return protection
+			},
+		},
+		{
+			name:    "unprotected branch",
+			current: func() *github.Protection { return nil },
+			want: []string{
+				"protected",
+				"required_status_checks",
+				"required_reviews",
+				"enforce_admins",
+				"require_linear_history",
+			},
+		},
+		{
+			name: "changed fields",
+			current: func() *github.Protection {
+				protection := inSync()
+				protection.RequiredStatusChecks.Contexts = &[]string{"test"}
+				protection.EnforceAdmins.Enabled = false
+				protection.AllowForcePushes = &github.AllowForcePushes{Enabled: true}
+				protection.Restrictions = &github.BranchRestrictions{
+					Users: []*github.User{{Login: github.Ptr("octocat")}},
+				}
+
+				return protection
+			},
+			want: []string{
+				"required_status_checks.contexts",
+				"enforce_admins",
+				"allow_force_pushes",
+				"restrictions",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			current := tt.current()
+			diffs := diffBranchProtection(current, buildProtectionRequest(&rule, current), &rule)
+
+			var fields []string
+			for _, diff := range diffs {
+				fields = append(fields, diff.Field)
+			}
+
+			if diff := cmp.Diff(tt.want, fields); diff != "" {
+				t.Errorf("diffBranchProtection() fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const bundleCommitTitle = syncCommitPrefix + " sync organization files and smyklot workflows"
+
+// SyncBundle synchronizes organization files and smyklot workflows through a single
+// branch and pull request. The bundle PR uses the files sync branch and supersedes
+// the standalone smyklot PR, which is closed once the bundle PR is published.
+//
+//nolint:funlen // Sequential sync steps, mirrors SyncFiles and SyncSmyklot
+func SyncBundle(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	sourceRepo string,
+	filesConfig string,
+	version string,
+	tag string,
+	sha string,
+	syncConfig *configtypes.SyncConfig,
+	templatesDir string,
+	smyklotFilePath string,
+	branchPrefix string,
+	prLabels []string,
+	mergeMethod string,
+	perFileCommits bool,
+	commitOpts *CommitOptions,
+	dryRun bool,
+) (*BundleSyncResult, error) {
+	result := NewBundleSyncResult(repo, dryRun)
+	prManager := NewPRManager(log, client, org, repo)
+	branchName := prManager.BranchName(branchPrefix)
+
+	syncFiles := !syncConfig.Sync.Skip && !syncConfig.Sync.Files.Skip
+	syncSmyklot := !syncConfig.Sync.Skip && !syncConfig.Sync.Smyklot.Skip
+
+	// Check if sync is skipped
+	if !syncFiles && !syncSmyklot {
+		log.Info("bundle sync skipped by config")
+
+		if _, err := prManager.Close(ctx, branchName, PRCloseReasonDisabled,
+			"Synchronization is disabled for this repository"); err != nil {
+			log.Warn("failed to close existing PR", "error", err)
+		}
+
+		result.CompleteSkipped("sync disabled by config")
+
+		return result, nil
+	}
+
+	// Get repository info
+	defaultBranch, baseSHA, err := getRepoBaseInfo(ctx, log, client, org, repo)
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "getting repository base info"))
+
+		return result, err
+	}
+
+	var fileChanges []FileChange
+
+	fileStats := &FileSyncStats{}
+
+	if syncFiles {
+		fileChanges, fileStats, err = planFileChanges(
+			ctx, log, client, org, repo, sourceRepo, defaultBranch, filesConfig, syncConfig,
+		)
+		if err != nil {
+			result.CompleteWithError(err)
+
+			return result, err
+		}
+	} else {
+		log.Info("file sync skipped by config")
+	}
+
+	var smyklotChanges []FileChange
+
+	smyklotStats := &SmyklotSyncStats{}
+
+	if syncSmyklot {
+		smyklotChanges, smyklotStats, err = planSmyklotChanges(
+			ctx, log, client, org, repo, version, tag, sha,
+			syncConfig, templatesDir, smyklotFilePath,
+		)
+		if err != nil {
+			result.CompleteWithError(err)
+
+			return result, err
+		}
+	} else {
+		log.Info("smyklot sync skipped by config")
+	}
+
+	fileChanges = dropOverlappingChanges(log, fileChanges, smyklotChanges)
+
+	// Populate result from stats
+	result.CreatedFiles = fileStats.CreatedFiles
+	result.UpdatedFiles = fileStats.UpdatedFiles
+	result.DeletedFiles = fileStats.DeletedFiles
+	result.HasDeletionsWarn = len(fileStats.DeletedFiles) > 0
+	result.InstalledFiles = smyklotStats.InstalledFiles
+	result.ReplacedFiles = smyklotStats.ReplacedFiles
+	result.VersionOnlyFiles = smyklotStats.VersionOnlyFiles
+
+	// If no changes, close any existing PR
+	if len(fileChanges)+len(smyklotChanges) == 0 {
+		log.Info("no changes needed")
+
+		if _, closeErr := prManager.Close(ctx, branchName, PRCloseReasonInSync,
+			"Files and smyklot workflows are in sync. Closing this PR."); closeErr != nil {
+			log.Warn("failed to close existing PR", "error", closeErr)
+		}
+
+		result.Complete(StatusSuccess)
+
+		return result, nil
+	}
+
+	if dryRun {
+		log.Info("dry-run mode: skipping PR creation")
+		logFileChanges(log, fileStats)
+		logSmyklotChanges(log, smyklotStats)
+		result.Complete(StatusSuccess)
+
+		return result, nil
+	}
+
+	// Create/update PR
+	body := buildBundlePRBody(
+		org, sourceRepo, tag, fileChanges, fileStats, smyklotChanges, smyklotStats,
+	)
+
+	spec := &pullRequestSpec{
+		Branch:      branchName,
+		Base:        defaultBranch,
+		Title:       buildBundlePRTitle(tag, fileChanges, smyklotChanges, smyklotStats),
+		Body:        body,
+		Labels:      bundlePRLabels(prLabels, fileStats, len(smyklotChanges) > 0),
+		MergeMethod: mergeMethod,
+	}
+
+	if fileStats.Deleted > 0 {
+		spec.AutoMergeSkipReason = filesDeletionSkipReason
+	}
+
+	groups := buildBundleCommitGroups(fileChanges, smyklotChanges, perFileCommits)
+
+	pr, err := prManager.Publish(ctx, baseSHA, spec, groups, commitOpts)
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "creating or updating PR"))
+
+		return result, err
+	}
+
+	result.PRNumber = pr.Number
+	result.PRURL = pr.URL
+	result.AutoMerge = pr.AutoMerge
+
+	// Close the standalone smyklot PR, its changes are part of the bundle now
+	supersededComment := fmt.Sprintf(
+		"Superseded by #%d, which bundles organization files and smyklot changes.", pr.Number,
+	)
+
+	superseded, err := prManager.Close(
+		ctx, smyklotBranchPrefix, PRCloseReasonSuperseded, supersededComment,
+	)
+	if err != nil {
+		log.Warn("failed to close superseded smyklot PR", "error", err)
+	}
+
+	if superseded != 0 {
+		result.SupersededPRs = append(result.SupersededPRs, superseded)
+	}
+
+	log.Info("bundle sync completed successfully")
+	result.Complete(StatusSuccess)
+
+	return result, nil
+}
+
+// dropOverlappingChanges removes file changes for paths also changed by smyklot.
+// Smyklot owns its workflow files, so its version wins.
+func dropOverlappingChanges(
+	log *logger.Logger,
+	fileChanges []FileChange,
+	smyklotChanges []FileChange,
+) []FileChange {
+	if len(fileChanges) == 0 || len(smyklotChanges) == 0 {
+		return fileChanges
+	}
+
+	smyklotPaths := make(map[string]bool, len(smyklotChanges))
+	for _, change := range smyklotChanges {
+		smyklotPaths[change.Path] = true
+	}
+
+	return slices.DeleteFunc(slices.Clone(fileChanges), func(change FileChange) bool {
+		if !smyklotPaths[change.Path] {
+			return false
+		}
+
+		log.Warn("file is changed by both file and smyklot sync, using smyklot version",
+			"path", change.Path,
+		)
+
+		return true
+	})
+}
+
+// buildBundleCommitGroups groups file changes before smyklot changes, skipping empty groups.
+func buildBundleCommitGroups(
+	fileChanges []FileChange,
+	smyklotChanges []FileChange,
+	perFileCommits bool,
+) []commitGroup {
+	var groups []commitGroup
+
+	if len(fileChanges) > 0 {
+		groups = append(groups, buildCommitGroups(fileChanges, perFileCommits)...)
+	}
+
+	if len(smyklotChanges) == 0 {
+		return groups
+	}
+
+	if perFileCommits {
+		return append(groups, buildCommitGroups(smyklotChanges, true)...)
+	}
+
+	return append(groups, commitGroup{Message: smyklotCommitMessage, Changes: smyklotChanges})
+}
+
+// bundlePRLabels returns the configured PR labels with the smyklot label when
+// smyklot changes are included and the destructive label for deletions.
+func bundlePRLabels(prLabels []string, stats *FileSyncStats, hasSmyklotChanges bool) []string {
+	labels := filesPRLabels(prLabels, stats)
+
+	if hasSmyklotChanges && !slices.Contains(labels, smyklotPRLabel) {
+		labels = append(labels, smyklotPRLabel)
+	}
+
+	return labels
+}
+
+// buildBundlePRTitle builds the PR title, falling back to the standalone titles
+// when only one kind of change is present.
+func buildBundlePRTitle(
+	tag string,
+	fileChanges []FileChange,
+	smyklotChanges []FileChange,
+	smyklotStats *SmyklotSyncStats,
+) string {
+	switch {
+	case len(smyklotChanges) == 0:
+		return filesCommitMessage
+	case len(fileChanges) == 0:
+		return buildSmyklotPRTitle(tag, smyklotStats)
+	default:
+		return bundleCommitTitle
+	}
+}
+
+// buildBundlePRBody builds the PR body text with a section per sync type.
+func buildBundlePRBody(
+	org string,
+	sourceRepo string,
+	tag string,
+	fileChanges []FileChange,
+	fileStats *FileSyncStats,
+	smyklotChanges []FileChange,
+	smyklotStats *SmyklotSyncStats,
+) string {
+	var body strings.Builder
+
+	body.WriteString("Bundles organization sync changes into a single PR.\n")
+
+	if len(fileChanges) > 0 {
+		body.WriteString("\n## Organization Files\n\n")
+		writeFilesPRSections(&body, org, sourceRepo, fileStats, "###")
+	}
+
+	if len(smyklotChanges) > 0 {
+		body.WriteString("\n## Smyklot\n\n")
+		writeSmyklotPRSections(&body, tag, smyklotStats, "###")
+	}
+
+	body.WriteString("\n---\n\n")
+	body.WriteString("*This PR was automatically created by the org sync workflow (bundle mode)*\n")
+
+	return body.String()
+}
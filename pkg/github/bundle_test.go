@@ -0,0 +1,138 @@
+package github
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestBuildBundleCommitGroups(t *testing.T) {
+	t.Parallel()
+
+	fileChanges := []FileChange{
+		{Path: "renovate.json", Action: "update"},
+		{Path: ".github/workflows/smyklot-poll.yml", Action: "update"},
+	}
+	smyklotChanges := []FileChange{
+		{Path: ".github/workflows/smyklot-poll.yml", Action: "update"},
+		{Path: ".github/workflows/smyklot-pr-commands.yml", Action: "create"},
+	}
+
+	tests := []struct {
+		name           string
+		fileChanges    []FileChange
+		smyklotChanges []FileChange
+		perFileCommits bool
+		wantMessages   []string
+	}{
+		{
+			name:           "files and smyklot",
+			fileChanges:    fileChanges,
+			smyklotChanges: smyklotChanges,
+			wantMessages: []string{
+				"chore(sync): sync organization files",
+				"chore(sync): sync smyklot workflows",
+			},
+		},
+		{
+			name:           "per-file commits",
+			fileChanges:    fileChanges,
+			smyklotChanges: smyklotChanges,
+			perFileCommits: true,
+			wantMessages: []string{
+				"chore(sync): update renovate.json",
+				"chore(sync): update .github/workflows/smyklot-poll.yml",
+				"chore(sync): add .github/workflows/smyklot-pr-commands.yml",
+			},
+		},
+		{
+			name:           "smyklot only",
+			smyklotChanges: smyklotChanges,
+			wantMessages:   []string{"chore(sync): sync smyklot workflows"},
+		},
+		{
+			name:         "files only",
+			fileChanges:  fileChanges,
+			wantMessages: []string{"chore(sync): sync organization files"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			deduped := dropOverlappingChanges(logger.New("error"), tt.fileChanges, tt.smyklotChanges)
+			groups := buildBundleCommitGroups(deduped, tt.smyklotChanges, tt.perFileCommits)
+
+			messages := make([]string, 0, len(groups))
+			paths := make(map[string]int)
+
+			for _, group := range groups {
+				messages = append(messages, group.Message)
+
+				for _, change := range group.Changes {
+					paths[change.Path]++
+				}
+			}
+
+			if !slices.Equal(messages, tt.wantMessages) {
+				t.Errorf("commit messages = %q, want %q", messages, tt.wantMessages)
+			}
+
+			for path, count := range paths {
+				if count > 1 {
+					t.Errorf("path %s is committed %d times", path, count)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildBundlePRBody(t *testing.T) {
+	t.Parallel()
+
+	fileChanges := []FileChange{{Path: "renovate.json", Action: "create"}}
+	fileStats := &FileSyncStats{
+		CreatedFiles: []string{"renovate.json"},
+		MergedFiles:  map[string]string{},
+	}
+	smyklotChanges := []FileChange{{Path: ".github/workflows/smyklot-poll.yml", Action: "create"}}
+	smyklotStats := &SmyklotSyncStats{
+		Installed:      1,
+		InstalledFiles: []string{".github/workflows/smyklot-poll.yml"},
+	}
+
+	body := buildBundlePRBody(
+		"smykla-labs", ".github", "v1.9.2",
+		fileChanges, fileStats, smyklotChanges, smyklotStats,
+	)
+
+	for _, want := range []string{
+		"## Organization Files",
+		"### Files Created",
+		"- `renovate.json`",
+		"## Smyklot",
+		"### Workflows Installed",
+		"- `.github/workflows/smyklot-poll.yml`",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	if strings.Count(body, "\n---\n") != 1 {
+		t.Errorf("body should have a single footer:\n%s", body)
+	}
+
+	title := buildBundlePRTitle("v1.9.2", fileChanges, smyklotChanges, smyklotStats)
+	if title != bundleCommitTitle {
+		t.Errorf("title = %q, want %q", title, bundleCommitTitle)
+	}
+
+	labels := bundlePRLabels([]string{"ci/skip-all"}, fileStats, true)
+	if !slices.Equal(labels, []string{"ci/skip-all"}) {
+		t.Errorf("labels = %q, want smyklot label only once", labels)
+	}
+}
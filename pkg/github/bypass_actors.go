@@ -0,0 +1,309 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+)
+
+// Ruleset bypass actor types.
+const (
+	bypassActorTypeIntegration    = "Integration"
+	bypassActorTypeOrgAdmin       = "OrganizationAdmin"
+	bypassActorTypeRepositoryRole = "RepositoryRole"
+	bypassActorTypeTeam           = "Team"
+)
+
+// orgAdminActorID is the actor ID GitHub uses for the organization admin role.
+const orgAdminActorID = 1
+
+// builtinRepositoryRoleIDs maps built-in repository roles that can bypass rulesets
+// to their actor IDs.
+var builtinRepositoryRoleIDs = map[string]int64{
+	"maintain": 2,
+	"write":    4,
+	"admin":    5,
+}
+
+var (
+	// ErrUnresolvedBypassActor is returned when a named bypass actor does not exist.
+	ErrUnresolvedBypassActor = errors.New("unresolved bypass actor")
+	// ErrAmbiguousBypassActor is returned when a bypass actor does not set exactly one
+	// of team, app, role, org_admin or actor_id.
+	ErrAmbiguousBypassActor = errors.New(
+		"bypass actor must set exactly one of team, app, role, org_admin or actor_id")
+)
+
+// actorCache caches actor ID lookups for the lifetime of a client. Unknown actors
+// are cached as 0 (or an empty node ID) so they are looked up only once.
+type actorCache struct {
+	mu          sync.Mutex
+	ids         map[string]int64
+	nodeIDs     map[string]string
+	customRoles map[string]map[string]int64
+}
+
+// resolveBypassActors returns the actors with team, app, role and org_admin entries
+// resolved to actor IDs. Actors setting more or less than one of these or actor_id
+// are rejected, and every actor that cannot be resolved is named in the error. Unset
+// actors stay nil, so they are not confused with an explicit empty list.
+func resolveBypassActors(
+	ctx context.Context,
+	client *Client,
+	org string,
+	actors []configtypes.BypassActorConfig,
+) ([]configtypes.BypassActorConfig, error) {
+	if actors == nil {
+		return nil, nil
+	}
+
+	resolved := make([]configtypes.BypassActorConfig, 0, len(actors))
+
+	var unresolved []string
+
+	for i, actor := range actors {
+		if err := checkBypassActor(actor); err != nil {
+			return nil, errors.Wrapf(err, "bypass actor %d", i+1)
+		}
+
+		var (
+			id   int64
+			name string
+			err  error
+		)
+
+		switch {
+		case actor.OrgAdmin != nil:
+			actor.ActorType = bypassActorTypeOrgAdmin
+			id = orgAdminActorID
+		case actor.Team != "":
+			actor.ActorType = bypassActorTypeTeam
+			name = fmt.Sprintf("team %q", actor.Team)
+			id, err = client.resolveTeamID(ctx, org, actor.Team)
+		case actor.App != "":
+			actor.ActorType = bypassActorTypeIntegration
+			name = fmt.Sprintf("app %q", actor.App)
+			id, err = client.resolveAppID(ctx, actor.App)
+		case actor.Role != "":
+			actor.ActorType = bypassActorTypeRepositoryRole
+			name = fmt.Sprintf("role %q", actor.Role)
+			id, err = client.resolveRoleID(ctx, org, actor.Role)
+		default:
+			resolved = append(resolved, actor)
+
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving bypass actor %s", name)
+		}
+
+		if id == 0 {
+			unresolved = append(unresolved, name)
+
+			continue
+		}
+
+		actor.ActorID = id
+		resolved = append(resolved, actor)
+	}
+
+	if len(unresolved) > 0 {
+		return nil, errors.Wrapf(ErrUnresolvedBypassActor, "%s", strings.Join(unresolved, ", "))
+	}
+
+	return resolved, nil
+}
+
+// checkBypassActor verifies that an actor names exactly one of a team, an app, a
+// role, the organization admins or an actor ID. org_admin is only accepted as true.
+func checkBypassActor(actor configtypes.BypassActorConfig) error {
+	var set []string
+
+	if actor.Team != "" {
+		set = append(set, "team")
+	}
+
+	if actor.App != "" {
+		set = append(set, "app")
+	}
+
+	if actor.Role != "" {
+		set = append(set, "role")
+	}
+
+	if actor.OrgAdmin != nil {
+		set = append(set, "org_admin")
+	}
+
+	if actor.ActorID != 0 || actor.ActorType != "" {
+		set = append(set, "actor_id")
+	}
+
+	if len(set) != 1 {
+		return errors.Wrapf(ErrAmbiguousBypassActor, "got %q", set)
+	}
+
+	if actor.OrgAdmin != nil && !*actor.OrgAdmin {
+		return errors.Wrap(ErrAmbiguousBypassActor, "org_admin: false names no actor")
+	}
+
+	return nil
+}
+
+// checkPullRequestAllowances verifies that the teams and apps allowed to bypass
+// pull request reviews exist, naming every one that does not.
+func checkPullRequestAllowances(
+	ctx context.Context,
+	client *Client,
+	org string,
+	allowances *configtypes.BypassPullRequestAllowances,
+) error {
+	if allowances == nil {
+		return nil
+	}
+
+	var unresolved []string
+
+	for _, team := range allowances.Teams {
+		id, err := client.resolveTeamID(ctx, org, team)
+		if err != nil {
+			return errors.Wrapf(err, "resolving team %q", team)
+		}
+
+		if id == 0 {
+			unresolved = append(unresolved, fmt.Sprintf("team %q", team))
+		}
+	}
+
+	for _, app := range allowances.Apps {
+		id, err := client.resolveAppID(ctx, app)
+		if err != nil {
+			return errors.Wrapf(err, "resolving app %q", app)
+		}
+
+		if id == 0 {
+			unresolved = append(unresolved, fmt.Sprintf("app %q", app))
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return errors.Wrapf(ErrUnresolvedBypassActor, "%s", strings.Join(unresolved, ", "))
+	}
+
+	return nil
+}
+
+// resolveTeamID returns the ID of a team in the organization, or 0 when it does
+// not exist.
+func (c *Client) resolveTeamID(ctx context.Context, org string, slug string) (int64, error) {
+	return c.cachedActorID("team:"+org+"/"+slug, func() (int64, error) {
+		team, _, err := c.Teams.GetTeamBySlug(ctx, org, slug)
+		if err != nil {
+			if isNotFoundError(err) {
+				return 0, nil
+			}
+
+			return 0, errors.Wrap(err, "getting team")
+		}
+
+		return team.GetID(), nil
+	})
+}
+
+// resolveAppID returns the ID of a GitHub App, or 0 when it does not exist.
+func (c *Client) resolveAppID(ctx context.Context, slug string) (int64, error) {
+	return c.cachedActorID("app:"+slug, func() (int64, error) {
+		app, _, err := c.Apps.Get(ctx, slug)
+		if err != nil {
+			if isNotFoundError(err) {
+				return 0, nil
+			}
+
+			return 0, errors.Wrap(err, "getting app")
+		}
+
+		return app.GetID(), nil
+	})
+}
+
+// resolveUserID returns the ID of a user, or 0 when the user does not exist.
+func (c *Client) resolveUserID(ctx context.Context, login string) (int64, error) {
+	return c.cachedActorID("user:"+login, func() (int64, error) {
+		user, _, err := c.Users.Get(ctx, login)
+		if err != nil {
+			if isNotFoundError(err) {
+				return 0, nil
+			}
+
+			return 0, errors.Wrap(err, "getting user")
+		}
+
+		return user.GetID(), nil
+	})
+}
+
+// resolveRoleID returns the ID of a built-in or custom repository role, or 0 when
+// the organization has no such role.
+func (c *Client) resolveRoleID(ctx context.Context, org string, name string) (int64, error) {
+	name = strings.ToLower(name)
+
+	if id, ok := builtinRepositoryRoleIDs[name]; ok {
+		return id, nil
+	}
+
+	c.actors.mu.Lock()
+	defer c.actors.mu.Unlock()
+
+	roles, ok := c.actors.customRoles[org]
+	if !ok {
+		list, _, err := c.Organizations.ListCustomRepoRoles(ctx, org)
+		if err != nil && !isNotFoundError(err) {
+			return 0, errors.Wrap(err, "listing custom repository roles")
+		}
+
+		roles = make(map[string]int64)
+
+		if list != nil {
+			for _, role := range list.CustomRepoRoles {
+				roles[strings.ToLower(role.GetName())] = role.GetID()
+			}
+		}
+
+		if c.actors.customRoles == nil {
+			c.actors.customRoles = make(map[string]map[string]int64)
+		}
+
+		c.actors.customRoles[org] = roles
+	}
+
+	return roles[name], nil
+}
+
+// cachedActorID returns the cached ID for key, calling lookup on a cache miss.
+func (c *Client) cachedActorID(key string, lookup func() (int64, error)) (int64, error) {
+	c.actors.mu.Lock()
+	defer c.actors.mu.Unlock()
+
+	if id, ok := c.actors.ids[key]; ok {
+		return id, nil
+	}
+
+	id, err := lookup()
+	if err != nil {
+		return 0, err
+	}
+
+	if c.actors.ids == nil {
+		c.actors.ids = make(map[string]int64)
+	}
+
+	c.actors.ids[key] = id
+
+	return id, nil
+}
@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+)
+
+func TestResolveBypassActors(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /orgs/org/teams/maintainers": `{"id":42,"slug":"maintainers"}`,
+		"GET /apps/renovate":              `{"id":2740,"slug":"renovate"}`,
+		"GET /orgs/org/custom-repository-roles": `{"total_count":1,` +
+			`"custom_roles":[{"id":7,"name":"Release Manager"}]}`,
+	})
+
+	actors := []configtypes.BypassActorConfig{
+		{Team: "maintainers", BypassMode: "always"},
+		{App: "renovate", BypassMode: "pull_request"},
+		{Role: "admin", BypassMode: "always"},
+		{Role: "release manager", BypassMode: "always"},
+		{OrgAdmin: github.Ptr(true), BypassMode: "always"},
+		{ActorID: 9, ActorType: "Team", BypassMode: "always"},
+		{Team: "maintainers", BypassMode: "pull_request"},
+	}
+
+	got, err := resolveBypassActors(context.Background(), client, "org", actors)
+	if err != nil {
+		t.Fatalf("resolveBypassActors() unexpected error: %v", err)
+	}
+
+	want := []configtypes.BypassActorConfig{
+		{ActorID: 42, ActorType: "Team", Team: "maintainers", BypassMode: "always"},
+		{ActorID: 2740, ActorType: "Integration", App: "renovate", BypassMode: "pull_request"},
+		{ActorID: 5, ActorType: "RepositoryRole", Role: "admin", BypassMode: "always"},
+		{ActorID: 7, ActorType: "RepositoryRole", Role: "release manager", BypassMode: "always"},
+		{
+			ActorID: 1, ActorType: "OrganizationAdmin", OrgAdmin: github.Ptr(true),
+			BypassMode: "always",
+		},
+		{ActorID: 9, ActorType: "Team", BypassMode: "always"},
+		{ActorID: 42, ActorType: "Team", Team: "maintainers", BypassMode: "pull_request"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("resolveBypassActors() mismatch (-want +got):\n%s", diff)
+	}
+
+	teamLookups := 0
+
+	for _, request := range fake.requests {
+		if request == "GET /orgs/org/teams/maintainers" {
+			teamLookups++
+		}
+	}
+
+	if teamLookups != 1 {
+		t.Errorf("team looked up %d times, want 1", teamLookups)
+	}
+}
+
+func TestResolveBypassActorsAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		actor configtypes.BypassActorConfig
+	}{
+		{
+			name: "team and actor ID",
+			actor: configtypes.BypassActorConfig{
+				Team: "maintainers", ActorID: 9, ActorType: "Team",
+			},
+		},
+		{
+			name: "org admin false and actor ID",
+			actor: configtypes.BypassActorConfig{
+				OrgAdmin: github.Ptr(false), ActorID: 9, ActorType: "Team",
+			},
+		},
+		{
+			name:  "app and role",
+			actor: configtypes.BypassActorConfig{App: "renovate", Role: "admin"},
+		},
+		{
+			name:  "org admin false",
+			actor: configtypes.BypassActorConfig{OrgAdmin: github.Ptr(false)},
+		},
+		{
+			name:  "no actor",
+			actor: configtypes.BypassActorConfig{BypassMode: "always"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{})
+
+			actors := []configtypes.BypassActorConfig{tt.actor}
+
+			_, err := resolveBypassActors(context.Background(), client, "org", actors)
+			if !errors.Is(err, ErrAmbiguousBypassActor) {
+				t.Fatalf("resolveBypassActors() error = %v, want ErrAmbiguousBypassActor", err)
+			}
+
+			if len(fake.requests) > 0 {
+				t.Errorf("resolveBypassActors() looked up an ambiguous actor: %v", fake.requests)
+			}
+		})
+	}
+}
+
+func TestResolveBypassActorsUnresolved(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, map[string]string{
+		"GET /orgs/org/teams/maintainers": `{"id":42,"slug":"maintainers"}`,
+	})
+
+	actors := []configtypes.BypassActorConfig{
+		{Team: "maintainers", BypassMode: "always"},
+		{Team: "ghosts", BypassMode: "always"},
+		{App: "missing-app", BypassMode: "always"},
+		{Role: "superuser", BypassMode: "always"},
+	}
+
+	_, err := resolveBypassActors(context.Background(), client, "org", actors)
+	if !errors.Is(err, ErrUnresolvedBypassActor) {
+		t.Fatalf("resolveBypassActors() error = %v, want ErrUnresolvedBypassActor", err)
+	}
+
+	for _, name := range []string{`team "ghosts"`, `app "missing-app"`, `role "superuser"`} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("resolveBypassActors() error %q does not name %s", err, name)
+		}
+	}
+
+	if strings.Contains(err.Error(), "maintainers") {
+		t.Errorf("resolveBypassActors() error %q names a resolved actor", err)
+	}
+}
@@ -14,7 +14,8 @@ import (
 // Client wraps the GitHub API client with additional functionality.
 type Client struct {
 	*github.Client
-	log *logger.Logger
+	log    *logger.Logger
+	actors actorCache
 }
 
 // NewClient creates a new GitHub API client with rate limiting.
@@ -0,0 +1,294 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// ErrInvalidCustomProperty indicates a configured custom property value that does not
+// match the organization's property definition.
+var ErrInvalidCustomProperty = errors.New("invalid custom property value")
+
+// errEmptyPropertyValue indicates a configured value whose repository placeholders
+// rendered to an empty string, e.g. "{{LANGUAGE}}" on a repository without a language.
+var errEmptyPropertyValue = errors.New("custom property value rendered empty")
+
+// Custom property value types defined by GitHub.
+const (
+	customPropertySingleSelect = "single_select"
+	customPropertyMultiSelect  = "multi_select"
+	customPropertyTrueFalse    = "true_false"
+)
+
+// SyncCustomProperties synchronizes custom property values of a repository. Values
+// configured in sync-config.yml take precedence over the defaults of the settings
+// file, and a null value clears the property. String values are rendered against
+// repository, so "{{LANGUAGE}}" derives a value from its primary language. Properties
+// whose value renders empty are skipped with a warning. Every value is validated
+// against the organization's property definitions before any change is made, and
+// properties that are not configured are left untouched.
+func SyncCustomProperties(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	defaults map[string]any,
+	overrides map[string]any,
+	repository *github.Repository,
+	exclude []string,
+	dryRun bool,
+) ([]FieldDiff, error) {
+	if isSettingExcluded("custom_properties", exclude) {
+		log.Debug("custom properties sync excluded by config")
+
+		return nil, nil
+	}
+
+	configured := make(map[string]any, len(defaults)+len(overrides))
+	maps.Copy(configured, defaults)
+	maps.Copy(configured, overrides)
+
+	if len(configured) == 0 {
+		log.Debug("no custom properties configured")
+
+		return nil, nil
+	}
+
+	diffs, values, err := planCustomProperties(
+		ctx, log, client, org, repo, configured, repository, exclude,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, diff := range diffs {
+		log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+	}
+
+	if dryRun {
+		log.Info("dry-run mode: skipping custom property changes", "count", len(diffs))
+
+		return diffs, nil
+	}
+
+	if len(values) == 0 {
+		return diffs, nil
+	}
+
+	if _, err := client.Repositories.CreateOrUpdateCustomProperties(
+		ctx, org, repo, values,
+	); err != nil {
+		return diffs, errors.Wrap(err, "updating custom property values")
+	}
+
+	log.Info("custom properties synced successfully", "count", len(values))
+
+	return diffs, nil
+}
+
+// planCustomProperties validates configured values against the organization's property
+// definitions and returns the diffs and values of properties that need updating.
+func planCustomProperties(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	configured map[string]any,
+	repository *github.Repository,
+	exclude []string,
+) ([]FieldDiff, []*github.CustomPropertyValue, error) {
+	definitions, _, err := client.Organizations.GetAllCustomProperties(ctx, org)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting custom property definitions")
+	}
+
+	byName := make(map[string]*github.CustomProperty, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.GetPropertyName()] = definition
+	}
+
+	desired := make(map[string]any, len(configured))
+
+	for _, name := range slices.Sorted(maps.Keys(configured)) {
+		if isSettingExcluded("custom_properties."+name, exclude) {
+			continue
+		}
+
+		definition, ok := byName[name]
+		if !ok {
+			return nil, nil, errors.Wrapf(ErrInvalidCustomProperty,
+				"property %q is not defined in organization %s", name, org)
+		}
+
+		value, err := normalizeCustomPropertyValue(definition, configured[name], repository)
+		if errors.Is(err, errEmptyPropertyValue) {
+			log.Warn("skipping custom property whose value rendered empty",
+				"property", name,
+				"value", configured[name],
+			)
+
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		desired[name] = value
+	}
+
+	currentValues, _, err := client.Repositories.GetAllCustomPropertyValues(ctx, org, repo)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "getting custom property values")
+	}
+
+	current := make(map[string]any, len(currentValues))
+	for _, value := range currentValues {
+		current[value.PropertyName] = normalizeCurrentPropertyValue(value.Value)
+	}
+
+	var (
+		diffs  []FieldDiff
+		values []*github.CustomPropertyValue
+	)
+
+	for _, name := range slices.Sorted(maps.Keys(desired)) {
+		if reflect.DeepEqual(current[name], desired[name]) {
+			continue
+		}
+
+		diffs = append(diffs, FieldDiff{
+			Field:   "custom_properties." + name,
+			Current: current[name],
+			Desired: desired[name],
+		})
+		values = append(values, &github.CustomPropertyValue{
+			PropertyName: name,
+			Value:        desired[name],
+		})
+	}
+
+	return diffs, values, nil
+}
+
+// normalizeCustomPropertyValue converts a configured value to the representation the
+// API uses for the property's value type: a string, a sorted list of strings for
+// multi_select properties, or nil to clear an optional property. Select values are
+// matched case-insensitively and replaced by the allowed value's spelling.
+func normalizeCustomPropertyValue(
+	definition *github.CustomProperty,
+	value any,
+	repository *github.Repository,
+) (any, error) {
+	name := definition.GetPropertyName()
+
+	if value == nil {
+		if definition.GetRequired() {
+			return nil, errors.Wrapf(ErrInvalidCustomProperty,
+				"property %q is required and cannot be cleared", name)
+		}
+
+		return nil, nil //nolint:nilnil // nil clears the property
+	}
+
+	if definition.ValueType == customPropertyMultiSelect {
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+
+		selected := make([]string, 0, len(items))
+
+		for _, item := range items {
+			rendered, err := renderPropertyValue(item, repository)
+			if err != nil {
+				return nil, errors.Wrapf(err, "property %q", name)
+			}
+
+			allowed, err := allowedPropertyValue(definition, rendered)
+			if err != nil {
+				return nil, err
+			}
+
+			selected = append(selected, allowed)
+		}
+
+		return sortedSet(selected), nil
+	}
+
+	if _, ok := value.([]any); ok {
+		return nil, errors.Wrapf(ErrInvalidCustomProperty,
+			"property %q of type %s takes a single value", name, definition.ValueType)
+	}
+
+	rendered, err := renderPropertyValue(value, repository)
+	if err != nil {
+		return nil, errors.Wrapf(err, "property %q", name)
+	}
+
+	switch definition.ValueType {
+	case customPropertySingleSelect:
+		return allowedPropertyValue(definition, rendered)
+	case customPropertyTrueFalse:
+		parsed, err := strconv.ParseBool(rendered)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidCustomProperty,
+				"property %q takes true or false, got %q", name, rendered)
+		}
+
+		return strconv.FormatBool(parsed), nil
+	default:
+		// String and newer value types such as url are validated by the API
+		return rendered, nil
+	}
+}
+
+// renderPropertyValue formats a configured scalar as a string and renders repository
+// placeholders in it. A value the placeholders leave empty is reported as
+// errEmptyPropertyValue.
+func renderPropertyValue(value any, repository *github.Repository) (string, error) {
+	formatted := fmt.Sprint(value)
+
+	rendered := *renderRepositoryTemplate(&formatted, repository)
+	if rendered == "" && formatted != "" {
+		return "", errors.Wrapf(errEmptyPropertyValue, "%q", formatted)
+	}
+
+	return rendered, nil
+}
+
+// allowedPropertyValue returns the allowed value of a select property matching value
+// case-insensitively.
+func allowedPropertyValue(definition *github.CustomProperty, value string) (string, error) {
+	for _, allowed := range definition.AllowedValues {
+		if strings.EqualFold(allowed, value) {
+			return allowed, nil
+		}
+	}
+
+	return "", errors.Wrapf(ErrInvalidCustomProperty,
+		"property %q does not allow %q (allowed: %s)", definition.GetPropertyName(), value,
+		strings.Join(definition.AllowedValues, ", "))
+}
+
+// normalizeCurrentPropertyValue sorts multi_select values so they compare equal to
+// configured values regardless of order.
+func normalizeCurrentPropertyValue(value any) any {
+	if values, ok := value.([]string); ok {
+		return sortedSet(values)
+	}
+
+	return value
+}
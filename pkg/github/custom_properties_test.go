@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const customPropertyDefinitions = `[
+	{"property_name":"team","value_type":"string"},
+	{"property_name":"tier","value_type":"single_select","required":true,
+		"allowed_values":["tier-1","tier-2","tier-3"]},
+	{"property_name":"language","value_type":"single_select","allowed_values":["Go","Rust"]},
+	{"property_name":"platforms","value_type":"multi_select",
+		"allowed_values":["linux","darwin","windows"]},
+	{"property_name":"archived","value_type":"true_false"}]`
+
+func TestSyncCustomProperties(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /orgs/org/properties/schema": customPropertyDefinitions,
+		"GET /repos/org/repo/properties/values": `[
+			{"property_name":"team","value":"platform"},
+			{"property_name":"tier","value":"tier-1"},
+			{"property_name":"platforms","value":["linux","darwin"]},
+			{"property_name":"archived","value":"true"}]`,
+		"PATCH /repos/org/repo/properties/values": ``,
+	})
+
+	defaults := map[string]any{
+		"team":      "platform",
+		"tier":      "tier-3",
+		"language":  "{{LANGUAGE}}",
+		"platforms": []any{"darwin", "linux"},
+		"archived":  true,
+	}
+	overrides := map[string]any{
+		"tier":     "TIER-2",
+		"archived": nil,
+	}
+
+	diffs, err := SyncCustomProperties(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		defaults, overrides, &github.Repository{Language: github.Ptr("go")}, nil, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncCustomProperties() error = %v", err)
+	}
+
+	want := []FieldDiff{
+		{Field: "custom_properties.archived", Current: "true", Desired: nil},
+		{Field: "custom_properties.language", Current: nil, Desired: "Go"},
+		{Field: "custom_properties.tier", Current: "tier-1", Desired: "tier-2"},
+	}
+
+	if diff := cmp.Diff(want, diffs); diff != "" {
+		t.Errorf("diffs mismatch (-want +got):\n%s", diff)
+	}
+
+	var body struct {
+		Properties []*github.CustomPropertyValue `json:"properties"`
+	}
+
+	request := fake.body("PATCH /repos/org/repo/properties/values")
+	if err := json.Unmarshal([]byte(request), &body); err != nil {
+		t.Fatalf("decoding request: %v", err)
+	}
+
+	wantValues := []*github.CustomPropertyValue{
+		{PropertyName: "archived"},
+		{PropertyName: "language", Value: "Go"},
+		{PropertyName: "tier", Value: "tier-2"},
+	}
+
+	if diff := cmp.Diff(wantValues, body.Properties); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSyncCustomPropertiesInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		values map[string]any
+	}{
+		{name: "undefined property", values: map[string]any{"owner": "platform"}},
+		{name: "value not allowed", values: map[string]any{"tier": "tier-9"}},
+		{name: "required cleared", values: map[string]any{"tier": nil}},
+		{name: "list for single value", values: map[string]any{"team": []any{"a", "b"}}},
+		{name: "not a boolean", values: map[string]any{"archived": "maybe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /orgs/org/properties/schema": customPropertyDefinitions,
+			})
+
+			_, err := SyncCustomProperties(
+				context.Background(), logger.New("error"), client, "org", "repo",
+				tt.values, nil, &github.Repository{}, nil, false,
+			)
+			if !errors.Is(err, ErrInvalidCustomProperty) {
+				t.Fatalf("SyncCustomProperties() error = %v, want %v",
+					err, ErrInvalidCustomProperty)
+			}
+
+			if fake.called("PATCH /repos/org/repo/properties/values") {
+				t.Error("invalid values must not be applied")
+			}
+		})
+	}
+}
+
+func TestSyncCustomPropertiesSkipsEmptyPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /orgs/org/properties/schema":         customPropertyDefinitions,
+		"GET /repos/org/repo/properties/values":   `[{"property_name":"tier","value":"tier-1"}]`,
+		"PATCH /repos/org/repo/properties/values": ``,
+	})
+
+	values := map[string]any{
+		"tier":      "tier-2",
+		"language":  "{{LANGUAGE}}",
+		"platforms": []any{"linux", "{{LANGUAGE}}"},
+	}
+
+	diffs, err := SyncCustomProperties(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		values, nil, &github.Repository{}, nil, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncCustomProperties() error = %v", err)
+	}
+
+	want := []FieldDiff{
+		{Field: "custom_properties.tier", Current: "tier-1", Desired: "tier-2"},
+	}
+
+	if diff := cmp.Diff(want, diffs); diff != "" {
+		t.Errorf("diffs mismatch (-want +got):\n%s", diff)
+	}
+
+	if !fake.called("PATCH /repos/org/repo/properties/values") {
+		t.Error("properties with values were not applied")
+	}
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"slices"
+)
+
+// appendDiff appends a FieldDiff when current and desired differ.
+func appendDiff[T comparable](diffs []FieldDiff, field string, current T, desired T) []FieldDiff {
+	if current == desired {
+		return diffs
+	}
+
+	return append(diffs, FieldDiff{Field: field, Current: current, Desired: desired})
+}
+
+// appendSetDiff appends a FieldDiff when current and desired contain different
+// values, ignoring order.
+func appendSetDiff(
+	diffs []FieldDiff,
+	field string,
+	current []string,
+	desired []string,
+) []FieldDiff {
+	current = sortedSet(current)
+	desired = sortedSet(desired)
+
+	if slices.Equal(current, desired) {
+		return diffs
+	}
+
+	return append(diffs, FieldDiff{Field: field, Current: current, Desired: desired})
+}
+
+// sortedSet returns a sorted copy of values without duplicates, never nil.
+func sortedSet(values []string) []string {
+	sorted := append([]string{}, values...)
+	slices.Sort(sorted)
+
+	return slices.Compact(sorted)
+}
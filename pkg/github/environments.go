@@ -0,0 +1,651 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// ErrUnresolvedEnvironmentReviewer is returned when a user or team named as a
+// required environment reviewer does not exist.
+var ErrUnresolvedEnvironmentReviewer = errors.New("unresolved environment reviewer")
+
+// Reviewer, protection rule and deployment policy types used by the environments API.
+const (
+	environmentReviewerUser         = "User"
+	environmentReviewerTeam         = "Team"
+	protectionRuleRequiredReviewers = "required_reviewers"
+	protectionRuleWaitTimer         = "wait_timer"
+	deploymentPolicyTypeBranch      = "branch"
+	deploymentPolicyTypeTag         = "tag"
+)
+
+// Deployment branch policy modes compared when planning environments.
+const (
+	deploymentPolicyAll               = "all"
+	deploymentPolicyProtectedBranches = "protected_branches"
+	deploymentPolicyCustom            = "custom"
+)
+
+// environmentsPerPage is the page size used when listing environments.
+const environmentsPerPage = 100
+
+// environmentChange is a planned environment operation. Request is nil when the
+// environment itself is in sync and only its deployment branch policies change.
+type environmentChange struct {
+	EnvironmentOutcome
+
+	Request        *github.CreateUpdateEnvironment
+	AddPolicies    []*github.DeploymentBranchPolicyRequest
+	RemovePolicies []*github.DeploymentBranchPolicy
+}
+
+// environmentState is the part of an environment managed by the settings sync.
+type environmentState struct {
+	Users             []string
+	Teams             []string
+	Reviewers         []*github.EnvReviewers
+	WaitTimer         int
+	PreventSelfReview bool
+	CanAdminsBypass   bool
+	BranchPolicy      *github.BranchPolicy
+}
+
+// SyncEnvironments synchronizes deployment environments from configuration to target
+// repository. Environments matching the existing state are left untouched, and
+// environments that are not configured are deleted when allowRemoval is set.
+func SyncEnvironments(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	environments []configtypes.EnvironmentConfig,
+	exclude []string,
+	allowRemoval bool,
+	dryRun bool,
+) ([]EnvironmentOutcome, error) {
+	if isSettingExcluded("environments", exclude) {
+		log.Debug("environments sync excluded by config")
+
+		return nil, nil
+	}
+
+	if len(environments) == 0 && !allowRemoval {
+		log.Debug("no environments configured")
+
+		return nil, nil
+	}
+
+	changes, err := planEnvironments(
+		ctx, log, client, org, repo, environments, exclude, allowRemoval,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logEnvironmentChanges(log, changes, dryRun)
+
+	if dryRun {
+		log.Info("dry-run mode: skipping environment changes", "count", len(changes))
+
+		return environmentOutcomes(changes), nil
+	}
+
+	if err := applyEnvironmentChanges(ctx, log, client, org, repo, changes); err != nil {
+		return environmentOutcomes(changes), err
+	}
+
+	log.Info("environments synced successfully", "count", len(changes))
+
+	return environmentOutcomes(changes), nil
+}
+
+// planEnvironments compares configured environments with the existing ones and
+// returns the operation needed for each of them.
+func planEnvironments(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	environments []configtypes.EnvironmentConfig,
+	exclude []string,
+	allowRemoval bool,
+) ([]environmentChange, error) {
+	existing, err := listEnvironments(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching existing environments")
+	}
+
+	log.Debug("fetched existing environments", "count", len(existing))
+
+	existingByName := make(map[string]*github.Environment, len(existing))
+	for _, env := range existing {
+		existingByName[env.GetName()] = env
+	}
+
+	changes := make([]environmentChange, 0, len(environments))
+	configured := make(map[string]bool, len(environments))
+
+	for i := range environments {
+		envConfig := &environments[i]
+		configured[envConfig.Name] = true
+
+		if isSettingExcluded("environments."+envConfig.Name, exclude) {
+			log.Debug("environment excluded by config", "name", envConfig.Name)
+
+			continue
+		}
+
+		change, err := planEnvironment(
+			ctx, client, org, repo, envConfig, existingByName[envConfig.Name],
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "planning environment %q", envConfig.Name)
+		}
+
+		changes = append(changes, change)
+	}
+
+	if !allowRemoval {
+		return changes, nil
+	}
+
+	for _, env := range existing {
+		name := env.GetName()
+		if configured[name] || isSettingExcluded("environments."+name, exclude) {
+			continue
+		}
+
+		changes = append(changes, environmentChange{
+			EnvironmentOutcome: EnvironmentOutcome{
+				Name:   name,
+				Action: ResourceActionDeleted,
+			},
+		})
+	}
+
+	return changes, nil
+}
+
+// listEnvironments returns all environments of a repository.
+func listEnvironments(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) ([]*github.Environment, error) {
+	opts := &github.EnvironmentListOptions{
+		ListOptions: github.ListOptions{PerPage: environmentsPerPage},
+	}
+
+	var environments []*github.Environment
+
+	for {
+		page, resp, err := client.Repositories.ListEnvironments(ctx, org, repo, opts)
+		if err != nil {
+			// Repositories without environments return 404
+			if isNotFoundError(err) {
+				return nil, nil
+			}
+
+			return nil, errors.Wrap(err, "listing environments")
+		}
+
+		environments = append(environments, page.Environments...)
+
+		if resp.NextPage == 0 {
+			return environments, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// planEnvironment compares a configured environment with the existing one of the
+// same name, which is nil when the environment does not exist yet. Fields the
+// config leaves unset keep their current values.
+func planEnvironment(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.EnvironmentConfig,
+	existing *github.Environment,
+) (environmentChange, error) {
+	current := currentEnvironmentState(existing)
+
+	var fields []FieldDiff
+
+	if desired.Reviewers != nil {
+		fields = appendSetDiff(fields, "reviewers.users", current.Users, desired.Reviewers.Users)
+		fields = appendSetDiff(fields, "reviewers.teams", current.Teams, desired.Reviewers.Teams)
+	}
+
+	if desired.WaitTimer != nil {
+		fields = appendDiff(fields, "wait_timer", current.WaitTimer, *desired.WaitTimer)
+	}
+
+	if desired.PreventSelfReview != nil {
+		fields = appendDiff(fields, "prevent_self_review",
+			current.PreventSelfReview, *desired.PreventSelfReview)
+	}
+
+	if desired.CanAdminsBypass != nil {
+		fields = appendDiff(fields, "can_admins_bypass",
+			current.CanAdminsBypass, *desired.CanAdminsBypass)
+	}
+
+	desiredPolicy := current.BranchPolicy
+	if desired.DeploymentBranchPolicy != nil {
+		desiredPolicy = buildBranchPolicy(desired.DeploymentBranchPolicy)
+		fields = appendDiff(fields, "deployment_branch_policy",
+			branchPolicyMode(current.BranchPolicy), branchPolicyMode(desiredPolicy))
+	}
+
+	change := environmentChange{
+		EnvironmentOutcome: EnvironmentOutcome{
+			Name:   desired.Name,
+			Action: ResourceActionUnchanged,
+		},
+	}
+
+	if existing == nil || len(fields) > 0 {
+		request, err := buildEnvironmentRequest(ctx, client, org, desired, current, desiredPolicy)
+		if err != nil {
+			return environmentChange{}, err
+		}
+
+		change.Request = request
+	}
+
+	if branchPolicyMode(desiredPolicy) == deploymentPolicyCustom &&
+		desired.DeploymentBranchPolicy != nil {
+		policyFields, err := planDeploymentBranchPolicies(
+			ctx, client, org, repo, desired, existing, &change,
+		)
+		if err != nil {
+			return environmentChange{}, err
+		}
+
+		fields = append(fields, policyFields...)
+	}
+
+	switch {
+	case existing == nil:
+		change.Action = ResourceActionCreated
+	case len(fields) > 0:
+		change.Action = ResourceActionUpdated
+		change.Fields = fields
+	}
+
+	return change, nil
+}
+
+// currentEnvironmentState extracts the managed settings of an environment. A nil
+// environment yields the defaults of a newly created one.
+func currentEnvironmentState(env *github.Environment) environmentState {
+	state := environmentState{CanAdminsBypass: true}
+
+	if env == nil {
+		return state
+	}
+
+	state.CanAdminsBypass = env.GetCanAdminsBypass()
+	state.BranchPolicy = env.DeploymentBranchPolicy
+
+	for _, rule := range env.ProtectionRules {
+		switch rule.GetType() {
+		case protectionRuleWaitTimer:
+			state.WaitTimer = rule.GetWaitTimer()
+		case protectionRuleRequiredReviewers:
+			state.PreventSelfReview = rule.GetPreventSelfReview()
+
+			for _, reviewer := range rule.Reviewers {
+				switch actor := reviewer.Reviewer.(type) {
+				case *github.User:
+					state.Users = append(state.Users, actor.GetLogin())
+					state.Reviewers = append(state.Reviewers, &github.EnvReviewers{
+						Type: github.Ptr(environmentReviewerUser),
+						ID:   actor.ID,
+					})
+				case *github.Team:
+					state.Teams = append(state.Teams, actor.GetSlug())
+					state.Reviewers = append(state.Reviewers, &github.EnvReviewers{
+						Type: github.Ptr(environmentReviewerTeam),
+						ID:   actor.ID,
+					})
+				}
+			}
+		}
+	}
+
+	return state
+}
+
+// buildEnvironmentRequest builds the create or update request of an environment.
+// The API replaces the whole environment, so unset fields are sent with their
+// current values.
+func buildEnvironmentRequest(
+	ctx context.Context,
+	client *Client,
+	org string,
+	desired *configtypes.EnvironmentConfig,
+	current environmentState,
+	policy *github.BranchPolicy,
+) (*github.CreateUpdateEnvironment, error) {
+	request := &github.CreateUpdateEnvironment{
+		WaitTimer:              github.Ptr(current.WaitTimer),
+		Reviewers:              current.Reviewers,
+		CanAdminsBypass:        github.Ptr(current.CanAdminsBypass),
+		DeploymentBranchPolicy: policy,
+		PreventSelfReview:      github.Ptr(current.PreventSelfReview),
+	}
+
+	if desired.Reviewers != nil {
+		reviewers, err := resolveEnvironmentReviewers(ctx, client, org, desired.Reviewers)
+		if err != nil {
+			return nil, err
+		}
+
+		request.Reviewers = reviewers
+	}
+
+	if desired.WaitTimer != nil {
+		request.WaitTimer = desired.WaitTimer
+	}
+
+	if desired.PreventSelfReview != nil {
+		request.PreventSelfReview = desired.PreventSelfReview
+	}
+
+	if desired.CanAdminsBypass != nil {
+		request.CanAdminsBypass = desired.CanAdminsBypass
+	}
+
+	return request, nil
+}
+
+// resolveEnvironmentReviewers resolves reviewer logins and team slugs to their IDs.
+func resolveEnvironmentReviewers(
+	ctx context.Context,
+	client *Client,
+	org string,
+	reviewers *configtypes.EnvironmentReviewersConfig,
+) ([]*github.EnvReviewers, error) {
+	resolved := make([]*github.EnvReviewers, 0, len(reviewers.Users)+len(reviewers.Teams))
+
+	var unresolved []string
+
+	resolve := func(kind string, name string, lookup func() (int64, error)) error {
+		id, err := lookup()
+		if err != nil {
+			return errors.Wrapf(err, "resolving %s %q", strings.ToLower(kind), name)
+		}
+
+		if id == 0 {
+			unresolved = append(unresolved, strings.ToLower(kind)+" "+strconv.Quote(name))
+
+			return nil
+		}
+
+		resolved = append(resolved, &github.EnvReviewers{Type: github.Ptr(kind), ID: &id})
+
+		return nil
+	}
+
+	for _, login := range reviewers.Users {
+		err := resolve(environmentReviewerUser, login, func() (int64, error) {
+			return client.resolveUserID(ctx, login)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, slug := range reviewers.Teams {
+		err := resolve(environmentReviewerTeam, slug, func() (int64, error) {
+			return client.resolveTeamID(ctx, org, slug)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return nil, errors.Wrapf(
+			ErrUnresolvedEnvironmentReviewer, "%s", strings.Join(unresolved, ", "),
+		)
+	}
+
+	return resolved, nil
+}
+
+// buildBranchPolicy converts a deployment branch policy config to the policy sent
+// to the API. A nil policy allows all branches to deploy.
+func buildBranchPolicy(policy *configtypes.DeploymentBranchPolicyConfig) *github.BranchPolicy {
+	switch {
+	case policy.ProtectedBranches:
+		return &github.BranchPolicy{
+			ProtectedBranches:    github.Ptr(true),
+			CustomBranchPolicies: github.Ptr(false),
+		}
+	case len(policy.Branches) > 0 || len(policy.Tags) > 0:
+		return &github.BranchPolicy{
+			ProtectedBranches:    github.Ptr(false),
+			CustomBranchPolicies: github.Ptr(true),
+		}
+	default:
+		return nil
+	}
+}
+
+// branchPolicyMode returns which branches may deploy under a branch policy.
+func branchPolicyMode(policy *github.BranchPolicy) string {
+	switch {
+	case policy.GetProtectedBranches():
+		return deploymentPolicyProtectedBranches
+	case policy.GetCustomBranchPolicies():
+		return deploymentPolicyCustom
+	default:
+		return deploymentPolicyAll
+	}
+}
+
+// planDeploymentBranchPolicies compares the custom branch and tag patterns of an
+// environment with the configured ones and records the policies to add and remove.
+func planDeploymentBranchPolicies(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.EnvironmentConfig,
+	existing *github.Environment,
+	change *environmentChange,
+) ([]FieldDiff, error) {
+	var current []*github.DeploymentBranchPolicy
+
+	if branchPolicyMode(currentEnvironmentState(existing).BranchPolicy) == deploymentPolicyCustom {
+		resp, _, err := client.Repositories.ListDeploymentBranchPolicies(
+			ctx, org, repo, desired.Name,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing deployment branch policies")
+		}
+
+		current = resp.BranchPolicies
+	}
+
+	wanted := map[string][]string{
+		deploymentPolicyTypeBranch: desired.DeploymentBranchPolicy.Branches,
+		deploymentPolicyTypeTag:    desired.DeploymentBranchPolicy.Tags,
+	}
+
+	fieldNames := map[string]string{
+		deploymentPolicyTypeBranch: "deployment_branch_policy.branches",
+		deploymentPolicyTypeTag:    "deployment_branch_policy.tags",
+	}
+
+	var fields []FieldDiff
+
+	for _, policyType := range []string{deploymentPolicyTypeBranch, deploymentPolicyTypeTag} {
+		var names []string
+
+		present := make(map[string]bool)
+
+		for _, policy := range current {
+			if policyType != policy.GetType() {
+				continue
+			}
+
+			names = append(names, policy.GetName())
+			present[policy.GetName()] = true
+
+			if !slices.Contains(wanted[policyType], policy.GetName()) {
+				change.RemovePolicies = append(change.RemovePolicies, policy)
+			}
+		}
+
+		for _, name := range wanted[policyType] {
+			if present[name] {
+				continue
+			}
+
+			change.AddPolicies = append(change.AddPolicies, &github.DeploymentBranchPolicyRequest{
+				Name: github.Ptr(name),
+				Type: github.Ptr(policyType),
+			})
+		}
+
+		fields = appendSetDiff(fields, fieldNames[policyType], names, wanted[policyType])
+	}
+
+	return fields, nil
+}
+
+// applyEnvironmentChanges creates, updates and deletes environments as planned.
+// Unchanged environments are skipped.
+func applyEnvironmentChanges(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	changes []environmentChange,
+) error {
+	for _, change := range changes {
+		switch change.Action {
+		case ResourceActionCreated, ResourceActionUpdated:
+			if err := applyEnvironmentChange(ctx, client, org, repo, change); err != nil {
+				return errors.Wrapf(err, "syncing environment %q", change.Name)
+			}
+		case ResourceActionDeleted:
+			_, err := client.Repositories.DeleteEnvironment(ctx, org, repo, change.Name)
+			if err != nil {
+				return errors.Wrapf(err, "deleting environment %q", change.Name)
+			}
+		case ResourceActionUnchanged:
+			continue
+		}
+
+		log.Info(string(change.Action)+" environment", "name", change.Name)
+	}
+
+	return nil
+}
+
+// applyEnvironmentChange writes an environment and then its custom deployment
+// branch policies, which can only be added once custom policies are enabled.
+func applyEnvironmentChange(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	change environmentChange,
+) error {
+	if change.Request != nil {
+		_, _, err := client.Repositories.CreateUpdateEnvironment(
+			ctx, org, repo, change.Name, change.Request,
+		)
+		if err != nil {
+			return errors.Wrap(err, "writing environment")
+		}
+	}
+
+	for _, policy := range change.AddPolicies {
+		_, _, err := client.Repositories.CreateDeploymentBranchPolicy(
+			ctx, org, repo, change.Name, policy,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "creating deployment %s policy %q",
+				policy.GetType(), policy.GetName())
+		}
+	}
+
+	for _, policy := range change.RemovePolicies {
+		_, err := client.Repositories.DeleteDeploymentBranchPolicy(
+			ctx, org, repo, change.Name, policy.GetID(),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "deleting deployment %s policy %q",
+				policy.GetType(), policy.GetName())
+		}
+	}
+
+	return nil
+}
+
+// logEnvironmentChanges logs planned environment operations and their field changes.
+func logEnvironmentChanges(log *logger.Logger, changes []environmentChange, dryRun bool) {
+	for _, change := range changes {
+		if change.Action == ResourceActionUnchanged {
+			log.Debug("environment up to date", "name", change.Name)
+
+			continue
+		}
+
+		if dryRun {
+			log.Info("would apply environment change",
+				"name", change.Name,
+				"action", change.Action,
+			)
+		}
+
+		for _, diff := range change.Fields {
+			log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+		}
+	}
+}
+
+// environmentOutcomes returns the exported outcomes of planned changes.
+func environmentOutcomes(changes []environmentChange) []EnvironmentOutcome {
+	outcomes := make([]EnvironmentOutcome, 0, len(changes))
+
+	for _, change := range changes {
+		outcomes = append(outcomes, change.EnvironmentOutcome)
+	}
+
+	return outcomes
+}
+
+// countEnvironmentChanges returns the number of environments created, updated or
+// deleted.
+func countEnvironmentChanges(outcomes []EnvironmentOutcome) int {
+	count := 0
+
+	for _, outcome := range outcomes {
+		if outcome.Action != ResourceActionUnchanged {
+			count++
+		}
+	}
+
+	return count
+}
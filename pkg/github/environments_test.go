@@ -0,0 +1,278 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const existingEnvironmentsResponse = `{"total_count":2,"environments":[
+	{"name":"production","can_admins_bypass":true,
+		"deployment_branch_policy":{"protected_branches":false,"custom_branch_policies":true},
+		"protection_rules":[
+			{"type":"wait_timer","wait_timer":10},
+			{"type":"required_reviewers","prevent_self_review":false,
+				"reviewers":[{"type":"User","reviewer":{"login":"alice","id":1}}]}]},
+	{"name":"old","can_admins_bypass":true}]}`
+
+func TestSyncEnvironments(t *testing.T) {
+	t.Parallel()
+
+	environments := []configtypes.EnvironmentConfig{
+		{
+			Name: "production",
+			Reviewers: &configtypes.EnvironmentReviewersConfig{
+				Users: []string{"alice"},
+				Teams: []string{"ops"},
+			},
+			WaitTimer: github.Ptr(10),
+			DeploymentBranchPolicy: &configtypes.DeploymentBranchPolicyConfig{
+				Branches: []string{"main", "release/*"},
+			},
+		},
+		{Name: "staging", WaitTimer: github.Ptr(5)},
+	}
+
+	policies := "/repos/org/repo/environments/production/deployment-branch-policies"
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/environments": existingEnvironmentsResponse,
+		"GET " + policies: `{"total_count":2,"branch_policies":[
+			{"id":11,"name":"main","type":"branch"},{"id":12,"name":"v*","type":"tag"}]}`,
+		"GET /users/alice":                            `{"login":"alice","id":1}`,
+		"GET /orgs/org/teams/ops":                     `{"slug":"ops","id":7}`,
+		"PUT /repos/org/repo/environments/production": `{"name":"production"}`,
+		"PUT /repos/org/repo/environments/staging":    `{"name":"staging"}`,
+		"POST " + policies:                            `{"id":13}`,
+		"DELETE " + policies + "/12":                  ``,
+		"DELETE /repos/org/repo/environments/old":     ``,
+	})
+
+	outcomes, err := SyncEnvironments(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		environments, nil, true, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncEnvironments() error = %v", err)
+	}
+
+	wantOutcomes := []EnvironmentOutcome{
+		{
+			Name:   "production",
+			Action: ResourceActionUpdated,
+			Fields: []FieldDiff{
+				{Field: "reviewers.teams", Current: []string{}, Desired: []string{"ops"}},
+				{
+					Field:   "deployment_branch_policy.branches",
+					Current: []string{"main"},
+					Desired: []string{"main", "release/*"},
+				},
+				{
+					Field:   "deployment_branch_policy.tags",
+					Current: []string{"v*"},
+					Desired: []string{},
+				},
+			},
+		},
+		{Name: "staging", Action: ResourceActionCreated},
+		{Name: "old", Action: ResourceActionDeleted},
+	}
+
+	if diff := cmp.Diff(wantOutcomes, outcomes); diff != "" {
+		t.Errorf("outcomes mismatch (-want +got):\n%s", diff)
+	}
+
+	var production map[string]any
+	if err := json.Unmarshal(
+		[]byte(fake.body("PUT /repos/org/repo/environments/production")), &production,
+	); err != nil {
+		t.Fatalf("decoding request body: %v", err)
+	}
+
+	wantProduction := map[string]any{
+		"wait_timer": float64(10),
+		"reviewers": []any{
+			map[string]any{"type": "User", "id": float64(1)},
+			map[string]any{"type": "Team", "id": float64(7)},
+		},
+		"can_admins_bypass": true,
+		"deployment_branch_policy": map[string]any{
+			"protected_branches":     false,
+			"custom_branch_policies": true,
+		},
+		"prevent_self_review": false,
+	}
+
+	if diff := cmp.Diff(wantProduction, production); diff != "" {
+		t.Errorf("production request mismatch (-want +got):\n%s", diff)
+	}
+
+	policy := fake.body("POST " + policies)
+	if diff := cmp.Diff(`{"name":"release/*","type":"branch"}`+"\n", policy); diff != "" {
+		t.Errorf("branch policy request mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, key := range []string{
+		"DELETE " + policies + "/12",
+		"PUT /repos/org/repo/environments/staging",
+		"DELETE /repos/org/repo/environments/old",
+	} {
+		if !fake.called(key) {
+			t.Errorf("expected request %s", key)
+		}
+	}
+}
+
+func TestSyncEnvironmentsPlan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		environments []configtypes.EnvironmentConfig
+		exclude      []string
+		allowRemoval bool
+		want         []EnvironmentOutcome
+	}{
+		{
+			name: "in sync",
+			environments: []configtypes.EnvironmentConfig{
+				{
+					Name: "production",
+					Reviewers: &configtypes.EnvironmentReviewersConfig{
+						Users: []string{"alice"},
+					},
+					WaitTimer:         github.Ptr(10),
+					PreventSelfReview: github.Ptr(false),
+				},
+			},
+			want: []EnvironmentOutcome{{Name: "production", Action: ResourceActionUnchanged}},
+		},
+		{
+			name: "changed fields",
+			environments: []configtypes.EnvironmentConfig{
+				{
+					Name:            "production",
+					WaitTimer:       github.Ptr(30),
+					CanAdminsBypass: github.Ptr(false),
+					DeploymentBranchPolicy: &configtypes.DeploymentBranchPolicyConfig{
+						ProtectedBranches: true,
+					},
+				},
+			},
+			want: []EnvironmentOutcome{
+				{
+					Name:   "production",
+					Action: ResourceActionUpdated,
+					Fields: []FieldDiff{
+						{Field: "wait_timer", Current: 10, Desired: 30},
+						{Field: "can_admins_bypass", Current: true, Desired: false},
+						{
+							Field:   "deployment_branch_policy",
+							Current: deploymentPolicyCustom,
+							Desired: deploymentPolicyProtectedBranches,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:         "excluded environments are neither synced nor removed",
+			environments: []configtypes.EnvironmentConfig{{Name: "production"}},
+			exclude:      []string{"environments.production", "environments.old"},
+			allowRemoval: true,
+			want:         []EnvironmentOutcome{},
+		},
+		{
+			name:         "excluded section",
+			environments: []configtypes.EnvironmentConfig{{Name: "production"}},
+			exclude:      []string{"environments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/environments": existingEnvironmentsResponse,
+				"GET /users/alice":                 `{"login":"alice","id":1}`,
+			})
+
+			outcomes, err := SyncEnvironments(
+				context.Background(), logger.New("error"), client, "org", "repo",
+				tt.environments, tt.exclude, tt.allowRemoval, true,
+			)
+			if err != nil {
+				t.Fatalf("SyncEnvironments() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, outcomes); diff != "" {
+				t.Errorf("outcomes mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, key := range fake.requests {
+				if key != "GET /repos/org/repo/environments" && key != "GET /users/alice" {
+					t.Errorf("unexpected request in dry-run: %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncEnvironmentsUnresolvedReviewer(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/environments": `{"total_count":0,"environments":[]}`,
+	})
+
+	environments := []configtypes.EnvironmentConfig{
+		{
+			Name:      "production",
+			Reviewers: &configtypes.EnvironmentReviewersConfig{Teams: []string{"ghosts"}},
+		},
+	}
+
+	_, err := SyncEnvironments(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		environments, nil, false, true,
+	)
+	if !errors.Is(err, ErrUnresolvedEnvironmentReviewer) {
+		t.Fatalf("SyncEnvironments() error = %v, want %v", err, ErrUnresolvedEnvironmentReviewer)
+	}
+}
+
+func TestApplySettingsMergeEnvironments(t *testing.T) {
+	t.Parallel()
+
+	settings := &SettingsDefinition{
+		Environments: []configtypes.EnvironmentConfig{
+			{Name: "production", WaitTimer: github.Ptr(10)},
+		},
+	}
+
+	syncConfig := &configtypes.SyncConfig{}
+	syncConfig.Sync.Settings.Merge = []configtypes.SettingsMergeConfig{
+		{Section: "production", Overrides: map[string]any{"wait_timer": 60}},
+	}
+
+	merged, err := ApplySettingsMerge(logger.New("error"), settings, syncConfig)
+	if err != nil {
+		t.Fatalf("ApplySettingsMerge() error = %v", err)
+	}
+
+	if got := merged.Environments[0].WaitTimer; got == nil || *got != 60 {
+		t.Errorf("merged wait_timer = %v, want 60", got)
+	}
+
+	if got := *settings.Environments[0].WaitTimer; got != 10 {
+		t.Errorf("original wait_timer = %d, want 10", got)
+	}
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves canned JSON responses keyed by "METHOD path" and records requests.
+type fakeGitHub struct {
+	t         *testing.T
+	responses map[string]string
+
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]string
+}
+
+func newFakeGitHub(t *testing.T, responses map[string]string) (*fakeGitHub, *Client) {
+	t.Helper()
+
+	fake := &fakeGitHub{
+		t:         t,
+		responses: responses,
+		bodies:    make(map[string]string),
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, newTestClient(t, server.URL)
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.t.Errorf("reading request body: %v", err)
+	}
+
+	// GraphQL operations share a path, so responses may also be keyed by a name the
+	// request contains, e.g. "POST /graphql enqueuePullRequest"
+	for candidate := range f.responses {
+		name, ok := strings.CutPrefix(candidate, key+" ")
+		if ok && strings.Contains(string(body), name) {
+			key = candidate
+
+			break
+		}
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, key)
+	f.bodies[key] = string(body)
+	f.mu.Unlock()
+
+	response, ok := f.responses[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(response))
+}
+
+func (f *fakeGitHub) called(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Contains(f.requests, key)
+}
+
+func (f *fakeGitHub) body(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.bodies[key]
+}
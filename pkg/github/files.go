@@ -21,7 +21,16 @@ import (
 
 const (
 	httpStatusNotFound    = 404
+	httpStatusConflict    = 409
 	commitsPerPageForFile = 20
+
+	// syncCommitPrefix marks commits created by the sync workflow. Commits without
+	// it are treated as manual modifications.
+	syncCommitPrefix = "chore(sync):"
+	// filesCommitMessage is the message of the single commit used for file sync PRs.
+	filesCommitMessage = syncCommitPrefix + " sync organization files"
+	// filesDeletionSkipReason explains why auto-merge is skipped for PRs deleting files.
+	filesDeletionSkipReason = "PR deletes files and requires manual review"
 )
 
 // FileMapping represents a source to destination file mapping.
@@ -38,6 +47,12 @@ type FileChange struct {
 	BlobSHA string // For blobs created
 }
 
+// commitGroup is a set of file changes committed together with one message.
+type commitGroup struct {
+	Message string
+	Changes []FileChange
+}
+
 // FileSyncStats tracks file sync statistics.
 type FileSyncStats struct {
 	Created          int
@@ -66,16 +81,21 @@ func SyncFiles(
 	syncConfig *configtypes.SyncConfig,
 	branchPrefix string,
 	prLabels []string,
+	mergeMethod string,
+	perFileCommits bool,
+	commitOpts *CommitOptions,
 	dryRun bool,
 ) (*FilesSyncResult, error) {
 	result := NewFilesSyncResult(repo, dryRun)
+	prManager := NewPRManager(log, client, org, repo)
+	branchName := prManager.BranchName(branchPrefix)
 
 	// Check if sync is skipped
 	if syncConfig.Sync.Skip || syncConfig.Sync.Files.Skip {
 		log.Info("file sync skipped by config")
 
 		// Check for existing PR to close
-		if err := closeExistingPR(ctx, log, client, org, repo, branchPrefix,
+		if _, err := prManager.Close(ctx, branchName, PRCloseReasonDisabled,
 			"File synchronization is disabled for this repository"); err != nil {
 			log.Warn("failed to close existing PR", "error", err)
 		}
@@ -85,16 +105,6 @@ func SyncFiles(
 		return result, nil
 	}
 
-	// Parse files config
-	fileMappings, err := parseFilesConfig(filesConfig)
-	if err != nil {
-		result.CompleteWithError(errors.Wrap(err, "parsing files config"))
-
-		return result, err
-	}
-
-	log.Debug("parsed files config", "count", len(fileMappings))
-
 	// Get repository info
 	defaultBranch, baseSHA, err := getRepoBaseInfo(ctx, log, client, org, repo)
 	if err != nil {
@@ -104,29 +114,15 @@ func SyncFiles(
 	}
 
 	// Process files
-	stats := &FileSyncStats{
-		MergedFiles: make(map[string]string),
-	}
-
-	var changes []FileChange
+	changes, stats, err := planFileChanges(
+		ctx, log, client, org, repo, sourceRepo, defaultBranch, filesConfig, syncConfig,
+	)
+	if err != nil {
+		result.CompleteWithError(err)
 
-	for _, mapping := range fileMappings {
-		fileChanges := processFileMapping(
-			ctx, log, client, org, repo, sourceRepo, defaultBranch, mapping, syncConfig, stats,
-		)
-		changes = append(changes, fileChanges...)
+		return result, err
 	}
 
-	// Log stats
-	log.Info("file sync summary",
-		"created", stats.Created,
-		"updated", stats.Updated,
-		"deleted", stats.Deleted,
-		"skipped", stats.Skipped,
-		"excluded", stats.Excluded,
-		"modified_excluded", stats.ModifiedExcluded,
-	)
-
 	// Populate result from stats
 	result.CreatedFiles = stats.CreatedFiles
 	result.UpdatedFiles = stats.UpdatedFiles
@@ -137,7 +133,7 @@ func SyncFiles(
 	if len(changes) == 0 {
 		log.Info("no changes needed")
 
-		if closeErr := closeExistingPR(ctx, log, client, org, repo, branchPrefix,
+		if _, closeErr := prManager.Close(ctx, branchName, PRCloseReasonInSync,
 			"All files are now in sync. Closing this PR."); closeErr != nil {
 			log.Warn("failed to close existing PR", "error", closeErr)
 		}
@@ -156,28 +152,31 @@ func SyncFiles(
 	}
 
 	// Create/update PR
-	prNumber, prURL, err := createOrUpdatePRWithResult(
-		ctx,
-		log,
-		client,
-		org,
-		repo,
-		sourceRepo,
-		defaultBranch,
-		baseSHA,
-		branchPrefix,
-		prLabels,
-		changes,
-		stats,
-	)
+	spec := &pullRequestSpec{
+		Branch:      branchName,
+		Base:        defaultBranch,
+		Title:       filesCommitMessage,
+		Body:        buildPRBody(org, sourceRepo, stats),
+		Labels:      filesPRLabels(prLabels, stats),
+		MergeMethod: mergeMethod,
+	}
+
+	if stats.Deleted > 0 {
+		spec.AutoMergeSkipReason = filesDeletionSkipReason
+	}
+
+	groups := buildCommitGroups(changes, perFileCommits)
+
+	pr, err := prManager.Publish(ctx, baseSHA, spec, groups, commitOpts)
 	if err != nil {
 		result.CompleteWithError(errors.Wrap(err, "creating or updating PR"))
 
 		return result, err
 	}
 
-	result.PRNumber = prNumber
-	result.PRURL = prURL
+	result.PRNumber = pr.Number
+	result.PRURL = pr.URL
+	result.AutoMerge = pr.AutoMerge
 
 	log.Info("file sync completed successfully")
 	result.Complete(StatusSuccess)
@@ -185,6 +184,63 @@ func SyncFiles(
 	return result, nil
 }
 
+// planFileChanges computes the file changes needed to bring a repository in sync
+// with the configured file mappings.
+func planFileChanges(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	sourceRepo string,
+	defaultBranch string,
+	filesConfig string,
+	syncConfig *configtypes.SyncConfig,
+) ([]FileChange, *FileSyncStats, error) {
+	// Parse files config
+	fileMappings, err := parseFilesConfig(filesConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing files config")
+	}
+
+	log.Debug("parsed files config", "count", len(fileMappings))
+
+	stats := &FileSyncStats{
+		MergedFiles: make(map[string]string),
+	}
+
+	var changes []FileChange
+
+	for _, mapping := range fileMappings {
+		fileChanges := processFileMapping(
+			ctx, log, client, org, repo, sourceRepo, defaultBranch, mapping, syncConfig, stats,
+		)
+		changes = append(changes, fileChanges...)
+	}
+
+	// Log stats
+	log.Info("file sync summary",
+		"created", stats.Created,
+		"updated", stats.Updated,
+		"deleted", stats.Deleted,
+		"skipped", stats.Skipped,
+		"excluded", stats.Excluded,
+		"modified_excluded", stats.ModifiedExcluded,
+	)
+
+	return changes, stats, nil
+}
+
+// filesPRLabels returns the labels for a file sync PR, flagging deletions for review.
+func filesPRLabels(prLabels []string, stats *FileSyncStats) []string {
+	labels := slices.Clone(prLabels)
+	if stats.Deleted > 0 {
+		labels = append(labels, destructiveLabel)
+	}
+
+	return labels
+}
+
 // getRepoBaseInfo retrieves the default branch and base SHA for a repository.
 func getRepoBaseInfo(
 	ctx context.Context,
@@ -589,6 +645,16 @@ func isNotFoundError(err error) bool {
 	return false
 }
 
+// isConflictError checks if an error is a 409 Conflict error.
+func isConflictError(err error) bool {
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) {
+		return ghErr.Response.StatusCode == httpStatusConflict
+	}
+
+	return false
+}
+
 // checkNonStandardRenovateConfigs checks for non-standard renovate config files.
 func checkNonStandardRenovateConfigs(
 	ctx context.Context,
@@ -657,7 +723,7 @@ func hasManualModifications(
 	// Check if any commits are not from sync workflow
 	for _, commit := range commits {
 		message := commit.GetCommit().GetMessage()
-		if !strings.HasPrefix(message, "chore(sync):") {
+		if !strings.HasPrefix(message, syncCommitPrefix) {
 			return true, nil
 		}
 	}
@@ -665,163 +731,94 @@ func hasManualModifications(
 	return false, nil
 }
 
-// closeExistingPR closes an existing PR if it exists.
-func closeExistingPR(
+// createGitCommit creates blobs, trees, and commits for the commit groups.
+//
+// Each group becomes one commit chained on top of the previous one, starting at
+// baseSHA. The branch ref is updated once, after all commits are created.
+func createGitCommit(
 	ctx context.Context,
 	log *logger.Logger,
 	client *Client,
 	org string,
 	repo string,
-	branchPrefix string,
-	comment string,
+	branchName string,
+	baseSHA string,
+	groups []commitGroup,
+	commitOpts *CommitOptions,
 ) error {
-	branchName := getBranchName(repo, branchPrefix)
-
-	// List open PRs for the branch
-	prs, _, err := client.PullRequests.List(ctx, org, repo, &github.PullRequestListOptions{
-		State: "open",
-		Head:  org + ":" + branchName,
-	})
-	if err != nil {
-		return errors.Wrap(err, "listing PRs")
-	}
-
-	if len(prs) == 0 {
-		return nil
-	}
-
-	prNumber := prs[0].GetNumber()
-	log.Info("closing existing PR", "pr", prNumber)
-
-	// Close the PR
-	pr := &github.PullRequest{
-		State: github.Ptr("closed"),
-	}
-
-	if _, _, errEdit := client.PullRequests.Edit(ctx, org, repo, prNumber, pr); errEdit != nil {
-		return errors.Wrap(errEdit, "closing PR")
-	}
-
-	// Add comment
-	prComment := &github.IssueComment{
-		Body: github.Ptr(comment),
+	// Create blobs for all files
+	for _, group := range groups {
+		if err := createBlobs(ctx, log, client, org, repo, group.Changes); err != nil {
+			return err
+		}
 	}
 
-	_, _, err = client.Issues.CreateComment(ctx, org, repo, prNumber, prComment)
+	// Get base tree
+	baseCommit, _, err := client.Git.GetCommit(ctx, org, repo, baseSHA)
 	if err != nil {
-		log.Warn("failed to add PR comment", "error", err)
+		return errors.Wrap(err, "getting base commit")
 	}
 
-	return nil
-}
+	parentSHA := baseSHA
+	parentTreeSHA := baseCommit.GetTree().GetSHA()
 
-// getBranchName generates the branch name from repo name and prefix.
-func getBranchName(repo string, branchPrefix string) string {
-	// Strip leading dot from repo name
-	repoSanitized := strings.TrimPrefix(repo, ".")
+	for _, group := range groups {
+		// Build tree entries
+		treeEntries := buildTreeEntries(group.Changes)
 
-	return fmt.Sprintf("%s/%s", branchPrefix, repoSanitized)
-}
+		// Create tree
+		log.Debug("creating tree", "entries", len(treeEntries))
 
-// createOrUpdatePRWithResult creates or updates a PR and returns PR number and URL.
-func createOrUpdatePRWithResult(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	sourceRepo string,
-	defaultBranch string,
-	baseSHA string,
-	branchPrefix string,
-	prLabels []string,
-	changes []FileChange,
-	stats *FileSyncStats,
-) (int, string, error) {
-	branchName := getBranchName(repo, branchPrefix)
-	log.Info("creating/updating PR", "branch", branchName)
-
-	// Ensure branch exists
-	if err := ensureBranchExists(ctx, log, client, org, repo, branchName, baseSHA); err != nil {
-		return 0, "", errors.Wrap(err, "ensuring branch exists")
-	}
-
-	// Create Git commit
-	if err := createGitCommit(ctx, log, client, org, repo, branchName, baseSHA, changes); err != nil {
-		return 0, "", errors.Wrap(err, "creating Git commit")
-	}
-
-	// Create or update pull request
-	prNumber, prURL, err := upsertPullRequestWithURL(
-		ctx, log, client, org, repo, sourceRepo, defaultBranch, branchName, stats,
-	)
-	if err != nil {
-		return 0, "", errors.Wrap(err, "upserting pull request")
-	}
+		tree, _, treeErr := client.Git.CreateTree(ctx, org, repo, parentTreeSHA, treeEntries)
+		if treeErr != nil {
+			return errors.Wrap(treeErr, "creating tree")
+		}
 
-	// Add labels and enable auto-merge
-	if err := finalizePR(ctx, log, client, org, repo, prNumber, prLabels, stats); err != nil {
-		return prNumber, prURL, err
-	}
+		// Create commit
+		log.Debug("creating commit", "message", group.Message)
 
-	return prNumber, prURL, nil
-}
+		commit := github.Commit{
+			Message: github.Ptr(group.Message),
+			Tree:    tree,
+			Parents: []*github.Commit{
+				{SHA: github.Ptr(parentSHA)},
+			},
+		}
 
-// ensureBranchExists creates the branch if it doesn't exist.
-func ensureBranchExists(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	branchName string,
-	baseSHA string,
-) error {
-	// Check if branch exists
-	_, existingRef, err := client.Git.GetRef(ctx, org, repo, "heads/"+branchName)
-	branchExists := err == nil && existingRef != nil
-
-	// If branch exists, check for merged PR and delete if merged
-	if branchExists {
-		if mergeErr := handleMergedPR(ctx, log, client, org, repo, branchName); mergeErr != nil {
-			log.Warn("failed to handle merged PR", "error", mergeErr)
+		newCommit, commitErr := createCommit(ctx, client, org, repo, commit, commitOpts)
+		if commitErr != nil {
+			return commitErr
 		}
 
-		// Check again if branch still exists after handling merged PR
-		_, existingRef, err = client.Git.GetRef(ctx, org, repo, "heads/"+branchName)
-		branchExists = err == nil && existingRef != nil
+		parentSHA = newCommit.GetSHA()
+		parentTreeSHA = tree.GetSHA()
 	}
 
-	// Create branch if it doesn't exist
-	if !branchExists {
-		log.Debug("creating branch", "branch", branchName)
+	// Update branch ref
+	log.Debug("updating branch ref", "sha", parentSHA[:7], "commits", len(groups))
 
-		ref := github.CreateRef{
-			Ref: "refs/heads/" + branchName,
-			SHA: baseSHA,
-		}
+	updateRef := github.UpdateRef{
+		SHA:   parentSHA,
+		Force: github.Ptr(true),
+	}
 
-		_, _, createErr := client.Git.CreateRef(ctx, org, repo, ref)
-		if createErr != nil {
-			return errors.Wrap(createErr, "creating branch")
-		}
+	_, _, err = client.Git.UpdateRef(ctx, org, repo, "heads/"+branchName, updateRef)
+	if err != nil {
+		return errors.Wrap(err, "updating branch ref")
 	}
 
 	return nil
 }
 
-// createGitCommit creates blobs, tree, and commit for the changes.
-func createGitCommit(
+// createBlobs creates blobs for all non-deleted files and records their SHAs.
+func createBlobs(
 	ctx context.Context,
 	log *logger.Logger,
 	client *Client,
 	org string,
 	repo string,
-	branchName string,
-	baseSHA string,
 	changes []FileChange,
 ) error {
-	// Create blobs for all files
 	log.Debug("creating blobs", "count", len(changes))
 
 	for i := range changes {
@@ -834,64 +831,48 @@ func createGitCommit(
 			Encoding: github.Ptr("base64"),
 		}
 
-		createdBlob, _, blobErr := client.Git.CreateBlob(ctx, org, repo, blob)
-		if blobErr != nil {
-			return errors.Wrapf(blobErr, "creating blob for %s", changes[i].Path)
+		createdBlob, _, err := client.Git.CreateBlob(ctx, org, repo, blob)
+		if err != nil {
+			return errors.Wrapf(err, "creating blob for %s", changes[i].Path)
 		}
 
 		changes[i].BlobSHA = createdBlob.GetSHA()
 	}
 
-	// Get base tree
-	baseCommit, _, err := client.Git.GetCommit(ctx, org, repo, baseSHA)
-	if err != nil {
-		return errors.Wrap(err, "getting base commit")
-	}
-
-	baseTreeSHA := baseCommit.GetTree().GetSHA()
-
-	// Build tree entries
-	treeEntries := buildTreeEntries(changes)
-
-	// Create tree
-	log.Debug("creating tree")
+	return nil
+}
 
-	tree, _, err := client.Git.CreateTree(ctx, org, repo, baseTreeSHA, treeEntries)
-	if err != nil {
-		return errors.Wrap(err, "creating tree")
+// buildCommitGroups splits changes into commit groups. With perFileCommits every
+// change gets its own commit, otherwise all changes share a single commit.
+func buildCommitGroups(changes []FileChange, perFileCommits bool) []commitGroup {
+	if !perFileCommits {
+		return []commitGroup{{Message: filesCommitMessage, Changes: changes}}
 	}
 
-	// Create commit
-	log.Debug("creating commit")
+	groups := make([]commitGroup, 0, len(changes))
 
-	commitMessage := "chore(sync): sync organization files"
-	commit := github.Commit{
-		Message: github.Ptr(commitMessage),
-		Tree:    tree,
-		Parents: []*github.Commit{
-			{SHA: github.Ptr(baseSHA)},
-		},
+	for _, change := range changes {
+		groups = append(groups, commitGroup{
+			Message: perFileCommitMessage(change),
+			Changes: []FileChange{change},
+		})
 	}
 
-	newCommit, _, err := client.Git.CreateCommit(ctx, org, repo, commit, nil)
-	if err != nil {
-		return errors.Wrap(err, "creating commit")
-	}
+	return groups
+}
 
-	// Update branch ref
-	log.Debug("updating branch ref", "sha", newCommit.GetSHA()[:7])
+// perFileCommitMessage returns the commit message for a single file change.
+func perFileCommitMessage(change FileChange) string {
+	verb := "update"
 
-	updateRef := github.UpdateRef{
-		SHA:   newCommit.GetSHA(),
-		Force: github.Ptr(true),
+	switch change.Action {
+	case "create":
+		verb = "add"
+	case "delete":
+		verb = "remove"
 	}
 
-	_, _, err = client.Git.UpdateRef(ctx, org, repo, "heads/"+branchName, updateRef)
-	if err != nil {
-		return errors.Wrap(err, "updating branch ref")
-	}
-
-	return nil
+	return fmt.Sprintf("%s %s %s", syncCommitPrefix, verb, change.Path)
 }
 
 // buildTreeEntries builds tree entries from file changes.
@@ -919,156 +900,36 @@ func buildTreeEntries(changes []FileChange) []*github.TreeEntry {
 	return treeEntries
 }
 
-// upsertPullRequestWithURL creates or updates a pull request and returns number and URL.
-func upsertPullRequestWithURL(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	sourceRepo string,
-	defaultBranch string,
-	branchName string,
-	stats *FileSyncStats,
-) (int, string, error) {
-	prTitle := "chore(sync): sync organization files"
-	prBody := buildPRBody(org, sourceRepo, stats)
-
-	// Check for existing PR
-	prs, _, err := client.PullRequests.List(ctx, org, repo, &github.PullRequestListOptions{
-		State: "open",
-		Head:  org + ":" + branchName,
-	})
-	if err != nil {
-		return 0, "", errors.Wrap(err, "listing PRs")
-	}
-
-	if len(prs) > 0 {
-		// Update existing PR
-		prNumber := prs[0].GetNumber()
-		prURL := prs[0].GetHTMLURL()
-
-		log.Info("updating existing PR", "pr", prNumber)
-
-		pr := &github.PullRequest{
-			Title: github.Ptr(prTitle),
-			Body:  github.Ptr(prBody),
-		}
-
-		_, _, editErr := client.PullRequests.Edit(ctx, org, repo, prNumber, pr)
-		if editErr != nil {
-			return 0, "", errors.Wrap(editErr, "updating PR")
-		}
-
-		return prNumber, prURL, nil
-	}
-
-	// Create new PR
-	log.Info("creating new PR")
-
-	pr := &github.NewPullRequest{
-		Title: github.Ptr(prTitle),
-		Head:  github.Ptr(branchName),
-		Base:  github.Ptr(defaultBranch),
-		Body:  github.Ptr(prBody),
-	}
+// buildPRBody builds the PR body text.
+func buildPRBody(org string, sourceRepo string, stats *FileSyncStats) string {
+	var body strings.Builder
 
-	createdPR, _, err := client.PullRequests.Create(ctx, org, repo, pr)
-	if err != nil {
-		return 0, "", errors.Wrap(err, "creating PR")
-	}
+	writeFilesPRSections(&body, org, sourceRepo, stats, "##")
 
-	prNumber := createdPR.GetNumber()
-	prURL := createdPR.GetHTMLURL()
-	log.Info("created PR", "pr", prNumber, "url", prURL)
+	body.WriteString("\n---\n\n")
+	body.WriteString("*This PR was automatically created by the org file sync workflow*\n")
 
-	return prNumber, prURL, nil
+	return body.String()
 }
 
-// finalizePR adds labels and enables auto-merge for a PR.
-//
-//nolint:unparam // Error return kept for consistency with similar functions
-func finalizePR(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
+// writeFilesPRSections writes the file sync sections of a PR body using the given
+// markdown heading level.
+func writeFilesPRSections(
+	body *strings.Builder,
 	org string,
-	repo string,
-	prNumber int,
-	prLabels []string,
+	sourceRepo string,
 	stats *FileSyncStats,
-) error {
-	// Add labels (include review/destructive if deletions present)
-	labels := prLabels
-	if stats.Deleted > 0 {
-		labels = append(labels, "review/destructive")
-	}
-
-	if len(labels) > 0 {
-		_, _, err := client.Issues.AddLabelsToIssue(ctx, org, repo, prNumber, labels)
-		if err != nil {
-			log.Warn("failed to add labels to PR", "error", err)
-		}
-	}
-
-	// Enable auto-merge if no deletions
-	if stats.Deleted == 0 {
-		if err := enableAutoMerge(ctx, log, client, org, repo, prNumber); err != nil {
-			log.Warn("failed to enable auto-merge", "error", err)
-		}
-	} else {
-		log.Info("skipping auto-merge due to deletions")
-	}
-
-	return nil
-}
-
-// handleMergedPR handles a branch with a merged PR.
-func handleMergedPR(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	branchName string,
-) error {
-	// Check for merged PRs
-	prs, _, err := client.PullRequests.List(ctx, org, repo, &github.PullRequestListOptions{
-		State: "closed",
-		Head:  org + ":" + branchName,
-	})
-	if err != nil {
-		return errors.Wrap(err, "listing closed PRs")
-	}
-
-	for _, pr := range prs {
-		if pr.GetMerged() {
-			log.Info("deleting branch with merged PR", "pr", pr.GetNumber())
-
-			_, err := client.Git.DeleteRef(ctx, org, repo, "heads/"+branchName)
-			if err != nil {
-				return errors.Wrap(err, "deleting branch")
-			}
-
-			return nil
-		}
-	}
-
-	return nil
-}
-
-// buildPRBody builds the PR body text.
-func buildPRBody(org string, sourceRepo string, stats *FileSyncStats) string {
-	var body strings.Builder
-
-	body.WriteString(fmt.Sprintf(
+	heading string,
+) {
+	fmt.Fprintf(
+		body,
 		"Syncs organization files from [`%s/%s`](https://github.com/%s/%s).\n",
 		org, sourceRepo, org, sourceRepo,
-	))
+	)
 
 	// Files merged section (show first since these are customized)
 	if len(stats.MergedFiles) > 0 {
-		body.WriteString("\n## Files Merged with Configured Overrides\n\n")
+		fmt.Fprintf(body, "\n%s Files Merged with Configured Overrides\n\n", heading)
 
 		// Sort files for deterministic output
 		mergedPaths := make([]string, 0, len(stats.MergedFiles))
@@ -1080,27 +941,27 @@ func buildPRBody(org string, sourceRepo string, stats *FileSyncStats) string {
 
 		for _, file := range mergedPaths {
 			strategy := stats.MergedFiles[file]
-			body.WriteString(fmt.Sprintf("- `%s` (%s strategy)\n", file, strategy))
+			fmt.Fprintf(body, "- `%s` (%s strategy)\n", file, strategy)
 		}
 	}
 
 	// Files created section (exclude merged files)
 	createdNonMerged := filterOutMerged(stats.CreatedFiles, stats.MergedFiles)
 	if len(createdNonMerged) > 0 {
-		body.WriteString("\n## Files Created\n\n")
+		fmt.Fprintf(body, "\n%s Files Created\n\n", heading)
 
 		for _, file := range createdNonMerged {
-			body.WriteString(fmt.Sprintf("- `%s`\n", file))
+			fmt.Fprintf(body, "- `%s`\n", file)
 		}
 	}
 
 	// Files updated section (exclude merged files)
 	updatedNonMerged := filterOutMerged(stats.UpdatedFiles, stats.MergedFiles)
 	if len(updatedNonMerged) > 0 {
-		body.WriteString("\n## Files Updated\n\n")
+		fmt.Fprintf(body, "\n%s Files Updated\n\n", heading)
 
 		for _, file := range updatedNonMerged {
-			body.WriteString(fmt.Sprintf("- `%s`\n", file))
+			fmt.Fprintf(body, "- `%s`\n", file)
 		}
 	}
 
@@ -1114,80 +975,12 @@ func buildPRBody(org string, sourceRepo string, stats *FileSyncStats) string {
 		body.WriteString(">\n")
 
 		for _, file := range stats.DeletedFiles {
-			body.WriteString(fmt.Sprintf("> - `%s`\n", file))
+			fmt.Fprintf(body, "> - `%s`\n", file)
 		}
 
 		body.WriteString(">\n")
 		body.WriteString("> **This PR requires manual review before merging.**\n")
 	}
-
-	body.WriteString("\n---\n\n")
-	body.WriteString("*This PR was automatically created by the org file sync workflow*\n")
-
-	return body.String()
-}
-
-// enableAutoMerge enables auto-merge for a PR.
-func enableAutoMerge(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	prNumber int,
-) error {
-	log.Debug("enabling auto-merge", "pr", prNumber)
-
-	// Get PR node ID
-	pr, _, err := client.PullRequests.Get(ctx, org, repo, prNumber)
-	if err != nil {
-		return errors.Wrap(err, "getting PR")
-	}
-
-	nodeID := pr.GetNodeID()
-
-	// GraphQL mutation to enable auto-merge
-	mutation := `
-mutation($prId: ID!) {
-  enablePullRequestAutoMerge(input: {
-    pullRequestId: $prId,
-    mergeMethod: SQUASH
-  }) {
-    pullRequest {
-      autoMergeRequest {
-        enabledAt
-      }
-    }
-  }
-}`
-
-	variables := map[string]any{
-		"prId": nodeID,
-	}
-
-	input := struct {
-		Query     string         `json:"query"`
-		Variables map[string]any `json:"variables"`
-	}{
-		Query:     mutation,
-		Variables: variables,
-	}
-
-	req, err := client.NewRequest("POST", "graphql", input)
-	if err != nil {
-		return errors.Wrap(err, "creating GraphQL request")
-	}
-
-	var result any
-
-	_, err = client.Do(ctx, req, &result)
-	if err != nil {
-		return errors.Wrap(err, "executing GraphQL mutation")
-	}
-
-	log.Debug("auto-merge enabled")
-
-	return nil
 }
 
 // logFileChanges logs the planned file changes in dry-run mode.
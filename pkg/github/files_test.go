@@ -2,6 +2,7 @@ package github
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -78,3 +79,64 @@ func TestRenderFileTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildCommitGroups(t *testing.T) {
+	t.Parallel()
+
+	changes := []FileChange{
+		{Path: "renovate.json", Action: "update"},
+		{Path: ".github/CODEOWNERS", Action: "create"},
+		{Path: "old.yml", Action: "delete"},
+	}
+
+	tests := []struct {
+		name           string
+		perFileCommits bool
+		wantMessages   []string
+		wantSizes      []int
+	}{
+		{
+			name:           "single commit",
+			perFileCommits: false,
+			wantMessages:   []string{"chore(sync): sync organization files"},
+			wantSizes:      []int{3},
+		},
+		{
+			name:           "one commit per file",
+			perFileCommits: true,
+			wantMessages: []string{
+				"chore(sync): update renovate.json",
+				"chore(sync): add .github/CODEOWNERS",
+				"chore(sync): remove old.yml",
+			},
+			wantSizes: []int{1, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			groups := buildCommitGroups(changes, tt.perFileCommits)
+
+			if len(groups) != len(tt.wantMessages) {
+				t.Fatalf("buildCommitGroups() returned %d groups, want %d", len(groups), len(tt.wantMessages))
+			}
+
+			for i, group := range groups {
+				if group.Message != tt.wantMessages[i] {
+					t.Errorf("group %d message = %q, want %q", i, group.Message, tt.wantMessages[i])
+				}
+
+				if len(group.Changes) != tt.wantSizes[i] {
+					t.Errorf("group %d has %d changes, want %d", i, len(group.Changes), tt.wantSizes[i])
+				}
+
+				// hasManualModifications relies on this prefix to recognise sync commits
+				if !strings.HasPrefix(group.Message, syncCommitPrefix) {
+					t.Errorf("group %d message %q lacks sync prefix", i, group.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// ErrGraphQL indicates the GraphQL API returned errors in its response body.
+var ErrGraphQL = errors.New("GraphQL request failed")
+
+// graphQLRequest is the body of a GraphQL API request.
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// graphQLResponse is the envelope of a GraphQL API response.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors"`
+}
+
+// graphQLError is a single error entry of a GraphQL API response.
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// executeGraphQL runs a GraphQL query or mutation and decodes its data into out.
+// GraphQL reports most failures with HTTP 200 and an errors array, so those are
+// surfaced as ErrGraphQL instead of being silently ignored. out may be nil.
+func executeGraphQL(
+	ctx context.Context,
+	client *Client,
+	query string,
+	variables map[string]any,
+	out any,
+) error {
+	req, err := client.NewRequest("POST", "graphql", graphQLRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating GraphQL request")
+	}
+
+	var resp graphQLResponse
+
+	if _, err := client.Do(ctx, req, &resp); err != nil {
+		return errors.Wrap(err, "executing GraphQL request")
+	}
+
+	if len(resp.Errors) > 0 {
+		return errors.Wrap(ErrGraphQL, joinGraphQLErrors(resp.Errors))
+	}
+
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return errors.Wrap(err, "decoding GraphQL response")
+	}
+
+	return nil
+}
+
+// joinGraphQLErrors joins GraphQL error messages into a single string.
+func joinGraphQLErrors(errs []graphQLError) string {
+	messages := make([]string, 0, len(errs))
+
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
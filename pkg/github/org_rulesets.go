@@ -0,0 +1,172 @@
+package github
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// SyncOrgRulesets synchronizes organization rulesets from the org_rulesets section of a
+// settings file. It runs once per organization; the result uses the organization as repo.
+// Changes weakening the rulesets, including their removal, are blocked and reported
+// unless allowWeakening is set.
+func SyncOrgRulesets(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	settingsFile string,
+	allowRemoval bool,
+	allowWeakening bool,
+	dryRun bool,
+) (*SettingsSyncResult, error) {
+	result := NewSettingsSyncResult(org, dryRun)
+
+	desiredSettings, err := parseSettingsFile(settingsFile)
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "parsing settings file"))
+
+		return result, err
+	}
+
+	log.Debug("parsed settings file", "file", settingsFile)
+
+	if len(desiredSettings.OrgRulesets) == 0 && !allowRemoval {
+		log.Info("no organization rulesets configured")
+		result.CompleteSkipped("no organization rulesets configured")
+
+		return result, nil
+	}
+
+	specs := make([]rulesetSpec, 0, len(desiredSettings.OrgRulesets))
+
+	for _, rulesetConfig := range desiredSettings.OrgRulesets {
+		if rules := rulesetConfig.Rules; rules != nil && rules.RequiredStatusChecks != nil &&
+			getBoolValue(rules.RequiredStatusChecks.Discover) {
+			log.Warn("ignoring status check discovery in organization ruleset",
+				"name", rulesetConfig.Name)
+		}
+
+		actors, err := resolveBypassActors(ctx, client, org, rulesetConfig.BypassActors)
+		if err != nil {
+			err = errors.Wrapf(err, "planning organization ruleset %q", rulesetConfig.Name)
+			result.CompleteWithError(err)
+
+			return result, err
+		}
+
+		rulesetConfig.BypassActors = actors
+
+		specs = append(specs, rulesetSpec{
+			Name: rulesetConfig.Name,
+			Build: func(current *github.RepositoryRuleset) *github.RepositoryRuleset {
+				return buildOrgRulesetFromConfig(rulesetConfig, current)
+			},
+		})
+	}
+
+	store := &orgRulesetStore{client: client, org: org}
+	policy := &WeakeningPolicy{Allow: allowWeakening}
+
+	outcomes, err := syncRulesets(ctx, log, store, specs, allowRemoval, policy, dryRun)
+
+	result.Rulesets = outcomes
+	result.Blocked = policy.Blocked
+	result.ChangesApplied = countRulesetChanges(outcomes)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing organization rulesets"))
+
+		return result, err
+	}
+
+	result.Complete(StatusSuccess)
+
+	return result, nil
+}
+
+// buildOrgRulesetFromConfig converts an organization ruleset config to a go-github
+// RepositoryRuleset, sharing rule and bypass actor conversion with repository rulesets.
+func buildOrgRulesetFromConfig(
+	rulesetConfig configtypes.OrgRulesetConfig,
+	existing *github.RepositoryRuleset,
+) *github.RepositoryRuleset {
+	ruleset := buildRulesetFromConfig(configtypes.RulesetConfig{
+		Name:         rulesetConfig.Name,
+		Target:       rulesetConfig.Target,
+		Enforcement:  rulesetConfig.Enforcement,
+		BypassActors: rulesetConfig.BypassActors,
+		Rules:        rulesetConfig.Rules,
+	}, existing)
+
+	if rulesetConfig.Conditions != nil {
+		ruleset.Conditions = buildOrgConditions(rulesetConfig.Conditions)
+	}
+
+	return ruleset
+}
+
+// buildOrgConditions converts organization ruleset conditions to go-github
+// RepositoryRulesetConditions.
+func buildOrgConditions(
+	conditionsConfig *configtypes.OrgRulesetConditionsConfig,
+) *github.RepositoryRulesetConditions {
+	conditions := buildConditions(&configtypes.RulesetConditionsConfig{
+		RefName: conditionsConfig.RefName,
+	})
+
+	if name := conditionsConfig.RepositoryName; name != nil {
+		conditions.RepositoryName = &github.RepositoryRulesetRepositoryNamesConditionParameters{
+			Include:   nonNilStrings(name.Include),
+			Exclude:   nonNilStrings(name.Exclude),
+			Protected: name.Protected,
+		}
+	}
+
+	if property := conditionsConfig.RepositoryProperty; property != nil {
+		params := &github.RepositoryRulesetRepositoryPropertyConditionParameters{
+			Include: buildPropertyTargets(property.Include),
+			Exclude: buildPropertyTargets(property.Exclude),
+		}
+
+		conditions.RepositoryProperty = params
+	}
+
+	return conditions
+}
+
+// buildPropertyTargets converts custom property targets, never returning nil since
+// the API rejects null arrays.
+func buildPropertyTargets(
+	targets []configtypes.RepositoryPropertyTarget,
+) []*github.RepositoryRulesetRepositoryPropertyTargetParameters {
+	params := make([]*github.RepositoryRulesetRepositoryPropertyTargetParameters, 0, len(targets))
+
+	for _, target := range targets {
+		param := &github.RepositoryRulesetRepositoryPropertyTargetParameters{
+			Name:           target.Name,
+			PropertyValues: nonNilStrings(target.PropertyValues),
+		}
+
+		if target.Source != "" {
+			param.Source = github.Ptr(target.Source)
+		}
+
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// nonNilStrings returns values, or an empty slice when values is nil.
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestSyncOrgRulesets(t *testing.T) {
+	t.Parallel()
+
+	settings := `settings:
+  org_rulesets:
+    - name: org-sync/production
+      target: branch
+      enforcement: active
+      conditions:
+        ref_name:
+          include: ["~DEFAULT_BRANCH"]
+        repository_property:
+          include:
+            - name: tier
+              property_values: [production]
+      rules:
+        deletion: true
+    - name: org-sync/all-repos
+      target: branch
+      enforcement: evaluate
+      conditions:
+        ref_name:
+          include: ["~ALL"]
+        repository_name:
+          include: ["*"]
+          exclude: [".github"]
+`
+
+	settingsFile := filepath.Join(t.TempDir(), "settings.yml")
+	if err := os.WriteFile(settingsFile, []byte(settings), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /orgs/org/rulesets": `[{"id":1,"name":"org-sync/production",` +
+			`"source_type":"Organization"}]`,
+		"GET /orgs/org/rulesets/1": `{"id":1,"name":"org-sync/production","target":"branch",` +
+			`"enforcement":"active","conditions":{` +
+			`"ref_name":{"include":["~DEFAULT_BRANCH"],"exclude":[]},` +
+			`"repository_property":{"include":[{"name":"tier","property_values":["staging"]}],` +
+			`"exclude":[]}},"rules":[{"type":"deletion"}]}`,
+		"PUT /orgs/org/rulesets/1": `{"id":1}`,
+		"POST /orgs/org/rulesets":  `{"id":2}`,
+	})
+
+	result, err := SyncOrgRulesets(
+		context.Background(), logger.New("error"), client, "org", settingsFile, false, false, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncOrgRulesets() unexpected error: %v", err)
+	}
+
+	want := []RulesetOutcome{
+		{
+			Name:   "org-sync/production",
+			ID:     1,
+			Action: ResourceActionUpdated,
+			Fields: []FieldDiff{{
+				Field:   "conditions.repository_property.include",
+				Current: []string{"custom:tier=staging"},
+				Desired: []string{"custom:tier=production"},
+			}},
+		},
+		{Name: "org-sync/all-repos", ID: 2, Action: ResourceActionCreated},
+	}
+
+	if diff := cmp.Diff(want, result.Rulesets); diff != "" {
+		t.Errorf("SyncOrgRulesets() outcomes mismatch (-want +got):\n%s", diff)
+	}
+
+	if result.ChangesApplied != 2 {
+		t.Errorf("ChangesApplied = %d, want 2", result.ChangesApplied)
+	}
+
+	created := fake.body("POST /orgs/org/rulesets")
+	if !strings.Contains(created, `"repository_name":{"include":["*"]`) {
+		t.Errorf("created ruleset lacks repository_name condition: %s", created)
+	}
+}
+
+func TestSyncOrgRulesetsWeakeningPolicy(t *testing.T) {
+	t.Parallel()
+
+	settingsFile := filepath.Join(t.TempDir(), "settings.yml")
+	if err := os.WriteFile(settingsFile, []byte("settings: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		allowWeakening bool
+		wantAction     ResourceAction
+		wantBlocked    []BlockedChange
+	}{
+		{
+			name:       "removal blocked",
+			wantAction: ResourceActionUnchanged,
+			wantBlocked: []BlockedChange{{
+				Section:   "rulesets",
+				Target:    "org-sync/legacy",
+				FieldDiff: FieldDiff{Field: deletedField, Current: false, Desired: true},
+			}},
+		},
+		{
+			name:           "removal allowed",
+			allowWeakening: true,
+			wantAction:     ResourceActionDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /orgs/org/rulesets": `[{"id":3,"name":"org-sync/legacy",` +
+					`"source_type":"Organization"}]`,
+				"DELETE /orgs/org/rulesets/3": `{}`,
+			})
+
+			result, err := SyncOrgRulesets(
+				context.Background(), logger.New("error"), client, "org", settingsFile,
+				true, tt.allowWeakening, false,
+			)
+			if err != nil {
+				t.Fatalf("SyncOrgRulesets() unexpected error: %v", err)
+			}
+
+			want := []RulesetOutcome{{Name: "org-sync/legacy", ID: 3, Action: tt.wantAction}}
+			if diff := cmp.Diff(want, result.Rulesets); diff != "" {
+				t.Errorf("SyncOrgRulesets() outcomes mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantBlocked, result.Blocked); diff != "" {
+				t.Errorf("SyncOrgRulesets() blocked mismatch (-want +got):\n%s", diff)
+			}
+
+			if deleted := fake.called("DELETE /orgs/org/rulesets/3"); deleted != tt.allowWeakening {
+				t.Errorf("ruleset deleted = %v, want %v", deleted, tt.allowWeakening)
+			}
+		})
+	}
+}
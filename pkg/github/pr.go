@@ -0,0 +1,490 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const (
+	// destructiveLabel marks sync PRs that delete files and need manual review.
+	destructiveLabel = "review/destructive"
+	// conflictLabel marks sync PRs whose branch has commits not created by sync.
+	conflictLabel = "sync/conflict"
+	// closeLabelPrefix prefixes the label recording why a sync PR was closed.
+	closeLabelPrefix = "sync/"
+
+	// mergeableStateDirty is the mergeable state of PRs conflicting with their base.
+	mergeableStateDirty = "dirty"
+)
+
+// PRCloseReason describes why a sync PR is closed. It is recorded as a
+// "sync/<reason>" label on the closed PR.
+type PRCloseReason string
+
+// Reasons for closing sync PRs.
+const (
+	// PRCloseReasonDisabled is used when sync is disabled by config.
+	PRCloseReasonDisabled PRCloseReason = "disabled"
+	// PRCloseReasonInSync is used when the repository no longer needs changes.
+	PRCloseReasonInSync PRCloseReason = "in-sync"
+	// PRCloseReasonSuperseded is used when another sync PR carries the changes.
+	PRCloseReasonSuperseded PRCloseReason = "superseded"
+)
+
+// ErrSyncBranchModified is returned when a sync branch has commits that were not
+// created by sync and would be lost by rebuilding the branch.
+var ErrSyncBranchModified = errors.New("sync branch has commits not created by sync")
+
+// pullRequestSpec describes a sync pull request to create or update.
+type pullRequestSpec struct {
+	Branch      string
+	Base        string
+	Title       string
+	Body        string
+	Labels      []string
+	MergeMethod string
+	// AutoMergeSkipReason disables auto-merge when set.
+	AutoMergeSkipReason string
+}
+
+// publishedPR describes a created or updated sync PR.
+type publishedPR struct {
+	Number    int
+	URL       string
+	AutoMerge *AutoMergeResult
+}
+
+// PRManager manages the lifecycle of sync pull requests in a repository:
+// branch naming, stale-branch cleanup, conflict detection, auto-merge,
+// labeling and closing. All PR-based syncs build on it.
+type PRManager struct {
+	log    *logger.Logger
+	client *Client
+	org    string
+	repo   string
+}
+
+// NewPRManager creates a PRManager for a repository.
+func NewPRManager(log *logger.Logger, client *Client, org string, repo string) *PRManager {
+	return &PRManager{
+		log:    log,
+		client: client,
+		org:    org,
+		repo:   repo,
+	}
+}
+
+// BranchName returns the per-repository sync branch for a prefix.
+func (m *PRManager) BranchName(prefix string) string {
+	return getBranchName(m.repo, prefix)
+}
+
+// Publish rebuilds the spec branch from baseSHA with the commit groups and
+// creates or updates its pull request. Branches with commits not created by
+// sync are left untouched and ErrSyncBranchModified is returned.
+func (m *PRManager) Publish(
+	ctx context.Context,
+	baseSHA string,
+	spec *pullRequestSpec,
+	groups []commitGroup,
+	commitOpts *CommitOptions,
+) (*publishedPR, error) {
+	m.log.Info("creating/updating PR", "branch", spec.Branch)
+
+	existing, err := m.FindOpen(ctx, spec.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure branch exists
+	if err := m.ensureBranch(ctx, spec.Branch, baseSHA, existing); err != nil {
+		return nil, err
+	}
+
+	if existing.GetMergeableState() == mergeableStateDirty {
+		m.log.Info("existing PR conflicts with base, rebuilding branch", "pr", existing.GetNumber())
+	}
+
+	// Create Git commit
+	err = createGitCommit(
+		ctx, m.log, m.client, m.org, m.repo, spec.Branch, baseSHA, groups, commitOpts,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating Git commit")
+	}
+
+	// Create or update pull request
+	pr, err := m.upsert(ctx, spec, existing)
+	if err != nil {
+		return nil, errors.Wrap(err, "upserting pull request")
+	}
+
+	// Add labels and enable auto-merge
+	pr.AutoMerge = m.finalize(ctx, pr.Number, spec)
+
+	return pr, nil
+}
+
+// FindOpen returns the open PR for a branch, or nil if there is none.
+func (m *PRManager) FindOpen(ctx context.Context, branchName string) (*github.PullRequest, error) {
+	prs, _, err := m.client.PullRequests.List(ctx, m.org, m.repo, &github.PullRequestListOptions{
+		State: "open",
+		Head:  m.org + ":" + branchName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing PRs")
+	}
+
+	if len(prs) == 0 {
+		return nil, nil //nolint:nilnil // no open PR is not an error
+	}
+
+	return prs[0], nil
+}
+
+// Close closes the open PR for a branch with a comment and a label recording
+// the reason, then deletes the branch. Returns the number of the closed PR, or
+// 0 if there was none.
+func (m *PRManager) Close(
+	ctx context.Context,
+	branchName string,
+	reason PRCloseReason,
+	comment string,
+) (int, error) {
+	existing, err := m.FindOpen(ctx, branchName)
+	if err != nil || existing == nil {
+		return 0, err
+	}
+
+	prNumber := existing.GetNumber()
+	m.log.Info("closing existing PR", "pr", prNumber, "reason", reason)
+
+	// Close the PR
+	pr := &github.PullRequest{
+		State: github.Ptr("closed"),
+	}
+
+	_, _, err = m.client.PullRequests.Edit(ctx, m.org, m.repo, prNumber, pr)
+	if err != nil {
+		return 0, errors.Wrap(err, "closing PR")
+	}
+
+	// Add comment
+	m.comment(ctx, prNumber, comment)
+
+	// Record the reason
+	m.addLabels(ctx, prNumber, []string{closeLabelPrefix + string(reason)})
+
+	if err := m.deleteBranch(ctx, branchName); err != nil {
+		m.log.Warn("failed to delete branch of closed PR", "error", err)
+	}
+
+	return prNumber, nil
+}
+
+// CleanupStaleBranch deletes a branch without an open PR whose last PR was
+// merged. Branches of PRs closed without merging are kept, since they may carry
+// commits pushed after closing. Returns whether the branch was deleted.
+func (m *PRManager) CleanupStaleBranch(ctx context.Context, branchName string) (bool, error) {
+	prs, _, err := m.client.PullRequests.List(ctx, m.org, m.repo, &github.PullRequestListOptions{
+		State: "closed",
+		Head:  m.org + ":" + branchName,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "listing closed PRs")
+	}
+
+	// The list endpoint does not report merged, only the merge time
+	if len(prs) == 0 || prs[0].MergedAt == nil {
+		return false, nil
+	}
+
+	m.log.Info("deleting branch of merged PR",
+		"branch", branchName,
+		"pr", prs[0].GetNumber(),
+	)
+
+	if err := m.deleteBranch(ctx, branchName); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ensureBranch creates the branch from baseSHA unless it exists. Branches of
+// merged PRs are deleted first and remaining branches are checked for commits
+// not created by sync.
+func (m *PRManager) ensureBranch(
+	ctx context.Context,
+	branchName string,
+	baseSHA string,
+	existing *github.PullRequest,
+) error {
+	// Check if branch exists
+	branchExists := m.branchExists(ctx, branchName)
+
+	// If branch exists without an open PR, clean it up when its PR was merged
+	if branchExists && existing == nil {
+		deleted, err := m.CleanupStaleBranch(ctx, branchName)
+		if err != nil {
+			m.log.Warn("failed to clean up stale branch", "error", err)
+		}
+
+		branchExists = !deleted
+	}
+
+	if branchExists {
+		return m.checkConflict(ctx, branchName, baseSHA, existing)
+	}
+
+	// Create branch
+	m.log.Debug("creating branch", "branch", branchName)
+
+	ref := github.CreateRef{
+		Ref: "refs/heads/" + branchName,
+		SHA: baseSHA,
+	}
+
+	if _, _, err := m.client.Git.CreateRef(ctx, m.org, m.repo, ref); err != nil {
+		return errors.Wrap(err, "creating branch")
+	}
+
+	return nil
+}
+
+// branchExists reports whether a branch exists.
+func (m *PRManager) branchExists(ctx context.Context, branchName string) bool {
+	ref, _, err := m.client.Git.GetRef(ctx, m.org, m.repo, "heads/"+branchName)
+
+	return err == nil && ref != nil
+}
+
+// checkConflict returns ErrSyncBranchModified when the branch has commits not
+// created by sync, flagging the open PR for review once.
+func (m *PRManager) checkConflict(
+	ctx context.Context,
+	branchName string,
+	baseSHA string,
+	existing *github.PullRequest,
+) error {
+	foreign, err := m.foreignCommits(ctx, branchName, baseSHA)
+	if err != nil {
+		return err
+	}
+
+	if len(foreign) == 0 {
+		return nil
+	}
+
+	m.log.Warn("sync branch has manual commits, not overwriting",
+		"branch", branchName,
+		"commits", foreign,
+	)
+
+	if existing != nil && !hasLabel(existing, conflictLabel) {
+		m.addLabels(ctx, existing.GetNumber(), []string{conflictLabel})
+		m.comment(ctx, existing.GetNumber(), fmt.Sprintf(
+			"Sync paused: this branch has commits not created by sync (%s). "+
+				"Merge or close this PR, or remove the `%s` label after dropping those commits.",
+			strings.Join(foreign, ", "), conflictLabel,
+		))
+	}
+
+	return errors.Wrapf(
+		ErrSyncBranchModified,
+		"branch %s (%s)",
+		branchName,
+		strings.Join(foreign, ", "),
+	)
+}
+
+// foreignCommits returns short SHAs of branch commits not created by sync. Merges
+// of the base branch into sync commits, as made by the "Update branch" button, only
+// bring in base commits and are not foreign.
+func (m *PRManager) foreignCommits(
+	ctx context.Context,
+	branchName string,
+	baseSHA string,
+) ([]string, error) {
+	comparison, _, err := m.client.Repositories.CompareCommits(
+		ctx, m.org, m.repo, baseSHA, branchName, nil,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "comparing sync branch with base")
+	}
+
+	// Commits are listed oldest first; parents outside the list are reachable from base
+	onBranch := make(map[string]bool, len(comparison.Commits))
+	synced := make(map[string]bool, len(comparison.Commits))
+
+	for _, commit := range comparison.Commits {
+		onBranch[commit.GetSHA()] = true
+	}
+
+	var foreign []string
+
+	for _, commit := range comparison.Commits {
+		if strings.HasPrefix(commit.GetCommit().GetMessage(), syncCommitPrefix) ||
+			isBaseMerge(commit.Parents, onBranch, synced) {
+			synced[commit.GetSHA()] = true
+
+			continue
+		}
+
+		foreign = append(foreign, shortSHA(commit.GetSHA()))
+	}
+
+	return foreign, nil
+}
+
+// isBaseMerge reports whether a commit merges a commit reachable from base into a
+// sync commit.
+func isBaseMerge(parents []*github.Commit, onBranch, synced map[string]bool) bool {
+	if len(parents) != 2 {
+		return false
+	}
+
+	first, second := parents[0].GetSHA(), parents[1].GetSHA()
+
+	return synced[first] && !onBranch[second] || synced[second] && !onBranch[first]
+}
+
+// upsert creates the spec PR or updates the existing one.
+func (m *PRManager) upsert(
+	ctx context.Context,
+	spec *pullRequestSpec,
+	existing *github.PullRequest,
+) (*publishedPR, error) {
+	if existing != nil {
+		// Update existing PR
+		prNumber := existing.GetNumber()
+
+		m.log.Info("updating existing PR", "pr", prNumber)
+
+		pr := &github.PullRequest{
+			Title: github.Ptr(spec.Title),
+			Body:  github.Ptr(spec.Body),
+		}
+
+		if _, _, err := m.client.PullRequests.Edit(ctx, m.org, m.repo, prNumber, pr); err != nil {
+			return nil, errors.Wrap(err, "updating PR")
+		}
+
+		return &publishedPR{Number: prNumber, URL: existing.GetHTMLURL()}, nil
+	}
+
+	// Create new PR
+	m.log.Info("creating new PR")
+
+	pr := &github.NewPullRequest{
+		Title: github.Ptr(spec.Title),
+		Head:  github.Ptr(spec.Branch),
+		Base:  github.Ptr(spec.Base),
+		Body:  github.Ptr(spec.Body),
+	}
+
+	createdPR, _, err := m.client.PullRequests.Create(ctx, m.org, m.repo, pr)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating PR")
+	}
+
+	m.log.Info("created PR", "pr", createdPR.GetNumber(), "url", createdPR.GetHTMLURL())
+
+	return &publishedPR{Number: createdPR.GetNumber(), URL: createdPR.GetHTMLURL()}, nil
+}
+
+// finalize adds labels and enables auto-merge for a PR, returning the auto-merge outcome.
+func (m *PRManager) finalize(
+	ctx context.Context,
+	prNumber int,
+	spec *pullRequestSpec,
+) *AutoMergeResult {
+	m.addLabels(ctx, prNumber, spec.Labels)
+
+	if spec.AutoMergeSkipReason != "" {
+		m.log.Info("skipping auto-merge", "reason", spec.AutoMergeSkipReason)
+
+		return &AutoMergeResult{
+			Route:  AutoMergeRouteSkipped,
+			Reason: spec.AutoMergeSkipReason,
+		}
+	}
+
+	autoMerge, err := enableAutoMerge(
+		ctx, m.log, m.client, m.org, m.repo, prNumber, spec.MergeMethod,
+	)
+	if err != nil {
+		m.log.Warn("failed to enable auto-merge", "error", err)
+	}
+
+	return autoMerge
+}
+
+// addLabels adds labels to a PR, logging failures.
+func (m *PRManager) addLabels(ctx context.Context, prNumber int, labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	_, _, err := m.client.Issues.AddLabelsToIssue(ctx, m.org, m.repo, prNumber, labels)
+	if err != nil {
+		m.log.Warn("failed to add labels to PR", "error", err)
+	}
+}
+
+// comment adds a comment to a PR, logging failures.
+func (m *PRManager) comment(ctx context.Context, prNumber int, body string) {
+	prComment := &github.IssueComment{
+		Body: github.Ptr(body),
+	}
+
+	_, _, err := m.client.Issues.CreateComment(ctx, m.org, m.repo, prNumber, prComment)
+	if err != nil {
+		m.log.Warn("failed to add PR comment", "error", err)
+	}
+}
+
+// deleteBranch deletes a branch.
+func (m *PRManager) deleteBranch(ctx context.Context, branchName string) error {
+	if _, err := m.client.Git.DeleteRef(ctx, m.org, m.repo, "heads/"+branchName); err != nil {
+		return errors.Wrap(err, "deleting branch")
+	}
+
+	return nil
+}
+
+// hasLabel reports whether a PR has a label.
+func hasLabel(pr *github.PullRequest, name string) bool {
+	for _, label := range pr.Labels {
+		if label.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shortSHA returns the abbreviated form of a commit SHA.
+func shortSHA(sha string) string {
+	const shortSHALength = 7
+
+	if len(sha) <= shortSHALength {
+		return sha
+	}
+
+	return sha[:shortSHALength]
+}
+
+// getBranchName generates the branch name from repo name and prefix.
+func getBranchName(repo string, branchPrefix string) string {
+	// Strip leading dot from repo name
+	repoSanitized := strings.TrimPrefix(repo, ".")
+
+	return fmt.Sprintf("%s/%s", branchPrefix, repoSanitized)
+}
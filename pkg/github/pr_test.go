@@ -0,0 +1,245 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestPRManagerClose(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/pulls":                                 `[{"number":7}]`,
+		"PATCH /repos/org/repo/pulls/7":                             `{"number":7,"state":"closed"}`,
+		"POST /repos/org/repo/issues/7/comments":                    `{}`,
+		"POST /repos/org/repo/issues/7/labels":                      `[]`,
+		"DELETE /repos/org/repo/git/refs/heads/chore/org-sync/repo": ``,
+	})
+
+	manager := NewPRManager(logger.New("error"), client, "org", "repo")
+
+	closed, err := manager.Close(
+		context.Background(), manager.BranchName("chore/org-sync"), PRCloseReasonInSync, "in sync",
+	)
+	if err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	if closed != 7 {
+		t.Errorf("Close() = %d, want 7", closed)
+	}
+
+	var labels []string
+	if err := json.Unmarshal([]byte(fake.body("POST /repos/org/repo/issues/7/labels")), &labels); err != nil {
+		t.Fatalf("decoding labels: %v", err)
+	}
+
+	if !slices.Equal(labels, []string{"sync/in-sync"}) {
+		t.Errorf("close labels = %q, want [sync/in-sync]", labels)
+	}
+
+	if !strings.Contains(fake.body("POST /repos/org/repo/issues/7/comments"), "in sync") {
+		t.Error("Close() did not comment with the reason")
+	}
+
+	if !fake.called("DELETE /repos/org/repo/git/refs/heads/chore/org-sync/repo") {
+		t.Error("Close() did not delete the branch")
+	}
+}
+
+func TestPRManagerPublishDetectsManualCommits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		labels       string
+		wantFlagged  bool
+		wantComments bool
+	}{
+		{
+			name:         "flags PR once",
+			labels:       `[]`,
+			wantFlagged:  true,
+			wantComments: true,
+		},
+		{
+			name:   "already flagged",
+			labels: `[{"name":"sync/conflict"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/pulls":                             `[{"number":3,"labels":` + tt.labels + `}]`,
+				"GET /repos/org/repo/git/ref/heads/chore/org-sync/repo": `{"ref":"refs/heads/chore/org-sync/repo"}`,
+				"GET /repos/org/repo/compare/base-sha...chore/org-sync/repo": `{"commits":[
+					{"sha":"1111111aaaa","commit":{"message":"chore(sync): sync organization files"}},
+					{"sha":"2222222bbbb","commit":{"message":"fix: adjust renovate schedule"}}
+				]}`,
+				"POST /repos/org/repo/issues/3/labels":   `[]`,
+				"POST /repos/org/repo/issues/3/comments": `{}`,
+			})
+
+			manager := NewPRManager(logger.New("error"), client, "org", "repo")
+			spec := &pullRequestSpec{
+				Branch: manager.BranchName("chore/org-sync"),
+				Base:   "main",
+				Title:  filesCommitMessage,
+			}
+
+			_, err := manager.Publish(context.Background(), "base-sha", spec, nil, nil)
+			if !errors.Is(err, ErrSyncBranchModified) {
+				t.Fatalf("Publish() error = %v, want ErrSyncBranchModified", err)
+			}
+
+			if !strings.Contains(err.Error(), "2222222") {
+				t.Errorf("Publish() error %q does not name the manual commit", err)
+			}
+
+			if got := fake.called("POST /repos/org/repo/issues/3/labels"); got != tt.wantFlagged {
+				t.Errorf("conflict label added = %v, want %v", got, tt.wantFlagged)
+			}
+
+			if got := fake.called("POST /repos/org/repo/issues/3/comments"); got != tt.wantComments {
+				t.Errorf("conflict comment added = %v, want %v", got, tt.wantComments)
+			}
+
+			if fake.called("PATCH /repos/org/repo/git/refs/heads/chore/org-sync/repo") {
+				t.Error("Publish() must not overwrite a branch with manual commits")
+			}
+		})
+	}
+}
+
+func TestPRManagerEnsureBranchAfterClosedPR(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		closedPRs   string
+		wantDeleted bool
+		wantErr     error
+	}{
+		{
+			name:        "merged PR",
+			closedPRs:   `[{"number":5,"state":"closed","merged_at":"2026-10-01T00:00:00Z"}]`,
+			wantDeleted: true,
+		},
+		{
+			name:      "PR closed without merging",
+			closedPRs: `[{"number":5,"state":"closed"}]`,
+			wantErr:   ErrSyncBranchModified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/pulls":                             tt.closedPRs,
+				"GET /repos/org/repo/git/ref/heads/chore/org-sync/repo": `{"ref":"refs/heads/chore/org-sync/repo"}`,
+				"GET /repos/org/repo/compare/base-sha...chore/org-sync/repo": `{"commits":[
+					{"sha":"2222222bbbb","commit":{"message":"fix: adjust renovate schedule"}}
+				]}`,
+				"DELETE /repos/org/repo/git/refs/heads/chore/org-sync/repo": ``,
+				"POST /repos/org/repo/git/refs":                             `{"ref":"refs/heads/chore/org-sync/repo"}`,
+			})
+
+			manager := NewPRManager(logger.New("error"), client, "org", "repo")
+
+			err := manager.ensureBranch(
+				context.Background(), manager.BranchName("chore/org-sync"), "base-sha", nil,
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ensureBranch() error = %v, want %v", err, tt.wantErr)
+			}
+
+			deleted := fake.called("DELETE /repos/org/repo/git/refs/heads/chore/org-sync/repo")
+			if deleted != tt.wantDeleted {
+				t.Errorf("branch deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+
+			if created := fake.called("POST /repos/org/repo/git/refs"); created != tt.wantDeleted {
+				t.Errorf("branch recreated = %v, want %v", created, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestPRManagerForeignCommits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		commits string
+		want    []string
+	}{
+		{
+			name: "update branch merge",
+			commits: `[
+				{"sha":"1111111aaaa","commit":{"message":"chore(sync): sync organization files"},
+					"parents":[{"sha":"base-sha"}]},
+				{"sha":"2222222bbbb","commit":{"message":"Merge branch 'main'"},
+					"parents":[{"sha":"1111111aaaa"},{"sha":"main-sha"}]}
+			]`,
+		},
+		{
+			name: "merge into a manual commit",
+			commits: `[
+				{"sha":"1111111aaaa","commit":{"message":"fix: adjust renovate schedule"},
+					"parents":[{"sha":"base-sha"}]},
+				{"sha":"2222222bbbb","commit":{"message":"Merge branch 'main'"},
+					"parents":[{"sha":"1111111aaaa"},{"sha":"main-sha"}]}
+			]`,
+			want: []string{"1111111", "2222222"},
+		},
+		{
+			name: "manual commit after merge",
+			commits: `[
+				{"sha":"1111111aaaa","commit":{"message":"chore(sync): sync organization files"},
+					"parents":[{"sha":"base-sha"}]},
+				{"sha":"2222222bbbb","commit":{"message":"Merge branch 'main'"},
+					"parents":[{"sha":"1111111aaaa"},{"sha":"main-sha"}]},
+				{"sha":"3333333cccc","commit":{"message":"fix: adjust renovate schedule"},
+					"parents":[{"sha":"2222222bbbb"}]}
+			]`,
+			want: []string{"3333333"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/compare/base-sha...chore/org-sync/repo": `{"commits":` +
+					tt.commits + `}`,
+			})
+
+			manager := NewPRManager(logger.New("error"), client, "org", "repo")
+
+			got, err := manager.foreignCommits(
+				context.Background(), manager.BranchName("chore/org-sync"), "base-sha",
+			)
+			if err != nil {
+				t.Fatalf("foreignCommits() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("foreignCommits() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,426 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// migratedRulesetPrefix names rulesets converted from branch protection rules.
+const migratedRulesetPrefix = "branch-protection/"
+
+// Suffixes of the rulesets carrying rules whose bypass is narrower than the whole
+// branch protection rule.
+const (
+	migratedReviewsSuffix = "/reviews"
+	migratedPushSuffix    = "/push"
+)
+
+// deleteBranchProtectionRuleMutation removes a pattern branch protection rule.
+const deleteBranchProtectionRuleMutation = `
+mutation($input: DeleteBranchProtectionRuleInput!) {
+  deleteBranchProtectionRule(input: $input) {
+    clientMutationId
+  }
+}`
+
+// MigrationStatus is the stage a branch protection rule reached in its migration to
+// rulesets.
+type MigrationStatus string
+
+const (
+	// MigrationConverted means the rule was converted without touching the repository.
+	MigrationConverted MigrationStatus = "converted"
+	// MigrationRulesetsCreated means the rulesets were created in evaluate enforcement.
+	MigrationRulesetsCreated MigrationStatus = "rulesets-created"
+	// MigrationEvaluating means the rulesets exist but are not all active yet.
+	MigrationEvaluating MigrationStatus = "evaluating"
+	// MigrationProtectionRemoved means the rulesets are active and the branch
+	// protection rule was removed.
+	MigrationProtectionRemoved MigrationStatus = "protection-removed"
+	// MigrationBlocked means the rulesets are active but the branch protection rule is
+	// kept because some of its settings have no ruleset equivalent, or because the
+	// settings file still manages it.
+	MigrationBlocked MigrationStatus = "blocked"
+)
+
+// ProtectionMigration is a branch protection rule converted to rulesets.
+type ProtectionMigration struct {
+	Pattern  string
+	Rulesets []configtypes.RulesetConfig
+	// Unsupported describes settings of the rule that the rulesets cannot express.
+	Unsupported []string
+	Status      MigrationStatus
+}
+
+// ConvertBranchProtectionRule converts a branch protection rule into rulesets in
+// evaluate enforcement. Ruleset bypass actors skip every rule of a ruleset, so pull
+// request bypass allowances and push restrictions get rulesets of their own; the
+// rest of the rule lands in a single ruleset. Users named in allowances cannot be
+// ruleset bypass actors and are reported as unsupported, as is an empty status check
+// list, which inherits the checks of the branch.
+func ConvertBranchProtectionRule(rule configtypes.BranchProtectionRuleConfig) ProtectionMigration {
+	migration := ProtectionMigration{Pattern: rule.Pattern, Status: MigrationConverted}
+
+	// Branch protection applies to administrators only when enforced
+	var adminBypass []configtypes.BypassActorConfig
+	if !getBoolValue(rule.EnforceAdmins) {
+		adminBypass = []configtypes.BypassActorConfig{
+			{Role: "admin", BypassMode: string(github.BypassModeAlways)},
+		}
+	}
+
+	name := migratedRulesetPrefix + rule.Pattern
+	rules := &configtypes.RulesetRulesConfig{}
+
+	if checks := rule.RequiredStatusChecks; checks != nil {
+		if len(checks.Contexts) == 0 {
+			migration.Unsupported = append(migration.Unsupported,
+				"required_status_checks.contexts: an empty list inherits the checks of "+
+					"the branch, list them explicitly")
+		} else {
+			statusChecks := make([]configtypes.StatusCheckConfig, 0, len(checks.Contexts))
+			for _, check := range checks.Contexts {
+				statusChecks = append(statusChecks, configtypes.StatusCheckConfig{Context: check})
+			}
+
+			rules.RequiredStatusChecks = &configtypes.StatusChecksRuleConfig{
+				StrictRequiredStatusChecksPolicy: checks.Strict,
+				RequiredStatusChecks:             statusChecks,
+			}
+		}
+	}
+
+	if getBoolValue(rule.RequireLinearHistory) {
+		rules.RequiredLinearHistory = github.Ptr(true)
+	}
+
+	if !getBoolValue(rule.AllowForcePushes) {
+		rules.NonFastForward = github.Ptr(true)
+	}
+
+	if !getBoolValue(rule.AllowDeletions) {
+		rules.Deletion = github.Ptr(true)
+	}
+
+	var reviewsRuleset *configtypes.RulesetConfig
+
+	if reviews := rule.RequiredReviews; reviews != nil {
+		pullRequest := &configtypes.PullRequestRuleConfig{
+			RequiredApprovingReviewCount:   reviews.RequiredApprovingReviewCount,
+			DismissStaleReviewsOnPush:      reviews.DismissStaleReviews,
+			RequireCodeOwnerReview:         reviews.RequireCodeOwnerReviews,
+			RequireLastPushApproval:        reviews.RequireLastPushApproval,
+			RequiredReviewThreadResolution: rule.RequiredConversationResolution,
+		}
+
+		var bypass []configtypes.BypassActorConfig
+
+		if allowances := reviews.BypassPullRequestAllowances; allowances != nil {
+			bypass = migratedBypassActors(allowances.Teams, allowances.Apps)
+			migration.Unsupported = append(migration.Unsupported, unsupportedUsers(
+				"required_reviews.bypass_pull_request_allowances.users", allowances.Users)...)
+		}
+
+		if len(bypass) == 0 {
+			rules.PullRequest = pullRequest
+		} else {
+			reviewsRuleset = &configtypes.RulesetConfig{
+				Name:         name + migratedReviewsSuffix,
+				BypassActors: slices.Concat(adminBypass, bypass),
+				Rules:        &configtypes.RulesetRulesConfig{PullRequest: pullRequest},
+			}
+		}
+	} else if getBoolValue(rule.RequiredConversationResolution) {
+		migration.Unsupported = append(migration.Unsupported,
+			"required_conversation_resolution: rulesets require resolved conversations "+
+				"only together with pull request reviews")
+	}
+
+	// The main ruleset is left out when all of its rules moved to the other rulesets
+	if *rules != (configtypes.RulesetRulesConfig{}) ||
+		(reviewsRuleset == nil && rule.Restrictions == nil) {
+		migration.Rulesets = append(migration.Rulesets, configtypes.RulesetConfig{
+			Name:         name,
+			BypassActors: adminBypass,
+			Rules:        rules,
+		})
+	}
+
+	if reviewsRuleset != nil {
+		migration.Rulesets = append(migration.Rulesets, *reviewsRuleset)
+	}
+
+	if restrictions := rule.Restrictions; restrictions != nil {
+		migration.Rulesets = append(migration.Rulesets, configtypes.RulesetConfig{
+			Name: name + migratedPushSuffix,
+			BypassActors: slices.Concat(
+				adminBypass,
+				migratedBypassActors(restrictions.Teams, restrictions.Apps),
+			),
+			Rules: &configtypes.RulesetRulesConfig{Update: github.Ptr(true)},
+		})
+
+		migration.Unsupported = append(migration.Unsupported,
+			unsupportedUsers("restrictions.users", restrictions.Users)...)
+	}
+
+	for i := range migration.Rulesets {
+		ruleset := &migration.Rulesets[i]
+		ruleset.Target = string(github.RulesetTargetBranch)
+		ruleset.Enforcement = string(github.RulesetEnforcementEvaluate)
+		ruleset.Conditions = &configtypes.RulesetConditionsConfig{
+			RefName: &configtypes.RefNameCondition{Include: []string{"refs/heads/" + rule.Pattern}},
+		}
+	}
+
+	return migration
+}
+
+// migratedBypassActors returns teams and apps as bypass actors that always bypass.
+func migratedBypassActors(teams []string, apps []string) []configtypes.BypassActorConfig {
+	actors := make([]configtypes.BypassActorConfig, 0, len(teams)+len(apps))
+
+	for _, team := range teams {
+		actors = append(actors, configtypes.BypassActorConfig{
+			Team:       team,
+			BypassMode: string(github.BypassModeAlways),
+		})
+	}
+
+	for _, app := range apps {
+		actors = append(actors, configtypes.BypassActorConfig{
+			App:        app,
+			BypassMode: string(github.BypassModeAlways),
+		})
+	}
+
+	return actors
+}
+
+// unsupportedUsers reports users of an allowance, which rulesets cannot exempt.
+func unsupportedUsers(field string, users []string) []string {
+	if len(users) == 0 {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s: rulesets cannot exempt individual users (%s)",
+		field, strings.Join(users, ", "))}
+}
+
+// ConvertSettingsFileProtection converts the branch protection rules of a settings
+// file into rulesets.
+func ConvertSettingsFileProtection(settingsFile string) ([]ProtectionMigration, error) {
+	settings, err := parseSettingsFile(settingsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing settings file")
+	}
+
+	migrations := make([]ProtectionMigration, 0, len(settings.BranchProtection))
+
+	for _, rule := range settings.BranchProtection {
+		migrations = append(migrations, ConvertBranchProtectionRule(rule))
+	}
+
+	return migrations, nil
+}
+
+// MigrateBranchProtection converts the branch protection rules of a repository into
+// rulesets. With apply set, the migration is advanced one stage per run: missing
+// rulesets are created in evaluate enforcement, and once all rulesets of a rule have
+// been promoted to active, the branch protection rule is removed. Rules with settings
+// the rulesets cannot express are never removed, and neither are rules whose pattern
+// is still listed under branch_protection in settingsFile, as the next settings sync
+// would recreate them. An empty settingsFile skips that check.
+func MigrateBranchProtection(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	settingsFile string,
+	apply bool,
+	dryRun bool,
+) ([]ProtectionMigration, error) {
+	managed, err := managedProtectionPatterns(settingsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	protection, err := fetchBranchProtectionRules(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching branch protection rules")
+	}
+
+	patterns := slices.Sorted(maps.Keys(protection.Rules))
+	migrations := make([]ProtectionMigration, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		migration := ConvertBranchProtectionRule(
+			exportBranchProtectionRule(protection.Rules[pattern]))
+		migrations = append(migrations, migration)
+	}
+
+	if !apply {
+		return migrations, nil
+	}
+
+	store := &repoRulesetStore{client: client, org: org, repo: repo}
+
+	existing, err := store.list(ctx)
+	if err != nil {
+		return migrations, errors.Wrap(err, "fetching existing rulesets")
+	}
+
+	enforcement := make(map[string]github.RulesetEnforcement, len(existing))
+	for _, ruleset := range existing {
+		enforcement[ruleset.Name] = ruleset.Enforcement
+	}
+
+	for i := range migrations {
+		migration := &migrations[i]
+		node := protection.Rules[migration.Pattern]
+
+		if err := advanceMigration(
+			ctx, log, client, org, store, node, migration, enforcement,
+			managed[migration.Pattern], dryRun,
+		); err != nil {
+			return migrations, errors.Wrapf(err, "migrating branch protection rule %q",
+				migration.Pattern)
+		}
+	}
+
+	return migrations, nil
+}
+
+// managedProtectionPatterns returns the branch protection patterns the settings file
+// manages.
+func managedProtectionPatterns(settingsFile string) (map[string]bool, error) {
+	if settingsFile == "" {
+		return map[string]bool{}, nil
+	}
+
+	settings, err := parseSettingsFile(settingsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing settings file")
+	}
+
+	managed := make(map[string]bool, len(settings.BranchProtection))
+	for _, rule := range settings.BranchProtection {
+		managed[rule.Pattern] = true
+	}
+
+	return managed, nil
+}
+
+// advanceMigration moves a single rule to its next migration stage and records the
+// stage reached in the migration. A rule the settings file still manages is never
+// removed.
+func advanceMigration(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	store rulesetStore,
+	node *branchProtectionRuleNode,
+	migration *ProtectionMigration,
+	enforcement map[string]github.RulesetEnforcement,
+	managed bool,
+	dryRun bool,
+) error {
+	var missing []configtypes.RulesetConfig
+
+	active := true
+
+	for _, ruleset := range migration.Rulesets {
+		current, ok := enforcement[ruleset.Name]
+		if !ok {
+			missing = append(missing, ruleset)
+		}
+
+		active = active && current == github.RulesetEnforcementActive
+	}
+
+	switch {
+	case len(missing) > 0:
+		migration.Status = MigrationRulesetsCreated
+
+		for _, ruleset := range missing {
+			if dryRun {
+				log.Info("would create ruleset", "name", ruleset.Name, "enforcement",
+					ruleset.Enforcement)
+
+				continue
+			}
+
+			actors, err := resolveBypassActors(ctx, client, org, ruleset.BypassActors)
+			if err != nil {
+				return err
+			}
+
+			ruleset.BypassActors = actors
+
+			id, err := store.create(ctx, buildRulesetFromConfig(ruleset, nil))
+			if err != nil {
+				return err
+			}
+
+			log.Info("created ruleset", "name", ruleset.Name, "id", id,
+				"enforcement", ruleset.Enforcement)
+		}
+	case !active:
+		migration.Status = MigrationEvaluating
+
+		log.Info("rulesets awaiting promotion to active", "pattern", migration.Pattern)
+	case len(migration.Unsupported) > 0:
+		migration.Status = MigrationBlocked
+
+		log.Warn("keeping branch protection rule with settings rulesets cannot express",
+			"pattern", migration.Pattern, "unsupported", migration.Unsupported)
+	case managed:
+		migration.Status = MigrationBlocked
+
+		log.Warn("keeping branch protection rule still managed by the settings file",
+			"pattern", migration.Pattern)
+	default:
+		migration.Status = MigrationProtectionRemoved
+
+		if dryRun {
+			log.Info("would remove branch protection rule", "pattern", migration.Pattern)
+
+			return nil
+		}
+
+		variables := map[string]any{
+			"input": map[string]any{"branchProtectionRuleId": node.ID},
+		}
+
+		err := executeGraphQL(ctx, client, deleteBranchProtectionRuleMutation, variables, nil)
+		if err != nil {
+			return errors.Wrap(err, "removing branch protection rule")
+		}
+
+		log.Info("removed branch protection rule", "pattern", migration.Pattern)
+	}
+
+	return nil
+}
+
+// MarshalMigratedRulesets returns the rulesets of the migrations as a settings file.
+func MarshalMigratedRulesets(migrations []ProtectionMigration) ([]byte, error) {
+	var rulesets []configtypes.RulesetConfig
+
+	for _, migration := range migrations {
+		rulesets = append(rulesets, migration.Rulesets...)
+	}
+
+	return marshalExportYAML(SettingsFile{Settings: SettingsDefinition{Rulesets: rulesets}})
+}
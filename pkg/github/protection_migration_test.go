@@ -0,0 +1,323 @@
+package github
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestConvertBranchProtectionRule(t *testing.T) {
+	t.Parallel()
+
+	mainConditions := &configtypes.RulesetConditionsConfig{
+		RefName: &configtypes.RefNameCondition{Include: []string{"refs/heads/main"}},
+	}
+	adminBypass := configtypes.BypassActorConfig{Role: "admin", BypassMode: "always"}
+
+	tests := []struct {
+		name string
+		rule configtypes.BranchProtectionRuleConfig
+		want ProtectionMigration
+	}{
+		{
+			name: "unset rule blocks force pushes and deletions",
+			rule: configtypes.BranchProtectionRuleConfig{Pattern: "main"},
+			want: ProtectionMigration{
+				Pattern: "main",
+				Status:  MigrationConverted,
+				Rulesets: []configtypes.RulesetConfig{
+					{
+						Name:         "branch-protection/main",
+						Target:       "branch",
+						Enforcement:  "evaluate",
+						Conditions:   mainConditions,
+						BypassActors: []configtypes.BypassActorConfig{adminBypass},
+						Rules: &configtypes.RulesetRulesConfig{
+							NonFastForward: github.Ptr(true),
+							Deletion:       github.Ptr(true),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "enforced rule with checks and reviews",
+			rule: configtypes.BranchProtectionRuleConfig{
+				Pattern:       "main",
+				EnforceAdmins: github.Ptr(true),
+				RequiredStatusChecks: &configtypes.RequiredStatusChecks{
+					Strict:   github.Ptr(true),
+					Contexts: []string{"test", "lint"},
+				},
+				RequiredReviews: &configtypes.RequiredReviews{
+					RequiredApprovingReviewCount: github.Ptr(2),
+					DismissStaleReviews:          github.Ptr(true),
+				},
+				RequireLinearHistory:           github.Ptr(true),
+				AllowForcePushes:               github.Ptr(true),
+				AllowDeletions:                 github.Ptr(true),
+				RequiredConversationResolution: github.Ptr(true),
+			},
+			want: ProtectionMigration{
+				Pattern: "main",
+				Status:  MigrationConverted,
+				Rulesets: []configtypes.RulesetConfig{
+					{
+						Name:        "branch-protection/main",
+						Target:      "branch",
+						Enforcement: "evaluate",
+						Conditions:  mainConditions,
+						Rules: &configtypes.RulesetRulesConfig{
+							PullRequest: &configtypes.PullRequestRuleConfig{
+								RequiredApprovingReviewCount:   github.Ptr(2),
+								DismissStaleReviewsOnPush:      github.Ptr(true),
+								RequiredReviewThreadResolution: github.Ptr(true),
+							},
+							RequiredStatusChecks: &configtypes.StatusChecksRuleConfig{
+								StrictRequiredStatusChecksPolicy: github.Ptr(true),
+								RequiredStatusChecks: []configtypes.StatusCheckConfig{
+									{Context: "test"},
+									{Context: "lint"},
+								},
+							},
+							RequiredLinearHistory: github.Ptr(true),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "bypass allowances and restrictions get their own rulesets",
+			rule: configtypes.BranchProtectionRuleConfig{
+				Pattern:          "main",
+				AllowForcePushes: github.Ptr(true),
+				AllowDeletions:   github.Ptr(true),
+				RequiredReviews: &configtypes.RequiredReviews{
+					RequiredApprovingReviewCount: github.Ptr(1),
+					BypassPullRequestAllowances: &configtypes.BypassPullRequestAllowances{
+						Users: []string{"octocat"},
+						Apps:  []string{"renovate"},
+					},
+				},
+				Restrictions: &configtypes.BranchRestrictionsConfig{
+					Teams: []string{"maintainers"},
+				},
+			},
+			want: ProtectionMigration{
+				Pattern: "main",
+				Status:  MigrationConverted,
+				Unsupported: []string{
+					"required_reviews.bypass_pull_request_allowances.users: " +
+						"rulesets cannot exempt individual users (octocat)",
+				},
+				Rulesets: []configtypes.RulesetConfig{
+					{
+						Name:        "branch-protection/main/reviews",
+						Target:      "branch",
+						Enforcement: "evaluate",
+						Conditions:  mainConditions,
+						BypassActors: []configtypes.BypassActorConfig{
+							adminBypass,
+							{App: "renovate", BypassMode: "always"},
+						},
+						Rules: &configtypes.RulesetRulesConfig{
+							PullRequest: &configtypes.PullRequestRuleConfig{
+								RequiredApprovingReviewCount: github.Ptr(1),
+							},
+						},
+					},
+					{
+						Name:        "branch-protection/main/push",
+						Target:      "branch",
+						Enforcement: "evaluate",
+						Conditions:  mainConditions,
+						BypassActors: []configtypes.BypassActorConfig{
+							adminBypass,
+							{Team: "maintainers", BypassMode: "always"},
+						},
+						Rules: &configtypes.RulesetRulesConfig{Update: github.Ptr(true)},
+					},
+				},
+			},
+		},
+		{
+			name: "inherited checks and conversation resolution are unsupported",
+			rule: configtypes.BranchProtectionRuleConfig{
+				Pattern:                        "release/*",
+				EnforceAdmins:                  github.Ptr(true),
+				RequiredStatusChecks:           &configtypes.RequiredStatusChecks{},
+				RequiredConversationResolution: github.Ptr(true),
+			},
+			want: ProtectionMigration{
+				Pattern: "release/*",
+				Status:  MigrationConverted,
+				Unsupported: []string{
+					"required_status_checks.contexts: an empty list inherits the checks of " +
+						"the branch, list them explicitly",
+					"required_conversation_resolution: rulesets require resolved " +
+						"conversations only together with pull request reviews",
+				},
+				Rulesets: []configtypes.RulesetConfig{
+					{
+						Name:        "branch-protection/release/*",
+						Target:      "branch",
+						Enforcement: "evaluate",
+						Conditions: &configtypes.RulesetConditionsConfig{
+							RefName: &configtypes.RefNameCondition{
+								Include: []string{"refs/heads/release/*"},
+							},
+						},
+						Rules: &configtypes.RulesetRulesConfig{
+							NonFastForward: github.Ptr(true),
+							Deletion:       github.Ptr(true),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ConvertBranchProtectionRule(tt.rule)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ConvertBranchProtectionRule() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMigrateBranchProtection(t *testing.T) {
+	t.Parallel()
+
+	const protection = `{"data":{"repository":{"id":"R_1","branchProtectionRules":{
+		"nodes":[{
+			"id":"BPR_1","pattern":"main","isAdminEnforced":true,
+			"requiresStatusChecks":true,"requiredStatusCheckContexts":%s
+		}],
+		"pageInfo":{"hasNextPage":false}
+	}}}}`
+
+	tests := []struct {
+		name        string
+		contexts    string
+		rulesets    string
+		settings    string
+		dryRun      bool
+		want        MigrationStatus
+		wantCreate  bool
+		wantRemoval bool
+	}{
+		{
+			name:       "missing ruleset is created in evaluate enforcement",
+			contexts:   `["test"]`,
+			rulesets:   `[]`,
+			want:       MigrationRulesetsCreated,
+			wantCreate: true,
+		},
+		{
+			name:     "evaluated ruleset awaits promotion",
+			contexts: `["test"]`,
+			rulesets: `[{"id":1,"name":"branch-protection/main","enforcement":"evaluate"}]`,
+			want:     MigrationEvaluating,
+		},
+		{
+			name:        "active ruleset removes branch protection",
+			contexts:    `["test"]`,
+			rulesets:    `[{"id":1,"name":"branch-protection/main","enforcement":"active"}]`,
+			want:        MigrationProtectionRemoved,
+			wantRemoval: true,
+		},
+		{
+			name:     "dry run keeps branch protection",
+			contexts: `["test"]`,
+			rulesets: `[{"id":1,"name":"branch-protection/main","enforcement":"active"}]`,
+			dryRun:   true,
+			want:     MigrationProtectionRemoved,
+		},
+		{
+			name:     "unsupported settings keep branch protection",
+			contexts: `[]`,
+			rulesets: `[{"id":1,"name":"branch-protection/main","enforcement":"active"}]`,
+			want:     MigrationBlocked,
+		},
+		{
+			name:     "pattern managed by the settings file keeps branch protection",
+			contexts: `["test"]`,
+			rulesets: `[{"id":1,"name":"branch-protection/main","enforcement":"active"}]`,
+			settings: "settings:\n  branch_protection:\n    - pattern: main\n",
+			want:     MigrationBlocked,
+		},
+		{
+			name:        "pattern dropped from the settings file removes branch protection",
+			contexts:    `["test"]`,
+			rulesets:    `[{"id":1,"name":"branch-protection/main","enforcement":"active"}]`,
+			settings:    "settings:\n  branch_protection:\n    - pattern: release/*\n",
+			want:        MigrationProtectionRemoved,
+			wantRemoval: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"POST /graphql":                 strings.Replace(protection, "%s", tt.contexts, 1),
+				"GET /repos/org/repo/rulesets":  tt.rulesets,
+				"POST /repos/org/repo/rulesets": `{"id":10}`,
+			})
+
+			var settingsFile string
+
+			if tt.settings != "" {
+				settingsFile = filepath.Join(t.TempDir(), "settings.yml")
+				if err := os.WriteFile(settingsFile, []byte(tt.settings), 0o600); err != nil {
+					t.Fatalf("writing settings file: %v", err)
+				}
+			}
+
+			migrations, err := MigrateBranchProtection(
+				context.Background(), logger.New("error"), client, "org", "repo", settingsFile,
+				true, tt.dryRun,
+			)
+			if err != nil {
+				t.Fatalf("MigrateBranchProtection() error = %v", err)
+			}
+
+			if len(migrations) != 1 {
+				t.Fatalf("MigrateBranchProtection() = %d migrations, want 1", len(migrations))
+			}
+
+			if got := migrations[0].Status; got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+
+			created := fake.called("POST /repos/org/repo/rulesets")
+			if created != tt.wantCreate {
+				t.Errorf("ruleset created = %v, want %v", created, tt.wantCreate)
+			}
+
+			if created && !strings.Contains(fake.body("POST /repos/org/repo/rulesets"),
+				`"enforcement":"evaluate"`) {
+				t.Errorf("ruleset not created in evaluate enforcement: %s",
+					fake.body("POST /repos/org/repo/rulesets"))
+			}
+
+			removed := strings.Contains(fake.body("POST /graphql"), "deleteBranchProtectionRule")
+			if removed != tt.wantRemoval {
+				t.Errorf("branch protection removed = %v, want %v", removed, tt.wantRemoval)
+			}
+		})
+	}
+}
@@ -50,6 +50,21 @@ const (
 	StatusSkipped SyncStatus = "skipped"
 )
 
+// Routes recorded in AutoMergeResult.Route.
+const (
+	AutoMergeRouteAutoMerge  = "auto_merge"
+	AutoMergeRouteMergeQueue = "merge_queue"
+	AutoMergeRouteSkipped    = "skipped"
+	AutoMergeRouteFailed     = "failed"
+)
+
+// AutoMergeResult records how a sync PR was set up to merge.
+type AutoMergeResult struct {
+	Route  string `json:"route"`
+	Method string `json:"method,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
 // SyncResult is the base result type for all sync operations.
 type SyncResult struct {
 	Repo          string     `json:"repo"`
@@ -73,28 +88,149 @@ type LabelsSyncResult struct {
 // FilesSyncResult extends SyncResult with files-specific fields.
 type FilesSyncResult struct {
 	SyncResult
-	PRNumber         int      `json:"pr_number,omitempty"`
-	PRURL            string   `json:"pr_url,omitempty"`
-	CreatedFiles     []string `json:"created_files,omitempty"`
-	UpdatedFiles     []string `json:"updated_files,omitempty"`
-	DeletedFiles     []string `json:"deleted_files,omitempty"`
-	HasDeletionsWarn bool     `json:"has_deletions_warn,omitempty"`
+	PRNumber         int              `json:"pr_number,omitempty"`
+	PRURL            string           `json:"pr_url,omitempty"`
+	CreatedFiles     []string         `json:"created_files,omitempty"`
+	UpdatedFiles     []string         `json:"updated_files,omitempty"`
+	DeletedFiles     []string         `json:"deleted_files,omitempty"`
+	HasDeletionsWarn bool             `json:"has_deletions_warn,omitempty"`
+	AutoMerge        *AutoMergeResult `json:"auto_merge,omitempty"`
 }
 
 // SettingsSyncResult extends SyncResult with settings-specific fields.
 type SettingsSyncResult struct {
 	SyncResult
-	ChangesApplied int `json:"changes_applied"`
+	ChangesApplied   int                    `json:"changes_applied"`
+	Profile          string                 `json:"profile,omitempty"`
+	BranchProtection []BranchProtectionDiff `json:"branch_protection,omitempty"`
+	Rulesets         []RulesetOutcome       `json:"rulesets,omitempty"`
+	Actions          []FieldDiff            `json:"actions,omitempty"`
+	Security         []FieldDiff            `json:"security,omitempty"`
+	Unsupported      []string               `json:"unsupported,omitempty"`
+	Ignored          []string               `json:"ignored,omitempty"`
+	Environments     []EnvironmentOutcome   `json:"environments,omitempty"`
+	Variables        []FieldDiff            `json:"variables,omitempty"`
+	Secrets          []SecretOutcome        `json:"secrets,omitempty"`
+	Webhooks         []WebhookOutcome       `json:"webhooks,omitempty"`
+	Access           []AccessChange         `json:"access,omitempty"`
+	CustomProperties []FieldDiff            `json:"custom_properties,omitempty"`
+	Blocked          []BlockedChange        `json:"blocked,omitempty"`
+}
+
+// FieldDiff describes a setting whose current value differs from the desired one.
+type FieldDiff struct {
+	Field   string `json:"field"`
+	Current any    `json:"current"`
+	Desired any    `json:"desired"`
+}
+
+// BlockedChange is a settings change that would weaken the repository's security and
+// was not applied. Section is the settings section, e.g. security, branch_protection or
+// rulesets, and Target the branch protection pattern, branch or ruleset name.
+type BlockedChange struct {
+	Section string `json:"section"`
+	Target  string `json:"target,omitempty"`
+
+	FieldDiff
+}
+
+// BranchProtectionDiff lists the protection fields that differ on a branch
+// protection rule. Branch is only set when protection was applied per branch.
+type BranchProtectionDiff struct {
+	Pattern string      `json:"pattern"`
+	Branch  string      `json:"branch,omitempty"`
+	Fields  []FieldDiff `json:"fields"`
+}
+
+// ResourceAction is the operation a settings sync performed on a managed resource
+// such as a ruleset, environment, secret or webhook.
+type ResourceAction string
+
+const (
+	ResourceActionCreated   ResourceAction = "created"
+	ResourceActionUpdated   ResourceAction = "updated"
+	ResourceActionDeleted   ResourceAction = "deleted"
+	ResourceActionUnchanged ResourceAction = "unchanged"
+)
+
+// RulesetOutcome records what happened to a single ruleset.
+type RulesetOutcome struct {
+	Name   string         `json:"name"`
+	ID     int64          `json:"id,omitempty"`
+	Action ResourceAction `json:"action"`
+	Fields []FieldDiff    `json:"fields,omitempty"`
+}
+
+// EnvironmentOutcome records what happened to a single deployment environment.
+type EnvironmentOutcome struct {
+	Name   string         `json:"name"`
+	Action ResourceAction `json:"action"`
+	Fields []FieldDiff    `json:"fields,omitempty"`
+}
+
+// SecretOutcome records what happened to a single secret. Secret values are never
+// recorded.
+type SecretOutcome struct {
+	Name   string         `json:"name"`
+	Action ResourceAction `json:"action"`
+}
+
+// WebhookOutcome records what happened to a single webhook. Secrets only appear as
+// fingerprints.
+type WebhookOutcome struct {
+	URL    string         `json:"url"`
+	ID     int64          `json:"id,omitempty"`
+	Action ResourceAction `json:"action"`
+	Fields []FieldDiff    `json:"fields,omitempty"`
+}
+
+// AccessChangeType classifies a change of repository access.
+type AccessChangeType string
+
+const (
+	AccessGranted   AccessChangeType = "grant"
+	AccessEscalated AccessChangeType = "escalate"
+	AccessReduced   AccessChangeType = "reduce"
+	AccessChanged   AccessChangeType = "change"
+	AccessRevoked   AccessChangeType = "revoke"
+)
+
+// AccessChange records a changed team or collaborator permission. Current is empty
+// for new grants and Desired is empty for revocations.
+type AccessChange struct {
+	Kind    string           `json:"kind"`
+	Name    string           `json:"name"`
+	Current string           `json:"current,omitempty"`
+	Desired string           `json:"desired,omitempty"`
+	Change  AccessChangeType `json:"change"`
 }
 
 // SmyklotSyncResult extends SyncResult with smyklot-specific fields.
 type SmyklotSyncResult struct {
 	SyncResult
-	PRNumber         int      `json:"pr_number,omitempty"`
-	PRURL            string   `json:"pr_url,omitempty"`
-	InstalledFiles   []string `json:"installed_files,omitempty"`
-	ReplacedFiles    []string `json:"replaced_files,omitempty"`
-	VersionOnlyFiles []string `json:"version_only_files,omitempty"`
+	PRNumber         int              `json:"pr_number,omitempty"`
+	PRURL            string           `json:"pr_url,omitempty"`
+	InstalledFiles   []string         `json:"installed_files,omitempty"`
+	ReplacedFiles    []string         `json:"replaced_files,omitempty"`
+	VersionOnlyFiles []string         `json:"version_only_files,omitempty"`
+	AutoMerge        *AutoMergeResult `json:"auto_merge,omitempty"`
+}
+
+// BundleSyncResult extends SyncResult with fields of a combined files and smyklot sync.
+type BundleSyncResult struct {
+	SyncResult
+	PRNumber         int              `json:"pr_number,omitempty"`
+	PRURL            string           `json:"pr_url,omitempty"`
+	CreatedFiles     []string         `json:"created_files,omitempty"`
+	UpdatedFiles     []string         `json:"updated_files,omitempty"`
+	DeletedFiles     []string         `json:"deleted_files,omitempty"`
+	HasDeletionsWarn bool             `json:"has_deletions_warn,omitempty"`
+	InstalledFiles   []string         `json:"installed_files,omitempty"`
+	ReplacedFiles    []string         `json:"replaced_files,omitempty"`
+	VersionOnlyFiles []string         `json:"version_only_files,omitempty"`
+	AutoMerge        *AutoMergeResult `json:"auto_merge,omitempty"`
+	// SupersededPRs lists standalone sync PRs closed in favor of the bundle PR.
+	SupersededPRs []int `json:"superseded_prs,omitempty"`
 }
 
 // WorkflowSummary aggregates results from a single workflow run.
@@ -157,6 +293,17 @@ func NewSmyklotSyncResult(repo string, dryRun bool) *SmyklotSyncResult {
 	}
 }
 
+// NewBundleSyncResult creates a new BundleSyncResult with initialized timing.
+func NewBundleSyncResult(repo string, dryRun bool) *BundleSyncResult {
+	return &BundleSyncResult{
+		SyncResult: SyncResult{
+			Repo:      repo,
+			DryRun:    dryRun,
+			StartedAt: time.Now(),
+		},
+	}
+}
+
 // Complete finalizes the result with completion time and status.
 func (r *SyncResult) Complete(status SyncStatus) {
 	r.CompletedAt = time.Now()
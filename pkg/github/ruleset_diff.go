@@ -0,0 +1,285 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v80/github"
+)
+
+// diffRuleset compares an existing ruleset with the desired one field by field.
+// Sections and rule parameters the desired ruleset leaves unset are not compared,
+// since updates keep their current values.
+func diffRuleset(current *github.RepositoryRuleset, desired *github.RepositoryRuleset) []FieldDiff {
+	var diffs []FieldDiff
+
+	diffs = appendDiff(diffs, "name", current.Name, desired.Name)
+	diffs = appendDiff(diffs, "target",
+		rulesetTargetValue(current.GetTarget()), rulesetTargetValue(desired.GetTarget()))
+	diffs = appendDiff(diffs, "enforcement",
+		string(current.Enforcement), string(desired.Enforcement))
+
+	if desired.Conditions != nil {
+		currentConditions := current.Conditions
+		if currentConditions == nil {
+			currentConditions = &github.RepositoryRulesetConditions{}
+		}
+
+		diffs = diffRulesetConditions(diffs, currentConditions, desired.Conditions)
+	}
+
+	if desired.BypassActors != nil {
+		diffs = appendSetDiff(diffs, "bypass_actors",
+			bypassActorKeys(current.BypassActors), bypassActorKeys(desired.BypassActors))
+	}
+
+	if desired.Rules != nil {
+		diffs = diffRulesetRules(diffs, current.Rules, desired.Rules)
+	}
+
+	return diffs
+}
+
+// diffRulesetConditions compares ref name, repository name and repository property
+// conditions the desired ruleset sets.
+func diffRulesetConditions(
+	diffs []FieldDiff,
+	current *github.RepositoryRulesetConditions,
+	desired *github.RepositoryRulesetConditions,
+) []FieldDiff {
+	if desired.RefName != nil {
+		currentRefName := current.RefName
+		if currentRefName == nil {
+			currentRefName = &github.RepositoryRulesetRefConditionParameters{}
+		}
+
+		diffs = appendSetDiff(diffs, "conditions.ref_name.include",
+			currentRefName.Include, desired.RefName.Include)
+		diffs = appendSetDiff(diffs, "conditions.ref_name.exclude",
+			currentRefName.Exclude, desired.RefName.Exclude)
+	}
+
+	if desired.RepositoryName != nil {
+		currentName := current.RepositoryName
+		if currentName == nil {
+			currentName = &github.RepositoryRulesetRepositoryNamesConditionParameters{}
+		}
+
+		diffs = appendSetDiff(diffs, "conditions.repository_name.include",
+			currentName.Include, desired.RepositoryName.Include)
+		diffs = appendSetDiff(diffs, "conditions.repository_name.exclude",
+			currentName.Exclude, desired.RepositoryName.Exclude)
+		diffs = appendDiff(diffs, "conditions.repository_name.protected",
+			currentName.GetProtected(), desired.RepositoryName.GetProtected())
+	}
+
+	if desired.RepositoryProperty != nil {
+		currentProperty := current.RepositoryProperty
+		if currentProperty == nil {
+			currentProperty = &github.RepositoryRulesetRepositoryPropertyConditionParameters{}
+		}
+
+		diffs = appendSetDiff(diffs, "conditions.repository_property.include",
+			propertyTargetKeys(currentProperty.Include),
+			propertyTargetKeys(desired.RepositoryProperty.Include))
+		diffs = appendSetDiff(diffs, "conditions.repository_property.exclude",
+			propertyTargetKeys(currentProperty.Exclude),
+			propertyTargetKeys(desired.RepositoryProperty.Exclude))
+	}
+
+	return diffs
+}
+
+// diffRulesetRules compares rules by type. Rule parameters are compared through
+// their JSON form so every rule type is covered without per-type code.
+func diffRulesetRules(
+	diffs []FieldDiff,
+	current *github.RepositoryRulesetRules,
+	desired *github.RepositoryRulesetRules,
+) []FieldDiff {
+	currentRules := rulesetRuleParameters(current)
+	desiredRules := rulesetRuleParameters(desired)
+
+	types := make([]string, 0, len(currentRules)+len(desiredRules))
+	for ruleType := range currentRules {
+		types = append(types, ruleType)
+	}
+
+	for ruleType := range desiredRules {
+		types = append(types, ruleType)
+	}
+
+	types = sortedSet(types)
+
+	for _, ruleType := range types {
+		field := "rules." + ruleType
+
+		currentParams, hasCurrent := currentRules[ruleType]
+		desiredParams, hasDesired := desiredRules[ruleType]
+
+		if !hasCurrent || !hasDesired {
+			diffs = appendDiff(diffs, field, hasCurrent, hasDesired)
+
+			continue
+		}
+
+		diffs = diffRuleParameters(diffs, field, currentParams, desiredParams)
+	}
+
+	return diffs
+}
+
+// diffRuleParameters compares the parameters the desired rule sets. Lists are
+// compared without regard to order.
+func diffRuleParameters(
+	diffs []FieldDiff,
+	field string,
+	current map[string]any,
+	desired map[string]any,
+) []FieldDiff {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		desiredValue := normalizeRuleValue(desired[key])
+		if desiredValue == nil {
+			continue
+		}
+
+		currentValue := normalizeRuleValue(current[key])
+		if reflect.DeepEqual(currentValue, desiredValue) {
+			continue
+		}
+
+		diffs = append(diffs, FieldDiff{
+			Field:   field + "." + key,
+			Current: currentValue,
+			Desired: desiredValue,
+		})
+	}
+
+	return diffs
+}
+
+// rulesetRuleParameters returns the parameters of each rule keyed by rule type.
+func rulesetRuleParameters(rules *github.RepositoryRulesetRules) map[string]map[string]any {
+	params := make(map[string]map[string]any)
+
+	if rules == nil {
+		return params
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return params
+	}
+
+	var decoded []struct {
+		Type       string         `json:"type"`
+		Parameters map[string]any `json:"parameters"`
+	}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return params
+	}
+
+	for _, rule := range decoded {
+		if rule.Parameters == nil {
+			rule.Parameters = map[string]any{}
+		}
+
+		params[rule.Type] = rule.Parameters
+	}
+
+	return params
+}
+
+// normalizeRuleValue sorts lists so order differences are not reported as changes.
+func normalizeRuleValue(value any) any {
+	list, ok := value.([]any)
+	if !ok {
+		return value
+	}
+
+	keys := make([]string, 0, len(list))
+
+	for _, item := range list {
+		if text, ok := item.(string); ok {
+			keys = append(keys, text)
+
+			continue
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return value
+		}
+
+		keys = append(keys, string(data))
+	}
+
+	return sortedSet(keys)
+}
+
+// bypassActorKeys returns comparable keys of bypass actors.
+func bypassActorKeys(actors []*github.BypassActor) []string {
+	keys := make([]string, 0, len(actors))
+
+	for _, actor := range actors {
+		var actorType, bypassMode string
+
+		if actor.ActorType != nil {
+			actorType = string(*actor.ActorType)
+		}
+
+		if actor.BypassMode != nil {
+			bypassMode = string(*actor.BypassMode)
+		}
+
+		// GitHub ignores the actor ID of the organization admin role
+		actorID := actor.GetActorID()
+		if actorType == bypassActorTypeOrgAdmin {
+			actorID = orgAdminActorID
+		}
+
+		keys = append(keys, fmt.Sprintf("%s:%d:%s", actorType, actorID, bypassMode))
+	}
+
+	return keys
+}
+
+// propertyTargetKeys returns comparable keys of custom property targets. An unset
+// source is GitHub's default, custom.
+func propertyTargetKeys(
+	targets []*github.RepositoryRulesetRepositoryPropertyTargetParameters,
+) []string {
+	keys := make([]string, 0, len(targets))
+
+	for _, target := range targets {
+		source := target.GetSource()
+		if source == "" {
+			source = "custom"
+		}
+
+		keys = append(keys, fmt.Sprintf("%s:%s=%s",
+			source, target.Name, strings.Join(sortedSet(target.PropertyValues), ",")))
+	}
+
+	return keys
+}
+
+// rulesetTargetValue returns the target as a string, empty when unset.
+func rulesetTargetValue(target *github.RulesetTarget) string {
+	if target == nil {
+		return ""
+	}
+
+	return string(*target)
+}
@@ -0,0 +1,432 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// ErrInsightsWindow is returned when the insights window is outside what the rule
+// suites API can report.
+var ErrInsightsWindow = errors.New("invalid insights window")
+
+const (
+	// maxInsightsDays is the longest time period the rule suites API reports.
+	maxInsightsDays = 30
+	// ruleSuitesPerPage is the page size used when listing rule suites.
+	ruleSuitesPerPage = 100
+	// reposPerPage is the page size used when listing organization repositories.
+	reposPerPage = 100
+)
+
+// ruleResultFail is the result of a rule suite or rule evaluation that failed.
+const ruleResultFail = "fail"
+
+// RulesetInsightsReport summarizes what rulesets in evaluate enforcement would have
+// blocked over a period, to decide whether they can be promoted to active.
+type RulesetInsightsReport struct {
+	Org      string            `json:"org"`
+	Days     int               `json:"days"`
+	Since    time.Time         `json:"since"`
+	Rulesets []*RulesetInsight `json:"rulesets"`
+}
+
+// RulesetInsight is the evaluation outcome of a single ruleset in evaluate enforcement.
+// A ruleset is ready for active enforcement when it had no violations over the whole
+// period and was not changed during it.
+type RulesetInsight struct {
+	ID           int64           `json:"id"`
+	Name         string          `json:"name"`
+	Source       string          `json:"source"`
+	SourceType   string          `json:"source_type"`
+	UpdatedAt    *time.Time      `json:"updated_at,omitempty"`
+	Repositories []string        `json:"repositories"`
+	Violations   []RuleViolation `json:"violations"`
+	Ready        bool            `json:"ready"`
+	Promoted     bool            `json:"promoted"`
+}
+
+// RuleViolation counts the pushes of an actor to a repository that a rule of an
+// evaluated ruleset would have blocked.
+type RuleViolation struct {
+	Repository string    `json:"repository"`
+	RuleType   string    `json:"rule_type"`
+	Actor      string    `json:"actor"`
+	Count      int       `json:"count"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// ruleSuite is a push evaluated by rulesets, as returned by the rule suites API.
+type ruleSuite struct {
+	ID               int64            `json:"id"`
+	ActorName        string           `json:"actor_name"`
+	PushedAt         time.Time        `json:"pushed_at"`
+	EvaluationResult string           `json:"evaluation_result"`
+	RuleEvaluations  []ruleEvaluation `json:"rule_evaluations"`
+}
+
+// ruleEvaluation is the outcome of a single rule within a rule suite.
+type ruleEvaluation struct {
+	RuleSource struct {
+		ID int64 `json:"id"`
+	} `json:"rule_source"`
+	Enforcement string `json:"enforcement"`
+	Result      string `json:"result"`
+	RuleType    string `json:"rule_type"`
+}
+
+// RulesetInsights reports the violations of rulesets in evaluate enforcement over the
+// last days before now, across the given repositories or all unarchived repositories
+// of the organization. Rule suites are only fetched for repositories that have
+// evaluated rulesets, and only suites whose evaluated rules failed are read in full.
+func RulesetInsights(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repos []string,
+	days int,
+	now time.Time,
+) (*RulesetInsightsReport, error) {
+	period, err := ruleSuitePeriod(days)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repos) == 0 {
+		repos, err = listActiveRepositories(ctx, client, org)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &RulesetInsightsReport{Org: org, Days: days, Since: now.AddDate(0, 0, -days)}
+	insights := make(map[int64]*RulesetInsight)
+
+	for _, repo := range repos {
+		evaluated, err := evaluatedRulesets(ctx, client, org, repo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing rulesets of %s", repo)
+		}
+
+		if len(evaluated) == 0 {
+			log.Debug("no rulesets in evaluate enforcement", "repo", repo)
+
+			continue
+		}
+
+		for _, ruleset := range evaluated {
+			insight, ok := insights[ruleset.GetID()]
+			if !ok {
+				insight = newRulesetInsight(ruleset)
+				insights[ruleset.GetID()] = insight
+				report.Rulesets = append(report.Rulesets, insight)
+			}
+
+			insight.Repositories = append(insight.Repositories, repo)
+		}
+
+		if err := collectViolations(
+			ctx, client, org, repo, period, report.Since, insights,
+		); err != nil {
+			return nil, errors.Wrapf(err, "reading rule suites of %s", repo)
+		}
+
+		log.Debug("collected rule suite insights", "repo", repo, "rulesets", len(evaluated))
+	}
+
+	for _, insight := range report.Rulesets {
+		finishRulesetInsight(insight, report.Since)
+	}
+
+	slices.SortFunc(report.Rulesets, func(a, b *RulesetInsight) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+
+	return report, nil
+}
+
+// ruleSuitePeriod returns the shortest rule suites time period covering the days.
+func ruleSuitePeriod(days int) (string, error) {
+	const daysPerWeek = 7
+
+	switch {
+	case days < 1 || days > maxInsightsDays:
+		return "", errors.Wrapf(ErrInsightsWindow, "days must be between 1 and %d, got %d",
+			maxInsightsDays, days)
+	case days == 1:
+		return "day", nil
+	case days <= daysPerWeek:
+		return "week", nil
+	default:
+		return "month", nil
+	}
+}
+
+// listActiveRepositories returns the names of the unarchived repositories of an
+// organization.
+func listActiveRepositories(ctx context.Context, client *Client, org string) ([]string, error) {
+	var names []string
+
+	opts := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{PerPage: reposPerPage},
+	}
+
+	for {
+		repos, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing organization repositories")
+		}
+
+		for _, repo := range repos {
+			if !repo.GetArchived() {
+				names = append(names, repo.GetName())
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// evaluatedRulesets returns the rulesets in evaluate enforcement that apply to a
+// repository, including those inherited from the organization.
+func evaluatedRulesets(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) ([]*github.RepositoryRuleset, error) {
+	var evaluated []*github.RepositoryRuleset
+
+	opts := &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(true),
+		ListOptions:     github.ListOptions{PerPage: rulesetsPerPage},
+	}
+
+	for {
+		rulesets, resp, err := client.Repositories.GetAllRulesets(ctx, org, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing rulesets")
+		}
+
+		for _, ruleset := range rulesets {
+			if ruleset.Enforcement == github.RulesetEnforcementEvaluate {
+				evaluated = append(evaluated, ruleset)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return evaluated, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// newRulesetInsight returns an empty insight for a ruleset.
+func newRulesetInsight(ruleset *github.RepositoryRuleset) *RulesetInsight {
+	insight := &RulesetInsight{
+		ID:     ruleset.GetID(),
+		Name:   ruleset.Name,
+		Source: ruleset.Source,
+	}
+
+	if ruleset.SourceType != nil {
+		insight.SourceType = string(*ruleset.SourceType)
+	}
+
+	if ruleset.UpdatedAt != nil {
+		insight.UpdatedAt = github.Ptr(ruleset.UpdatedAt.Time)
+	}
+
+	return insight
+}
+
+// collectViolations records the failed evaluated rules of the rule suites of a
+// repository pushed since the start of the period.
+func collectViolations(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	period string,
+	since time.Time,
+	insights map[int64]*RulesetInsight,
+) error {
+	for page := 1; page != 0; {
+		var suites []ruleSuite
+
+		path := fmt.Sprintf("repos/%s/%s/rulesets/rule-suites?time_period=%s&per_page=%d&page=%d",
+			org, repo, period, ruleSuitesPerPage, page)
+
+		next, err := getRuleSuites(ctx, client, path, &suites)
+		if err != nil {
+			return err
+		}
+
+		for _, suite := range suites {
+			if suite.PushedAt.Before(since) || suite.EvaluationResult != ruleResultFail {
+				continue
+			}
+
+			var detail ruleSuite
+
+			path := "repos/" + org + "/" + repo + "/rulesets/rule-suites/" +
+				strconv.FormatInt(suite.ID, 10)
+
+			if _, err := getRuleSuites(ctx, client, path, &detail); err != nil {
+				return err
+			}
+
+			for _, evaluation := range detail.RuleEvaluations {
+				insight, ok := insights[evaluation.RuleSource.ID]
+				if !ok || evaluation.Enforcement != string(github.RulesetEnforcementEvaluate) ||
+					evaluation.Result != ruleResultFail {
+					continue
+				}
+
+				recordViolation(insight, repo, evaluation.RuleType, suite)
+			}
+		}
+
+		page = next
+	}
+
+	return nil
+}
+
+// getRuleSuites reads a rule suites endpoint into out and returns the next page.
+func getRuleSuites(ctx context.Context, client *Client, path string, out any) (int, error) {
+	req, err := client.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating rule suites request")
+	}
+
+	resp, err := client.Do(ctx, req, out)
+	if err != nil {
+		return 0, errors.Wrap(err, "getting rule suites")
+	}
+
+	return resp.NextPage, nil
+}
+
+// recordViolation counts a failed rule of a rule suite against the ruleset.
+func recordViolation(insight *RulesetInsight, repo string, ruleType string, suite ruleSuite) {
+	for i := range insight.Violations {
+		violation := &insight.Violations[i]
+
+		if violation.Repository == repo && violation.RuleType == ruleType &&
+			violation.Actor == suite.ActorName {
+			violation.Count++
+
+			if suite.PushedAt.After(violation.LastSeen) {
+				violation.LastSeen = suite.PushedAt
+			}
+
+			return
+		}
+	}
+
+	insight.Violations = append(insight.Violations, RuleViolation{
+		Repository: repo,
+		RuleType:   ruleType,
+		Actor:      suite.ActorName,
+		Count:      1,
+		LastSeen:   suite.PushedAt,
+	})
+}
+
+// finishRulesetInsight sorts the insight and decides whether the ruleset is ready for
+// active enforcement.
+func finishRulesetInsight(insight *RulesetInsight, since time.Time) {
+	slices.Sort(insight.Repositories)
+	slices.SortFunc(insight.Violations, func(a, b RuleViolation) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.RuleType, b.RuleType),
+			cmp.Compare(a.Actor, b.Actor),
+			cmp.Compare(a.Repository, b.Repository),
+		)
+	})
+
+	insight.Ready = len(insight.Violations) == 0 &&
+		insight.UpdatedAt != nil && !insight.UpdatedAt.After(since)
+}
+
+// PromoteReadyRulesets switches the rulesets of the report that are ready to active
+// enforcement and marks them as promoted. Rulesets managed by a settings file must be
+// promoted there as well, or the next sync returns them to evaluate enforcement.
+func PromoteReadyRulesets(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	report *RulesetInsightsReport,
+	dryRun bool,
+) error {
+	for _, insight := range report.Rulesets {
+		if !insight.Ready {
+			continue
+		}
+
+		var store rulesetStore
+
+		switch github.RulesetSourceType(insight.SourceType) {
+		case github.RulesetSourceTypeOrganization:
+			store = &orgRulesetStore{client: client, org: report.Org}
+		case github.RulesetSourceTypeRepository:
+			// Repository rulesets apply to their own repository only
+			store = &repoRulesetStore{
+				client: client,
+				org:    report.Org,
+				repo:   insight.Repositories[0],
+			}
+		default:
+			log.Warn("cannot promote ruleset", "name", insight.Name, "source", insight.Source)
+
+			continue
+		}
+
+		if dryRun {
+			log.Info("would promote ruleset to active", "name", insight.Name, "id", insight.ID)
+
+			continue
+		}
+
+		current, err := store.get(ctx, insight.ID)
+		if err != nil {
+			return errors.Wrapf(err, "promoting ruleset %q", insight.Name)
+		}
+
+		promoted := &github.RepositoryRuleset{
+			Name:         current.Name,
+			Target:       current.Target,
+			Enforcement:  github.RulesetEnforcementActive,
+			BypassActors: current.BypassActors,
+			Conditions:   current.Conditions,
+			Rules:        current.Rules,
+		}
+
+		if err := store.update(ctx, insight.ID, promoted); err != nil {
+			return errors.Wrapf(err, "promoting ruleset %q", insight.Name)
+		}
+
+		insight.Promoted = true
+
+		log.Info("promoted ruleset to active", "name", insight.Name, "id", insight.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestRulesetInsights(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/rulesets": `[
+			{"id":1,"name":"org-sync/base","enforcement":"evaluate",
+				"source_type":"Organization","source":"org","updated_at":"2026-09-01T00:00:00Z"},
+			{"id":2,"name":"branch-protection/main","enforcement":"evaluate",
+				"source_type":"Repository","source":"org/repo","updated_at":"2026-09-01T00:00:00Z"},
+			{"id":3,"name":"recent","enforcement":"evaluate",
+				"source_type":"Repository","source":"org/repo","updated_at":"2026-10-15T00:00:00Z"},
+			{"id":4,"name":"enforced","enforcement":"active","source_type":"Repository",
+				"source":"org/repo","updated_at":"2026-09-01T00:00:00Z"}]`,
+		"GET /repos/org/repo/rulesets/rule-suites": `[
+			{"id":7,"actor_name":"octocat",
+				"pushed_at":"2026-10-16T10:00:00Z","evaluation_result":"fail"},
+			{"id":8,"actor_name":"octocat",
+				"pushed_at":"2026-09-20T10:00:00Z","evaluation_result":"fail"},
+			{"id":9,"actor_name":"renovate[bot]",
+				"pushed_at":"2026-10-17T10:00:00Z","evaluation_result":"pass"}]`,
+		"GET /repos/org/repo/rulesets/rule-suites/7": `{"id":7,"rule_evaluations":[
+			{"rule_source":{"type":"ruleset","id":1},"enforcement":"evaluate",
+				"result":"fail","rule_type":"pull_request"},
+			{"rule_source":{"type":"ruleset","id":1},"enforcement":"evaluate",
+				"result":"pass","rule_type":"deletion"},
+			{"rule_source":{"type":"ruleset","id":4},"enforcement":"active",
+				"result":"fail","rule_type":"non_fast_forward"}]}`,
+		"GET /repos/org/repo/rulesets/2": `{"id":2,"name":"branch-protection/main",
+			"target":"branch","enforcement":"evaluate","rules":[{"type":"deletion"}]}`,
+		"PUT /repos/org/repo/rulesets/2": `{"id":2}`,
+	})
+
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	log := logger.New("error")
+
+	report, err := RulesetInsights(
+		context.Background(), log, client, "org", []string{"repo"}, 14, now,
+	)
+	if err != nil {
+		t.Fatalf("RulesetInsights() error = %v", err)
+	}
+
+	updated := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	want := &RulesetInsightsReport{
+		Org:   "org",
+		Days:  14,
+		Since: time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC),
+		Rulesets: []*RulesetInsight{
+			{
+				ID:           2,
+				Name:         "branch-protection/main",
+				Source:       "org/repo",
+				SourceType:   "Repository",
+				UpdatedAt:    &updated,
+				Repositories: []string{"repo"},
+				Ready:        true,
+			},
+			{
+				ID:           1,
+				Name:         "org-sync/base",
+				Source:       "org",
+				SourceType:   "Organization",
+				UpdatedAt:    &updated,
+				Repositories: []string{"repo"},
+				Violations: []RuleViolation{
+					{
+						Repository: "repo",
+						RuleType:   "pull_request",
+						Actor:      "octocat",
+						Count:      1,
+						LastSeen:   time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			{
+				ID:           3,
+				Name:         "recent",
+				Source:       "org/repo",
+				SourceType:   "Repository",
+				UpdatedAt:    &recent,
+				Repositories: []string{"repo"},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("RulesetInsights() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := PromoteReadyRulesets(context.Background(), log, client, report, false); err != nil {
+		t.Fatalf("PromoteReadyRulesets() error = %v", err)
+	}
+
+	body := fake.body("PUT /repos/org/repo/rulesets/2")
+	if !strings.Contains(body, `"enforcement":"active"`) {
+		t.Errorf("promoted ruleset body = %s, want active enforcement", body)
+	}
+
+	if !report.Rulesets[0].Promoted || report.Rulesets[1].Promoted || report.Rulesets[2].Promoted {
+		t.Errorf("only the ready ruleset should be promoted")
+	}
+}
+
+func TestRuleSuitePeriod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		days    int
+		want    string
+		wantErr bool
+	}{
+		{name: "no days", days: 0, wantErr: true},
+		{name: "single day", days: 1, want: "day"},
+		{name: "week", days: 7, want: "week"},
+		{name: "over a week", days: 8, want: "month"},
+		{name: "month", days: 30, want: "month"},
+		{name: "over a month", days: 31, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ruleSuitePeriod(tt.days)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ruleSuitePeriod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ruleSuitePeriod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatedRulesetsPaginates(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("page") != "2" {
+			w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[{"id":1,"name":"first","enforcement":"evaluate"},` +
+				`{"id":2,"name":"enforced","enforcement":"active"}]`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":3,"name":"second","enforcement":"evaluate"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	rulesets, err := evaluatedRulesets(
+		context.Background(), newTestClient(t, server.URL), "org", "repo",
+	)
+	if err != nil {
+		t.Fatalf("evaluatedRulesets() error = %v", err)
+	}
+
+	var names []string
+	for _, ruleset := range rulesets {
+		names = append(names, ruleset.Name)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second"}, names); diff != "" {
+		t.Errorf("evaluatedRulesets() mismatch (-want +got):\n%s", diff)
+	}
+}
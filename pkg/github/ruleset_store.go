@@ -0,0 +1,188 @@
+package github
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+)
+
+// rulesetsPerPage is the page size used when listing rulesets.
+const rulesetsPerPage = 100
+
+// rulesetStore reads and writes the rulesets of a repository or an organization.
+type rulesetStore interface {
+	list(ctx context.Context) ([]*github.RepositoryRuleset, error)
+	get(ctx context.Context, id int64) (*github.RepositoryRuleset, error)
+	create(ctx context.Context, ruleset *github.RepositoryRuleset) (int64, error)
+	update(ctx context.Context, id int64, ruleset *github.RepositoryRuleset) error
+	remove(ctx context.Context, id int64) error
+	sourceType() github.RulesetSourceType
+}
+
+// repoRulesetStore manages rulesets of a single repository, ignoring rulesets
+// inherited from the organization.
+type repoRulesetStore struct {
+	client *Client
+	org    string
+	repo   string
+}
+
+func (s *repoRulesetStore) list(ctx context.Context) ([]*github.RepositoryRuleset, error) {
+	var all []*github.RepositoryRuleset
+
+	opts := &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(false),
+		ListOptions:     github.ListOptions{PerPage: rulesetsPerPage},
+	}
+
+	for {
+		rulesets, resp, err := s.client.Repositories.GetAllRulesets(ctx, s.org, s.repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing rulesets")
+		}
+
+		all = append(all, rulesets...)
+
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+func (s *repoRulesetStore) get(ctx context.Context, id int64) (*github.RepositoryRuleset, error) {
+	ruleset, _, err := s.client.Repositories.GetRuleset(ctx, s.org, s.repo, id, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting ruleset")
+	}
+
+	return ruleset, nil
+}
+
+func (s *repoRulesetStore) create(
+	ctx context.Context,
+	ruleset *github.RepositoryRuleset,
+) (int64, error) {
+	created, _, err := s.client.Repositories.CreateRuleset(ctx, s.org, s.repo, *ruleset)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating ruleset")
+	}
+
+	return created.GetID(), nil
+}
+
+func (s *repoRulesetStore) update(
+	ctx context.Context,
+	id int64,
+	ruleset *github.RepositoryRuleset,
+) error {
+	_, _, err := s.client.Repositories.UpdateRuleset(ctx, s.org, s.repo, id, *ruleset)
+	if err != nil {
+		return errors.Wrap(err, "updating ruleset")
+	}
+
+	// Empty bypass actors are omitted from updates and have to be cleared separately
+	if ruleset.BypassActors != nil && len(ruleset.BypassActors) == 0 {
+		_, err := s.client.Repositories.UpdateRulesetClearBypassActor(ctx, s.org, s.repo, id)
+		if err != nil {
+			return errors.Wrap(err, "clearing ruleset bypass actors")
+		}
+	}
+
+	return nil
+}
+
+func (s *repoRulesetStore) remove(ctx context.Context, id int64) error {
+	if _, err := s.client.Repositories.DeleteRuleset(ctx, s.org, s.repo, id); err != nil {
+		return errors.Wrap(err, "deleting ruleset")
+	}
+
+	return nil
+}
+
+func (*repoRulesetStore) sourceType() github.RulesetSourceType {
+	return github.RulesetSourceTypeRepository
+}
+
+// orgRulesetStore manages rulesets of an organization.
+type orgRulesetStore struct {
+	client *Client
+	org    string
+}
+
+func (s *orgRulesetStore) list(ctx context.Context) ([]*github.RepositoryRuleset, error) {
+	var all []*github.RepositoryRuleset
+
+	opts := &github.ListOptions{PerPage: rulesetsPerPage}
+
+	for {
+		rulesets, resp, err := s.client.Organizations.GetAllRepositoryRulesets(ctx, s.org, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing organization rulesets")
+		}
+
+		all = append(all, rulesets...)
+
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+func (s *orgRulesetStore) get(ctx context.Context, id int64) (*github.RepositoryRuleset, error) {
+	ruleset, _, err := s.client.Organizations.GetRepositoryRuleset(ctx, s.org, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting organization ruleset")
+	}
+
+	return ruleset, nil
+}
+
+func (s *orgRulesetStore) create(
+	ctx context.Context,
+	ruleset *github.RepositoryRuleset,
+) (int64, error) {
+	created, _, err := s.client.Organizations.CreateRepositoryRuleset(ctx, s.org, *ruleset)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating organization ruleset")
+	}
+
+	return created.GetID(), nil
+}
+
+func (s *orgRulesetStore) update(
+	ctx context.Context,
+	id int64,
+	ruleset *github.RepositoryRuleset,
+) error {
+	_, _, err := s.client.Organizations.UpdateRepositoryRuleset(ctx, s.org, id, *ruleset)
+	if err != nil {
+		return errors.Wrap(err, "updating organization ruleset")
+	}
+
+	// Empty bypass actors are omitted from updates and have to be cleared separately
+	if ruleset.BypassActors != nil && len(ruleset.BypassActors) == 0 {
+		_, err := s.client.Organizations.UpdateRepositoryRulesetClearBypassActor(ctx, s.org, id)
+		if err != nil {
+			return errors.Wrap(err, "clearing organization ruleset bypass actors")
+		}
+	}
+
+	return nil
+}
+
+func (s *orgRulesetStore) remove(ctx context.Context, id int64) error {
+	if _, err := s.client.Organizations.DeleteRepositoryRuleset(ctx, s.org, id); err != nil {
+		return errors.Wrap(err, "deleting organization ruleset")
+	}
+
+	return nil
+}
+
+func (*orgRulesetStore) sourceType() github.RulesetSourceType {
+	return github.RulesetSourceTypeOrganization
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	"github.com/google/go-github/v80/github"
@@ -10,7 +11,30 @@ import (
 	"github.com/smykla-labs/.github/pkg/logger"
 )
 
+// managedRulesetPrefix marks rulesets created by the organization sync. Only
+// rulesets with this name prefix are removed when allow_removal is enabled.
+const managedRulesetPrefix = "org-sync/"
+
+// rulesetChange is a planned ruleset operation.
+type rulesetChange struct {
+	RulesetOutcome
+
+	Ruleset *github.RepositoryRuleset
+	// Current is the existing ruleset, nil when it is created or deleted.
+	Current *github.RepositoryRuleset
+}
+
+// rulesetSpec is a configured ruleset. Build returns the desired ruleset given the
+// current one, which is nil when the ruleset does not exist yet.
+type rulesetSpec struct {
+	Name  string
+	Build func(current *github.RepositoryRuleset) *github.RepositoryRuleset
+}
+
 // SyncRulesets synchronizes repository rulesets from configuration to target repository.
+// Rulesets matching the existing state are left untouched, and unmanaged rulesets with the
+// managed prefix are deleted when allowRemoval is set. Changes weakening the repository's
+// security are only applied when the policy allows them.
 func SyncRulesets(
 	ctx context.Context,
 	log *logger.Logger,
@@ -19,26 +43,95 @@ func SyncRulesets(
 	repo string,
 	rulesets []configtypes.RulesetConfig,
 	exclude []string,
+	allowRemoval bool,
+	policy *WeakeningPolicy,
 	dryRun bool,
-) error {
+) ([]RulesetOutcome, error) {
 	// Check if rulesets sync is excluded
 	if isSettingExcluded("rulesets", exclude) {
 		log.Debug("rulesets sync excluded by config")
 
-		return nil
+		return nil, nil
 	}
 
-	// No rulesets to sync
-	if len(rulesets) == 0 {
+	// No rulesets to sync or remove
+	if len(rulesets) == 0 && !allowRemoval {
 		log.Debug("no rulesets configured")
 
-		return nil
+		return nil, nil
+	}
+
+	specs := make([]rulesetSpec, 0, len(rulesets))
+
+	for _, rulesetConfig := range rulesets {
+		actors, err := resolveBypassActors(ctx, client, org, rulesetConfig.BypassActors)
+		if err != nil {
+			return nil, errors.Wrapf(err, "planning ruleset %q", rulesetConfig.Name)
+		}
+
+		rulesetConfig.BypassActors = actors
+
+		specs = append(specs, rulesetSpec{
+			Name: rulesetConfig.Name,
+			Build: func(current *github.RepositoryRuleset) *github.RepositoryRuleset {
+				return buildRulesetFromConfig(rulesetConfig, current)
+			},
+		})
 	}
 
+	store := &repoRulesetStore{client: client, org: org, repo: repo}
+
+	return syncRulesets(ctx, log, store, specs, allowRemoval, policy, dryRun)
+}
+
+// syncRulesets plans and, unless in dry-run mode, applies ruleset changes. Changes the
+// policy blocks are left out, a nil policy allows every change.
+func syncRulesets(
+	ctx context.Context,
+	log *logger.Logger,
+	store rulesetStore,
+	specs []rulesetSpec,
+	allowRemoval bool,
+	policy *WeakeningPolicy,
+	dryRun bool,
+) ([]RulesetOutcome, error) {
+	changes, err := planRulesets(ctx, log, store, specs, allowRemoval)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.reviewRulesets(log, changes)
+
+	logRulesetChanges(log, changes, dryRun)
+
+	if dryRun {
+		log.Info("dry-run mode: skipping ruleset changes", "count", len(changes))
+
+		return rulesetOutcomes(changes), nil
+	}
+
+	if err := applyRulesetChanges(ctx, log, store, changes); err != nil {
+		return rulesetOutcomes(changes), err
+	}
+
+	log.Info("rulesets synced successfully", "count", len(changes))
+
+	return rulesetOutcomes(changes), nil
+}
+
+// planRulesets compares configured rulesets with the existing ones and returns the
+// operation needed for each of them.
+func planRulesets(
+	ctx context.Context,
+	log *logger.Logger,
+	store rulesetStore,
+	specs []rulesetSpec,
+	allowRemoval bool,
+) ([]rulesetChange, error) {
 	// Fetch existing rulesets
-	existingRulesets, err := fetchExistingRulesets(ctx, client, org, repo)
+	existingRulesets, err := store.list(ctx)
 	if err != nil {
-		return errors.Wrap(err, "fetching existing rulesets")
+		return nil, errors.Wrap(err, "fetching existing rulesets")
 	}
 
 	log.Debug("fetched existing rulesets", "count", len(existingRulesets))
@@ -49,87 +142,170 @@ func SyncRulesets(
 		existingByName[ruleset.Name] = ruleset
 	}
 
-	// Sync each ruleset
-	for _, rulesetConfig := range rulesets {
-		existing := existingByName[rulesetConfig.Name]
+	changes := make([]rulesetChange, 0, len(specs))
+	configured := make(map[string]bool, len(specs))
 
-		if dryRun {
-			logRulesetOperation(log, rulesetConfig.Name, existing)
+	for _, spec := range specs {
+		configured[spec.Name] = true
 
-			continue
+		existing := existingByName[spec.Name]
+		if existing == nil {
+			existing = adoptableRuleset(existingByName, spec.Name, store.sourceType())
 		}
 
-		if err := createOrUpdateRuleset(ctx, log, client, org, repo, rulesetConfig, existing); err != nil {
-			return errors.Wrapf(err, "syncing ruleset %q", rulesetConfig.Name)
+		if existing != nil && existing.Name != spec.Name {
+			log.Info("adopting ruleset under the managed prefix",
+				"name", existing.Name,
+				"managed_name", spec.Name,
+			)
+
+			configured[existing.Name] = true
 		}
 
-		if existing == nil {
-			log.Info("created ruleset", "name", rulesetConfig.Name)
-		} else {
-			log.Info("updated ruleset", "name", rulesetConfig.Name)
+		change, err := planRuleset(ctx, store, spec, existing)
+		if err != nil {
+			return nil, errors.Wrapf(err, "planning ruleset %q", spec.Name)
 		}
+
+		changes = append(changes, change)
 	}
 
-	if dryRun {
-		log.Info("dry-run mode: skipping ruleset changes", "count", len(rulesets))
-	} else {
-		log.Info("rulesets synced successfully", "count", len(rulesets))
+	if !allowRemoval {
+		return changes, nil
 	}
 
-	return nil
+	for _, spec := range specs {
+		if !strings.HasPrefix(spec.Name, managedRulesetPrefix) {
+			log.Warn("configured ruleset lacks the managed prefix and is never removed",
+				"name", spec.Name,
+				"prefix", managedRulesetPrefix,
+			)
+		}
+	}
+
+	for _, existing := range existingRulesets {
+		if configured[existing.Name] || !isManagedRuleset(existing, store.sourceType()) {
+			continue
+		}
+
+		changes = append(changes, rulesetChange{
+			RulesetOutcome: RulesetOutcome{
+				Name:   existing.Name,
+				ID:     existing.GetID(),
+				Action: ResourceActionDeleted,
+			},
+		})
+	}
+
+	return changes, nil
 }
 
-// fetchExistingRulesets retrieves all rulesets from a repository.
-func fetchExistingRulesets(
+// planRuleset builds the desired ruleset and compares it with the existing one of
+// the same name. The list endpoint omits rules, so the full ruleset is fetched first.
+func planRuleset(
 	ctx context.Context,
-	client *Client,
-	org string,
-	repo string,
-) ([]*github.RepositoryRuleset, error) {
-	opts := &github.RepositoryListRulesetsOptions{
-		IncludesParents: github.Ptr(false),
+	store rulesetStore,
+	spec rulesetSpec,
+	existing *github.RepositoryRuleset,
+) (rulesetChange, error) {
+	if existing == nil {
+		return rulesetChange{
+			RulesetOutcome: RulesetOutcome{
+				Name:   spec.Name,
+				Action: ResourceActionCreated,
+			},
+			Ruleset: spec.Build(nil),
+		}, nil
 	}
 
-	rulesets, _, err := client.Repositories.GetAllRulesets(ctx, org, repo, opts)
+	current, err := store.get(ctx, existing.GetID())
 	if err != nil {
-		return nil, errors.Wrap(err, "listing rulesets")
+		return rulesetChange{}, err
 	}
 
-	return rulesets, nil
+	desired := spec.Build(current)
+
+	change := rulesetChange{
+		RulesetOutcome: RulesetOutcome{
+			Name:   spec.Name,
+			ID:     current.GetID(),
+			Action: ResourceActionUnchanged,
+			Fields: diffRuleset(current, desired),
+		},
+		Ruleset: desired,
+		Current: current,
+	}
+
+	if len(change.Fields) > 0 {
+		change.Action = ResourceActionUpdated
+	}
+
+	return change, nil
 }
 
-// createOrUpdateRuleset creates a new ruleset or updates an existing one.
-func createOrUpdateRuleset(
+// adoptableRuleset returns the existing ruleset a configured ruleset with the managed
+// prefix takes over: one named without the prefix, as synced before the prefix was
+// added. Returns nil when there is none or the name has no prefix.
+func adoptableRuleset(
+	existingByName map[string]*github.RepositoryRuleset,
+	name string,
+	source github.RulesetSourceType,
+) *github.RepositoryRuleset {
+	unprefixed, ok := strings.CutPrefix(name, managedRulesetPrefix)
+	if !ok {
+		return nil
+	}
+
+	ruleset := existingByName[unprefixed]
+	if ruleset == nil || ruleset.SourceType != nil && *ruleset.SourceType != source {
+		return nil
+	}
+
+	return ruleset
+}
+
+// isManagedRuleset reports whether a ruleset was created by the organization sync
+// and belongs to the synced repository or organization rather than to a parent.
+func isManagedRuleset(ruleset *github.RepositoryRuleset, source github.RulesetSourceType) bool {
+	if ruleset.SourceType != nil && *ruleset.SourceType != source {
+		return false
+	}
+
+	return strings.HasPrefix(ruleset.Name, managedRulesetPrefix)
+}
+
+// applyRulesetChanges creates, updates and deletes rulesets as planned. Unchanged
+// rulesets are skipped.
+func applyRulesetChanges(
 	ctx context.Context,
 	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	rulesetConfig configtypes.RulesetConfig,
-	existing *github.RepositoryRuleset,
+	store rulesetStore,
+	changes []rulesetChange,
 ) error {
-	// Build ruleset from config
-	ruleset := buildRulesetFromConfig(rulesetConfig, existing)
-
-	if existing == nil {
-		// Create new ruleset
-		_, _, err := client.Repositories.CreateRuleset(ctx, org, repo, *ruleset)
-		if err != nil {
-			return errors.Wrap(err, "creating ruleset")
-		}
+	for i := range changes {
+		change := &changes[i]
+
+		switch change.Action {
+		case ResourceActionCreated:
+			id, err := store.create(ctx, change.Ruleset)
+			if err != nil {
+				return errors.Wrapf(err, "creating ruleset %q", change.Name)
+			}
 
-		log.Debug("created new ruleset", "name", rulesetConfig.Name)
-	} else {
-		// Update existing ruleset
-		_, _, err := client.Repositories.UpdateRuleset(ctx, org, repo, existing.GetID(), *ruleset)
-		if err != nil {
-			return errors.Wrap(err, "updating ruleset")
+			change.ID = id
+		case ResourceActionUpdated:
+			if err := store.update(ctx, change.ID, change.Ruleset); err != nil {
+				return errors.Wrapf(err, "updating ruleset %q", change.Name)
+			}
+		case ResourceActionDeleted:
+			if err := store.remove(ctx, change.ID); err != nil {
+				return errors.Wrapf(err, "deleting ruleset %q", change.Name)
+			}
+		case ResourceActionUnchanged:
+			continue
 		}
 
-		log.Debug("updated existing ruleset",
-			"name", rulesetConfig.Name,
-			"id", existing.GetID(),
-		)
+		log.Info(string(change.Action)+" ruleset", "name", change.Name, "id", change.ID)
 	}
 
 	return nil
@@ -154,8 +330,8 @@ func buildRulesetFromConfig(
 		ruleset.Conditions = buildConditions(rulesetConfig.Conditions)
 	}
 
-	// Convert bypass actors
-	if len(rulesetConfig.BypassActors) > 0 {
+	// Convert bypass actors; an empty list removes the existing ones
+	if rulesetConfig.BypassActors != nil {
 		ruleset.BypassActors = buildBypassActors(rulesetConfig.BypassActors)
 	}
 
@@ -266,9 +442,140 @@ func buildRules(
 		rules.CodeScanning = buildCodeScanningRule(rulesConfig.CodeScanning)
 	}
 
+	// Pattern rules
+	rules.CommitMessagePattern = buildPatternRule(rulesConfig.CommitMessagePattern)
+	rules.CommitAuthorEmailPattern = buildPatternRule(rulesConfig.CommitAuthorEmailPattern)
+	rules.BranchNamePattern = buildPatternRule(rulesConfig.BranchNamePattern)
+	rules.TagNamePattern = buildPatternRule(rulesConfig.TagNamePattern)
+
+	// Push rules
+	if rulesConfig.FilePathRestriction != nil {
+		rules.FilePathRestriction = &github.FilePathRestrictionRuleParameters{
+			RestrictedFilePaths: nonNilStrings(rulesConfig.FilePathRestriction.RestrictedFilePaths),
+		}
+	}
+
+	if rulesConfig.MaxFileSize != nil {
+		rules.MaxFileSize = &github.MaxFileSizeRuleParameters{
+			MaxFileSize: rulesConfig.MaxFileSize.MaxFileSize,
+		}
+	}
+
+	if rulesConfig.FileExtensionRestriction != nil {
+		rules.FileExtensionRestriction = &github.FileExtensionRestrictionRuleParameters{
+			RestrictedFileExtensions: nonNilStrings(
+				rulesConfig.FileExtensionRestriction.RestrictedFileExtensions,
+			),
+		}
+	}
+
+	if rulesConfig.RequiredDeployments != nil {
+		rules.RequiredDeployments = &github.RequiredDeploymentsRuleParameters{
+			RequiredDeploymentEnvironments: nonNilStrings(
+				rulesConfig.RequiredDeployments.RequiredDeploymentEnvironments,
+			),
+		}
+	}
+
+	if rulesConfig.MergeQueue != nil {
+		rules.MergeQueue = buildMergeQueueRule(rulesConfig.MergeQueue)
+	}
+
+	if rulesConfig.Workflows != nil {
+		rules.Workflows = buildWorkflowsRule(rulesConfig.Workflows)
+	}
+
 	return rules
 }
 
+// buildPatternRule converts a config pattern rule to go-github PatternRuleParameters.
+// Returns nil when the rule is not configured.
+func buildPatternRule(patternConfig *configtypes.PatternRuleConfig) *github.PatternRuleParameters {
+	if patternConfig == nil {
+		return nil
+	}
+
+	rule := &github.PatternRuleParameters{
+		Operator: github.PatternRuleOperator(patternConfig.Operator),
+		Pattern:  patternConfig.Pattern,
+		Negate:   patternConfig.Negate,
+	}
+
+	if patternConfig.Name != "" {
+		rule.Name = github.Ptr(patternConfig.Name)
+	}
+
+	return rule
+}
+
+// Merge queue defaults used by GitHub when creating a merge queue rule in the UI.
+const (
+	mergeQueueCheckResponseTimeoutMinutes  = 60
+	mergeQueueMaxEntriesToBuild            = 5
+	mergeQueueMaxEntriesToMerge            = 5
+	mergeQueueMinEntriesToMerge            = 1
+	mergeQueueMinEntriesToMergeWaitMinutes = 5
+)
+
+// buildMergeQueueRule converts config merge queue to go-github MergeQueueRuleParameters.
+// The API requires every parameter, so unset fields get GitHub's defaults.
+func buildMergeQueueRule(
+	mqConfig *configtypes.MergeQueueRuleConfig,
+) *github.MergeQueueRuleParameters {
+	rule := &github.MergeQueueRuleParameters{
+		CheckResponseTimeoutMinutes: getIntValueOr(
+			mqConfig.CheckResponseTimeoutMinutes, mergeQueueCheckResponseTimeoutMinutes,
+		),
+		GroupingStrategy:  github.MergeGroupingStrategyAllGreen,
+		MaxEntriesToBuild: getIntValueOr(mqConfig.MaxEntriesToBuild, mergeQueueMaxEntriesToBuild),
+		MaxEntriesToMerge: getIntValueOr(mqConfig.MaxEntriesToMerge, mergeQueueMaxEntriesToMerge),
+		MergeMethod:       github.MergeQueueMergeMethodMerge,
+		MinEntriesToMerge: getIntValueOr(mqConfig.MinEntriesToMerge, mergeQueueMinEntriesToMerge),
+		MinEntriesToMergeWaitMinutes: getIntValueOr(
+			mqConfig.MinEntriesToMergeWaitMinutes, mergeQueueMinEntriesToMergeWaitMinutes,
+		),
+	}
+
+	if mqConfig.GroupingStrategy != "" {
+		rule.GroupingStrategy = github.MergeGroupingStrategy(mqConfig.GroupingStrategy)
+	}
+
+	if mqConfig.MergeMethod != "" {
+		rule.MergeMethod = github.MergeQueueMergeMethod(mqConfig.MergeMethod)
+	}
+
+	return rule
+}
+
+// buildWorkflowsRule converts config workflows to go-github WorkflowsRuleParameters.
+func buildWorkflowsRule(
+	workflowsConfig *configtypes.WorkflowsRuleConfig,
+) *github.WorkflowsRuleParameters {
+	rule := &github.WorkflowsRuleParameters{
+		DoNotEnforceOnCreate: workflowsConfig.DoNotEnforceOnCreate,
+		Workflows:            make([]*github.RuleWorkflow, 0, len(workflowsConfig.Workflows)),
+	}
+
+	for _, workflowConfig := range workflowsConfig.Workflows {
+		workflow := &github.RuleWorkflow{
+			Path:         workflowConfig.Path,
+			RepositoryID: workflowConfig.RepositoryID,
+		}
+
+		if workflowConfig.Ref != "" {
+			workflow.Ref = github.Ptr(workflowConfig.Ref)
+		}
+
+		if workflowConfig.SHA != "" {
+			workflow.SHA = github.Ptr(workflowConfig.SHA)
+		}
+
+		rule.Workflows = append(rule.Workflows, workflow)
+	}
+
+	return rule
+}
+
 // buildPullRequestRule converts config PR rule to go-github PullRequestRuleParameters.
 func buildPullRequestRule(
 	prConfig *configtypes.PullRequestRuleConfig,
@@ -412,11 +719,49 @@ func buildCodeScanningRule(
 	return rule
 }
 
-// logRulesetOperation logs the planned operation in dry-run mode.
-func logRulesetOperation(log *logger.Logger, name string, existing *github.RepositoryRuleset) {
-	if existing == nil {
-		log.Info("would create ruleset", "name", name)
-	} else {
-		log.Info("would update ruleset", "name", name, "id", existing.GetID())
+// logRulesetChanges logs planned ruleset operations and their field changes.
+func logRulesetChanges(log *logger.Logger, changes []rulesetChange, dryRun bool) {
+	for _, change := range changes {
+		if change.Action == ResourceActionUnchanged {
+			log.Debug("ruleset up to date", "name", change.Name, "id", change.ID)
+
+			continue
+		}
+
+		if dryRun {
+			log.Info("would apply ruleset change",
+				"name", change.Name,
+				"id", change.ID,
+				"action", change.Action,
+			)
+		}
+
+		for _, diff := range change.Fields {
+			log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+		}
 	}
 }
+
+// rulesetOutcomes returns the exported outcomes of planned changes.
+func rulesetOutcomes(changes []rulesetChange) []RulesetOutcome {
+	outcomes := make([]RulesetOutcome, 0, len(changes))
+
+	for _, change := range changes {
+		outcomes = append(outcomes, change.RulesetOutcome)
+	}
+
+	return outcomes
+}
+
+// countRulesetChanges returns the number of rulesets created, updated or deleted.
+func countRulesetChanges(outcomes []RulesetOutcome) int {
+	count := 0
+
+	for _, outcome := range outcomes {
+		if outcome.Action != ResourceActionUnchanged {
+			count++
+		}
+	}
+
+	return count
+}
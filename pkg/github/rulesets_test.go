@@ -1,11 +1,19 @@
 package github
 
 import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-github/v80/github"
 
 	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
 )
 
 func TestBuildRulesetFromConfig(t *testing.T) {
@@ -276,6 +284,99 @@ func TestBuildRulesetFromConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "converts pattern and push rules",
+			config: configtypes.RulesetConfig{
+				Name:        "push-rules",
+				Target:      "push",
+				Enforcement: "active",
+				Rules: &configtypes.RulesetRulesConfig{
+					CommitMessagePattern: &configtypes.PatternRuleConfig{
+						Operator: "regex",
+						Pattern:  "^(feat|fix|chore)",
+						Name:     "conventional commits",
+					},
+					TagNamePattern: &configtypes.PatternRuleConfig{
+						Operator: "starts_with",
+						Pattern:  "v",
+						Negate:   github.Ptr(true),
+					},
+					FilePathRestriction: &configtypes.FilePathRestrictionRuleConfig{
+						RestrictedFilePaths: []string{".github/workflows/**"},
+					},
+					MaxFileSize: &configtypes.MaxFileSizeRuleConfig{MaxFileSize: 10},
+				},
+			},
+			validate: func(t *testing.T, ruleset *github.RepositoryRuleset) {
+				want := &github.RepositoryRulesetRules{
+					CommitMessagePattern: &github.PatternRuleParameters{
+						Name:     github.Ptr("conventional commits"),
+						Operator: github.PatternRuleOperatorRegex,
+						Pattern:  "^(feat|fix|chore)",
+					},
+					TagNamePattern: &github.PatternRuleParameters{
+						Negate:   github.Ptr(true),
+						Operator: github.PatternRuleOperatorStartsWith,
+						Pattern:  "v",
+					},
+					FilePathRestriction: &github.FilePathRestrictionRuleParameters{
+						RestrictedFilePaths: []string{".github/workflows/**"},
+					},
+					MaxFileSize: &github.MaxFileSizeRuleParameters{MaxFileSize: 10},
+				}
+
+				if diff := cmp.Diff(want, ruleset.Rules); diff != "" {
+					t.Errorf("rules mismatch (-want +got):\n%s", diff)
+				}
+			},
+		},
+		{
+			name: "fills merge queue defaults and converts workflows",
+			config: configtypes.RulesetConfig{
+				Name:        "merge-rules",
+				Target:      "branch",
+				Enforcement: "active",
+				Rules: &configtypes.RulesetRulesConfig{
+					RequiredDeployments: &configtypes.RequiredDeploymentsRuleConfig{
+						RequiredDeploymentEnvironments: []string{"staging"},
+					},
+					MergeQueue: &configtypes.MergeQueueRuleConfig{
+						MergeMethod:       "SQUASH",
+						MaxEntriesToMerge: github.Ptr(10),
+					},
+					Workflows: &configtypes.WorkflowsRuleConfig{
+						Workflows: []configtypes.WorkflowRuleConfig{
+							{Path: ".github/workflows/ci.yml", RepositoryID: github.Ptr(int64(42))},
+						},
+					},
+				},
+			},
+			validate: func(t *testing.T, ruleset *github.RepositoryRuleset) {
+				want := &github.RepositoryRulesetRules{
+					RequiredDeployments: &github.RequiredDeploymentsRuleParameters{
+						RequiredDeploymentEnvironments: []string{"staging"},
+					},
+					MergeQueue: &github.MergeQueueRuleParameters{
+						CheckResponseTimeoutMinutes:  60,
+						GroupingStrategy:             github.MergeGroupingStrategyAllGreen,
+						MaxEntriesToBuild:            5,
+						MaxEntriesToMerge:            10,
+						MergeMethod:                  github.MergeQueueMergeMethodSquash,
+						MinEntriesToMerge:            1,
+						MinEntriesToMergeWaitMinutes: 5,
+					},
+					Workflows: &github.WorkflowsRuleParameters{
+						Workflows: []*github.RuleWorkflow{
+							{Path: ".github/workflows/ci.yml", RepositoryID: github.Ptr(int64(42))},
+						},
+					},
+				}
+
+				if diff := cmp.Diff(want, ruleset.Rules); diff != "" {
+					t.Errorf("rules mismatch (-want +got):\n%s", diff)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,6 +387,52 @@ func TestBuildRulesetFromConfig(t *testing.T) {
 	}
 }
 
+func TestMergeRulesetConfigAdditionalRules(t *testing.T) {
+	t.Parallel()
+
+	org := &configtypes.RulesetConfig{
+		Name:        "push-rules",
+		Target:      "push",
+		Enforcement: "active",
+		Rules: &configtypes.RulesetRulesConfig{
+			MaxFileSize: &configtypes.MaxFileSizeRuleConfig{MaxFileSize: 10},
+			FileExtensionRestriction: &configtypes.FileExtensionRestrictionRuleConfig{
+				RestrictedFileExtensions: []string{".exe"},
+			},
+		},
+	}
+
+	overrides := map[string]any{
+		"rules": map[string]any{
+			"max_file_size": map[string]any{"max_file_size": 50},
+			"branch_name_pattern": map[string]any{
+				"operator": "regex",
+				"pattern":  "^[a-z0-9/-]+$",
+			},
+		},
+	}
+
+	merged, err := mergeRulesetConfig(org, overrides, configtypes.MergeStrategyDeep)
+	if err != nil {
+		t.Fatalf("mergeRulesetConfig() error = %v", err)
+	}
+
+	want := &configtypes.RulesetRulesConfig{
+		MaxFileSize: &configtypes.MaxFileSizeRuleConfig{MaxFileSize: 50},
+		FileExtensionRestriction: &configtypes.FileExtensionRestrictionRuleConfig{
+			RestrictedFileExtensions: []string{".exe"},
+		},
+		BranchNamePattern: &configtypes.PatternRuleConfig{
+			Operator: "regex",
+			Pattern:  "^[a-z0-9/-]+$",
+		},
+	}
+
+	if diff := cmp.Diff(want, merged.Rules); diff != "" {
+		t.Errorf("merged rules mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGetRequiredReviewCountForRuleset(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -436,3 +583,379 @@ func TestBuildStatusChecksRuleOverridesExisting(t *testing.T) {
 		t.Errorf("expected 'new-check-2', got %q", rule.RequiredStatusChecks[1].Context)
 	}
 }
+
+func TestDiffRuleset(t *testing.T) {
+	t.Parallel()
+
+	config := configtypes.RulesetConfig{
+		Name:        "main-protection",
+		Target:      "branch",
+		Enforcement: "active",
+		Conditions: &configtypes.RulesetConditionsConfig{
+			RefName: &configtypes.RefNameCondition{
+				Include: []string{"~DEFAULT_BRANCH", "refs/heads/release/*"},
+			},
+		},
+		Rules: &configtypes.RulesetRulesConfig{
+			Deletion: github.Ptr(true),
+			PullRequest: &configtypes.PullRequestRuleConfig{
+				RequiredApprovingReviewCount: github.Ptr(1),
+				DismissStaleReviewsOnPush:    github.Ptr(true),
+			},
+		},
+	}
+
+	inSync := func() *github.RepositoryRuleset {
+		// Simulates a ruleset read back from the API, including server defaults.
+		target := github.RulesetTargetBranch
+
+		return &github.RepositoryRuleset{
+			ID:          github.Ptr(int64(1)),
+			Name:        "main-protection",
+			Target:      &target,
+			Enforcement: github.RulesetEnforcementActive,
+			Conditions: &github.RepositoryRulesetConditions{
+				RefName: &github.RepositoryRulesetRefConditionParameters{
+					Include: []string{"refs/heads/release/*", "~DEFAULT_BRANCH"},
+					Exclude: []string{},
+				},
+			},
+			Rules: &github.RepositoryRulesetRules{
+				Deletion: &github.EmptyRuleParameters{},
+				PullRequest: &github.PullRequestRuleParameters{
+					AllowedMergeMethods: []github.PullRequestMergeMethod{
+						github.PullRequestMergeMethodSquash,
+						github.PullRequestMergeMethodMerge,
+					},
+					RequiredApprovingReviewCount: 1,
+					DismissStaleReviewsOnPush:    true,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		current func() *github.RepositoryRuleset
+		want    []string
+	}{
+		{
+			name:    "in sync ignoring order and server defaults",
+			current: inSync,
+		},
+		{
+			name: "higher current review count is kept",
+			current: func() *github.RepositoryRuleset {
+				ruleset := inSync()
+				ruleset.Rules.PullRequest.RequiredApprovingReviewCount = 2
+
+				return ruleset
+			},
+		},
+		{
+			name: "changed fields and extra rule",
+			current: func() *github.RepositoryRuleset {
+				ruleset := inSync()
+				ruleset.Enforcement = github.RulesetEnforcementEvaluate
+				ruleset.Conditions.RefName.Include = []string{"~DEFAULT_BRANCH"}
+				ruleset.Rules.PullRequest.DismissStaleReviewsOnPush = false
+				ruleset.Rules.NonFastForward = &github.EmptyRuleParameters{}
+
+				return ruleset
+			},
+			want: []string{
+				"enforcement",
+				"conditions.ref_name.include",
+				"rules.non_fast_forward",
+				"rules.pull_request.dismiss_stale_reviews_on_push",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			current := tt.current()
+			diffs := diffRuleset(current, buildRulesetFromConfig(config, current))
+
+			var fields []string
+			for _, diff := range diffs {
+				fields = append(fields, diff.Field)
+			}
+
+			if diff := cmp.Diff(tt.want, fields); diff != "" {
+				t.Errorf("diffRuleset() fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyncRulesetsRemoval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		allowRemoval bool
+		want         []RulesetOutcome
+	}{
+		{
+			name: "keeps unmanaged rulesets by default",
+			want: []RulesetOutcome{
+				{Name: "org-sync/main", ID: 1, Action: ResourceActionUnchanged},
+			},
+		},
+		{
+			name:         "deletes only prefixed repository rulesets",
+			allowRemoval: true,
+			want: []RulesetOutcome{
+				{Name: "org-sync/main", ID: 1, Action: ResourceActionUnchanged},
+				{Name: "org-sync/legacy", ID: 2, Action: ResourceActionDeleted},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/rulesets": `[
+					{"id":1,"name":"org-sync/main","source_type":"Repository"},
+					{"id":2,"name":"org-sync/legacy","source_type":"Repository"},
+					{"id":3,"name":"manual","source_type":"Repository"}
+				]`,
+				"GET /repos/org/repo/rulesets/1": `{"id":1,"name":"org-sync/main",` +
+					`"target":"branch","enforcement":"active"}`,
+				"DELETE /repos/org/repo/rulesets/2": ``,
+			})
+
+			outcomes, err := SyncRulesets(
+				context.Background(),
+				logger.New("error"),
+				client,
+				"org",
+				"repo",
+				[]configtypes.RulesetConfig{
+					{Name: "org-sync/main", Target: "branch", Enforcement: "active"},
+				},
+				nil,
+				tt.allowRemoval,
+				nil,
+				false,
+			)
+			if err != nil {
+				t.Fatalf("SyncRulesets() unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, outcomes); diff != "" {
+				t.Errorf("SyncRulesets() outcomes mismatch (-want +got):\n%s", diff)
+			}
+
+			if fake.called("PUT /repos/org/repo/rulesets/1") {
+				t.Error("SyncRulesets() updated a ruleset that is already in sync")
+			}
+
+			if fake.called("DELETE /repos/org/repo/rulesets/3") {
+				t.Error("SyncRulesets() deleted a ruleset without the managed prefix")
+			}
+
+			if got := fake.called("DELETE /repos/org/repo/rulesets/2"); got != tt.allowRemoval {
+				t.Errorf("managed ruleset deleted = %v, want %v", got, tt.allowRemoval)
+			}
+		})
+	}
+}
+
+func TestSyncRulesetsWarnsAboutUnremovableRulesets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		allowRemoval bool
+		wantWarning  bool
+	}{
+		{name: "without removal"},
+		{name: "with removal", allowRemoval: true, wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/rulesets": `[]`,
+			})
+
+			var output bytes.Buffer
+
+			log := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{
+				Level: slog.LevelWarn,
+			}))
+
+			_, err := SyncRulesets(
+				context.Background(),
+				log,
+				client,
+				"org",
+				"repo",
+				[]configtypes.RulesetConfig{
+					{Name: "main-branch-protection", Target: "branch", Enforcement: "active"},
+					{Name: "org-sync/main", Target: "branch", Enforcement: "active"},
+				},
+				nil,
+				tt.allowRemoval,
+				nil,
+				true,
+			)
+			if err != nil {
+				t.Fatalf("SyncRulesets() unexpected error: %v", err)
+			}
+
+			warned := strings.Contains(output.String(), "name=main-branch-protection")
+			if warned != tt.wantWarning {
+				t.Errorf("warned about main-branch-protection = %v, want %v\n%s",
+					warned, tt.wantWarning, output.String())
+			}
+
+			if strings.Contains(output.String(), "name=org-sync/main ") {
+				t.Errorf("warned about a prefixed ruleset:\n%s", output.String())
+			}
+		})
+	}
+}
+
+func TestRepoRulesetStoreListPaginates(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("per_page = %q, want 100", r.URL.Query().Get("per_page"))
+		}
+
+		if r.URL.Query().Get("page") != "2" {
+			w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[{"id":1,"name":"org-sync/main"}]`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id":2,"name":"org-sync/release"}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	store := &repoRulesetStore{client: newTestClient(t, server.URL), org: "org", repo: "repo"}
+
+	rulesets, err := store.list(context.Background())
+	if err != nil {
+		t.Fatalf("list() error = %v", err)
+	}
+
+	var names []string
+	for _, ruleset := range rulesets {
+		names = append(names, ruleset.Name)
+	}
+
+	if diff := cmp.Diff([]string{"org-sync/main", "org-sync/release"}, names); diff != "" {
+		t.Errorf("list() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSyncRulesetsAdoptsUnprefixedRuleset(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/rulesets": `[
+			{"id":1,"name":"main","source_type":"Repository"}
+		]`,
+		"GET /repos/org/repo/rulesets/1": `{"id":1,"name":"main",` +
+			`"target":"branch","enforcement":"active"}`,
+		"PUT /repos/org/repo/rulesets/1": `{"id":1}`,
+	})
+
+	outcomes, err := SyncRulesets(
+		context.Background(),
+		logger.New("error"),
+		client,
+		"org",
+		"repo",
+		[]configtypes.RulesetConfig{
+			{Name: "org-sync/main", Target: "branch", Enforcement: "active"},
+		},
+		nil,
+		true,
+		nil,
+		false,
+	)
+	if err != nil {
+		t.Fatalf("SyncRulesets() unexpected error: %v", err)
+	}
+
+	want := []RulesetOutcome{
+		{
+			Name:   "org-sync/main",
+			ID:     1,
+			Action: ResourceActionUpdated,
+			Fields: []FieldDiff{{Field: "name", Current: "main", Desired: "org-sync/main"}},
+		},
+	}
+
+	if diff := cmp.Diff(want, outcomes); diff != "" {
+		t.Errorf("SyncRulesets() outcomes mismatch (-want +got):\n%s", diff)
+	}
+
+	if fake.called("POST /repos/org/repo/rulesets") {
+		t.Error("SyncRulesets() created a duplicate of the adopted ruleset")
+	}
+
+	if body := fake.body("PUT /repos/org/repo/rulesets/1"); !strings.Contains(
+		body, `"name":"org-sync/main"`,
+	) {
+		t.Errorf("adopted ruleset not renamed: %s", body)
+	}
+}
+
+func TestSyncRulesetsClearsBypassActors(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/rulesets": `[
+			{"id":1,"name":"org-sync/main","source_type":"Repository"}
+		]`,
+		"GET /repos/org/repo/rulesets/1": `{"id":1,"name":"org-sync/main",` +
+			`"target":"branch","enforcement":"active","bypass_actors":[` +
+			`{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"always"}]}`,
+		"PUT /repos/org/repo/rulesets/1": `{"id":1}`,
+	})
+
+	outcomes, err := SyncRulesets(
+		context.Background(),
+		logger.New("error"),
+		client,
+		"org",
+		"repo",
+		[]configtypes.RulesetConfig{{
+			Name:         "org-sync/main",
+			Target:       "branch",
+			Enforcement:  "active",
+			BypassActors: []configtypes.BypassActorConfig{},
+		}},
+		nil,
+		false,
+		&WeakeningPolicy{Allow: true},
+		false,
+	)
+	if err != nil {
+		t.Fatalf("SyncRulesets() unexpected error: %v", err)
+	}
+
+	if len(outcomes) != 1 || outcomes[0].Action != ResourceActionUpdated {
+		t.Fatalf("SyncRulesets() outcomes = %+v, want bypass actors updated", outcomes)
+	}
+
+	if body := fake.body("PUT /repos/org/repo/rulesets/1"); body != `{"bypass_actors":[]}`+"\n" {
+		t.Errorf("last ruleset update = %q, want bypass actors cleared", body)
+	}
+}
@@ -24,6 +24,7 @@ func VerifyAndCommitSchemaFromContent(
 	branch string,
 	schemaFile string,
 	generatedSchema []byte,
+	commitOpts *CommitOptions,
 	dryRun bool,
 ) (bool, error) {
 	const ownerNameParts = 2
@@ -138,9 +139,9 @@ Schema was out of sync with Go types. Regenerated from SyncConfig struct.`
 		Parents: []*github.Commit{{SHA: github.Ptr(currentSHA)}},
 	}
 
-	createdCommit, _, err := client.Git.CreateCommit(ctx, owner, name, newCommit, nil)
+	createdCommit, err := createCommit(ctx, client, owner, name, newCommit, commitOpts)
 	if err != nil {
-		return false, errors.Wrap(err, "creating commit")
+		return false, err
 	}
 
 	commitSHA := createdCommit.GetSHA()
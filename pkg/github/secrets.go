@@ -0,0 +1,282 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+var (
+	// ErrInvalidSecretSource is returned when a secret does not name exactly one of
+	// from_env or from_file.
+	ErrInvalidSecretSource = errors.New("secret needs exactly one of from_env or from_file")
+	// ErrSecretValueUnavailable is returned when the environment variable of a secret
+	// that has to be written is not set.
+	ErrSecretValueUnavailable = errors.New("secret value unavailable")
+	// ErrInvalidPublicKey is returned when the repository public key cannot be used to
+	// encrypt secrets.
+	ErrInvalidPublicKey = errors.New("invalid repository public key")
+)
+
+const (
+	// secretsPerPage is the page size used when listing secrets.
+	secretsPerPage = 100
+	// publicKeySize is the size of the Curve25519 key secrets are sealed with.
+	publicKeySize = 32
+)
+
+// secretChange is a planned secret operation.
+type secretChange struct {
+	SecretOutcome
+
+	Config configtypes.SecretConfig
+}
+
+// SyncSecrets synchronizes GitHub Actions repository secrets from configuration to
+// target repository. Secrets cannot be read back, so only missing secrets and secrets
+// marked with force are written. Values are read from the environment or files only
+// when written and never logged or recorded.
+func SyncSecrets(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	secrets []configtypes.SecretConfig,
+	exclude []string,
+	dryRun bool,
+) ([]SecretOutcome, error) {
+	if isSettingExcluded("secrets", exclude) {
+		log.Debug("secrets sync excluded by config")
+
+		return nil, nil
+	}
+
+	if len(secrets) == 0 {
+		log.Debug("no secrets configured")
+
+		return nil, nil
+	}
+
+	changes, err := planSecrets(ctx, client, org, repo, secrets, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if change.Action != ResourceActionUnchanged {
+			log.Info("secret to write", "name", change.Name, "action", change.Action)
+		}
+	}
+
+	if dryRun {
+		log.Info("dry-run mode: skipping secret changes", "count", len(changes))
+
+		return secretOutcomes(changes), nil
+	}
+
+	if err := applySecretChanges(ctx, client, org, repo, changes); err != nil {
+		return secretOutcomes(changes), err
+	}
+
+	log.Info("secrets synced successfully", "count", len(changes))
+
+	return secretOutcomes(changes), nil
+}
+
+// planSecrets returns the operation needed for each configured secret.
+func planSecrets(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	secrets []configtypes.SecretConfig,
+	exclude []string,
+) ([]secretChange, error) {
+	existing, err := listSecretNames(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching existing secrets")
+	}
+
+	changes := make([]secretChange, 0, len(secrets))
+
+	for _, secret := range secrets {
+		if isSettingExcluded("secrets."+secret.Name, exclude) {
+			continue
+		}
+
+		if (secret.FromEnv == "") == (secret.FromFile == "") {
+			return nil, errors.Wrapf(ErrInvalidSecretSource, "secret %q", secret.Name)
+		}
+
+		change := secretChange{
+			SecretOutcome: SecretOutcome{Name: secret.Name, Action: ResourceActionUnchanged},
+			Config:        secret,
+		}
+
+		// GitHub stores secret names in upper case
+		switch {
+		case !existing[strings.ToUpper(secret.Name)]:
+			change.Action = ResourceActionCreated
+		case secret.Force:
+			change.Action = ResourceActionUpdated
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// listSecretNames returns the names of all repository secrets.
+func listSecretNames(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (map[string]bool, error) {
+	opts := &github.ListOptions{PerPage: secretsPerPage}
+	names := make(map[string]bool)
+
+	for {
+		page, resp, err := client.Actions.ListRepoSecrets(ctx, org, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing secrets")
+		}
+
+		for _, secret := range page.Secrets {
+			names[strings.ToUpper(secret.Name)] = true
+		}
+
+		if resp.NextPage == 0 {
+			return names, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// applySecretChanges encrypts and writes the planned secrets. The repository public
+// key is only fetched when a secret has to be written.
+func applySecretChanges(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	changes []secretChange,
+) error {
+	var publicKey *github.PublicKey
+
+	for _, change := range changes {
+		if change.Action == ResourceActionUnchanged {
+			continue
+		}
+
+		if publicKey == nil {
+			key, _, err := client.Actions.GetRepoPublicKey(ctx, org, repo)
+			if err != nil {
+				return errors.Wrap(err, "getting repository public key")
+			}
+
+			publicKey = key
+		}
+
+		value, err := readSecretValue(change.Config)
+		if err != nil {
+			return errors.Wrapf(err, "reading secret %q", change.Name)
+		}
+
+		encrypted, err := encryptSecret(publicKey, value)
+		if err != nil {
+			return errors.Wrapf(err, "encrypting secret %q", change.Name)
+		}
+
+		_, err = client.Actions.CreateOrUpdateRepoSecret(ctx, org, repo, &github.EncryptedSecret{
+			Name:           change.Name,
+			KeyID:          publicKey.GetKeyID(),
+			EncryptedValue: encrypted,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "writing secret %q", change.Name)
+		}
+	}
+
+	return nil
+}
+
+// readSecretValue reads the value of a secret from its environment variable or file.
+func readSecretValue(secret configtypes.SecretConfig) ([]byte, error) {
+	if secret.FromFile != "" {
+		value, err := os.ReadFile(secret.FromFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading secret file")
+		}
+
+		return value, nil
+	}
+
+	value, ok := os.LookupEnv(secret.FromEnv)
+	if !ok {
+		return nil, errors.Wrapf(
+			ErrSecretValueUnavailable, "environment variable %q is not set", secret.FromEnv,
+		)
+	}
+
+	return []byte(value), nil
+}
+
+// encryptSecret seals value with the repository public key as required by the
+// secrets API and returns it base64 encoded.
+func encryptSecret(publicKey *github.PublicKey, value []byte) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(publicKey.GetKey())
+	if err != nil {
+		return "", errors.Wrapf(ErrInvalidPublicKey, "decoding key: %v", err)
+	}
+
+	var key [publicKeySize]byte
+	if len(decoded) != publicKeySize {
+		return "", errors.Wrapf(ErrInvalidPublicKey, "key is %d bytes long", len(decoded))
+	}
+
+	copy(key[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, value, &key, rand.Reader)
+	if err != nil {
+		return "", errors.Wrap(err, "sealing secret")
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// secretOutcomes returns the exported outcomes of planned changes.
+func secretOutcomes(changes []secretChange) []SecretOutcome {
+	outcomes := make([]SecretOutcome, 0, len(changes))
+
+	for _, change := range changes {
+		outcomes = append(outcomes, change.SecretOutcome)
+	}
+
+	return outcomes
+}
+
+// countSecretChanges returns the number of secrets created or updated.
+func countSecretChanges(outcomes []SecretOutcome) int {
+	count := 0
+
+	for _, outcome := range outcomes {
+		if outcome.Action != ResourceActionUnchanged {
+			count++
+		}
+	}
+
+	return count
+}
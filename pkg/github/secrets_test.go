@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestSyncSecrets(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	valueFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(valueFile, []byte("s3cr3t"), 0o600); err != nil {
+		t.Fatalf("writing secret file: %v", err)
+	}
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/actions/secrets": `{"total_count":2,"secrets":[
+			{"name":"EXISTING"},{"name":"FORCED"}]}`,
+		"GET /repos/org/repo/actions/secrets/public-key": `{"key_id":"k1","key":"` +
+			base64.StdEncoding.EncodeToString(publicKey[:]) + `"}`,
+		"PUT /repos/org/repo/actions/secrets/FORCED": ``,
+		"PUT /repos/org/repo/actions/secrets/NEW":    ``,
+	})
+
+	secrets := []configtypes.SecretConfig{
+		{Name: "EXISTING", FromFile: valueFile},
+		{Name: "FORCED", FromFile: valueFile, Force: true},
+		{Name: "NEW", FromFile: valueFile},
+	}
+
+	outcomes, err := SyncSecrets(
+		context.Background(), logger.New("error"), client, "org", "repo", secrets, nil, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncSecrets() error = %v", err)
+	}
+
+	want := []SecretOutcome{
+		{Name: "EXISTING", Action: ResourceActionUnchanged},
+		{Name: "FORCED", Action: ResourceActionUpdated},
+		{Name: "NEW", Action: ResourceActionCreated},
+	}
+
+	if diff := cmp.Diff(want, outcomes); diff != "" {
+		t.Errorf("outcomes mismatch (-want +got):\n%s", diff)
+	}
+
+	if fake.called("PUT /repos/org/repo/actions/secrets/EXISTING") {
+		t.Error("expected existing secret not to be written")
+	}
+
+	body := fake.body("PUT /repos/org/repo/actions/secrets/NEW")
+	if strings.Contains(body, "s3cr3t") {
+		t.Fatal("secret value sent unencrypted")
+	}
+
+	var request struct {
+		KeyID          string `json:"key_id"`
+		EncryptedValue string `json:"encrypted_value"`
+	}
+
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		t.Fatalf("decoding request body: %v", err)
+	}
+
+	if request.KeyID != "k1" {
+		t.Errorf("key_id = %q, want k1", request.KeyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(request.EncryptedValue)
+	if err != nil {
+		t.Fatalf("decoding encrypted value: %v", err)
+	}
+
+	opened, ok := box.OpenAnonymous(nil, sealed, publicKey, privateKey)
+	if !ok || string(opened) != "s3cr3t" {
+		t.Errorf("decrypted value = %q (ok %t), want s3cr3t", opened, ok)
+	}
+}
+
+func TestSyncSecretsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		secret  configtypes.SecretConfig
+		wantErr error
+	}{
+		{
+			name:    "no source",
+			secret:  configtypes.SecretConfig{Name: "NEW"},
+			wantErr: ErrInvalidSecretSource,
+		},
+		{
+			name: "both sources",
+			secret: configtypes.SecretConfig{
+				Name: "NEW", FromEnv: "DOTSYNC_TEST_SECRET", FromFile: "secret",
+			},
+			wantErr: ErrInvalidSecretSource,
+		},
+		{
+			name:    "unset environment variable",
+			secret:  configtypes.SecretConfig{Name: "NEW", FromEnv: "DOTSYNC_TEST_UNSET_SECRET"},
+			wantErr: ErrSecretValueUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key := base64.StdEncoding.EncodeToString(make([]byte, publicKeySize))
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/actions/secrets": `{"total_count":0,"secrets":[]}`,
+				"GET /repos/org/repo/actions/secrets/public-key": `{
+					"key_id":"k1","key":"` + key + `"}`,
+			})
+
+			_, err := SyncSecrets(
+				context.Background(), logger.New("error"), client, "org", "repo",
+				[]configtypes.SecretConfig{tt.secret}, nil, false,
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SyncSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if fake.called("PUT /repos/org/repo/actions/secrets/NEW") {
+				t.Error("expected secret not to be written")
+			}
+		})
+	}
+}
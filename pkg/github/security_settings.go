@@ -0,0 +1,471 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const (
+	securityStatusEnabled    = "enabled"
+	securityStatusDisabled   = "disabled"
+	codeScanningConfigured   = "configured"
+	codeScanningUnconfigured = "not-configured"
+)
+
+// securityChange is a planned update of security features managed through their own
+// endpoints. Each request is nil when its feature is already in sync. Unsupported
+// lists the settings not available for the repository.
+type securityChange struct {
+	Fields              []FieldDiff
+	Unsupported         []string
+	VulnerabilityAlerts *bool
+	PrivateReporting    *bool
+	NonProviderPatterns *string
+	CodeScanning        *github.UpdateDefaultSetupConfigurationOptions
+}
+
+// repositorySecurityAnalysis holds the security and analysis settings go-github does
+// not model.
+type repositorySecurityAnalysis struct {
+	SecurityAndAnalysis struct {
+		SecretScanningNonProviderPatterns *github.SecretScanning `json:"secret_scanning_non_provider_patterns,omitempty"`
+	} `json:"security_and_analysis"`
+}
+
+// planSecurityFeatures compares the security features that are not part of the
+// repository resource with the desired state. Features unavailable for the repository,
+// e.g. code scanning on private repositories without GitHub Advanced Security, are
+// recorded as unsupported instead of failing. Returns nil when nothing needs updating
+// and nothing is unsupported.
+func planSecurityFeatures(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.SecurityConfig,
+	current *github.Repository,
+	exclude []string,
+) (*securityChange, error) {
+	if isSettingExcluded("security", exclude) {
+		return nil, nil //nolint:nilnil // excluded section has no changes
+	}
+
+	change := &securityChange{
+		Unsupported: unavailableSecurityFeatures(desired, current, exclude),
+	}
+
+	steps := []func(context.Context, *Client, string, string,
+		*configtypes.SecurityConfig, []string, *securityChange) error{
+		planNonProviderPatterns,
+		planVulnerabilityAlerts,
+		planPrivateReporting,
+		planCodeScanningDefaultSetup,
+	}
+
+	for _, step := range steps {
+		if err := step(ctx, client, org, repo, desired, exclude, change); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(change.Fields) == 0 && len(change.Unsupported) == 0 {
+		return nil, nil //nolint:nilnil // in sync
+	}
+
+	return change, nil
+}
+
+// unavailableSecurityFeatures returns the configured security settings the repository
+// does not offer. Validity checks are synced with the repository resource by
+// computeSecurityDiff and only reported where available. Private vulnerability
+// reporting is limited to public repositories.
+func unavailableSecurityFeatures(
+	desired *configtypes.SecurityConfig,
+	current *github.Repository,
+	exclude []string,
+) []string {
+	var unsupported []string
+
+	const validityChecks = "security.secret_scanning_validity_checks"
+
+	if desired.SecretScanningValidityChecks != nil &&
+		current.GetSecurityAndAnalysis().GetSecretScanningValidityChecks() == nil &&
+		!isSettingExcluded(validityChecks, exclude) {
+		unsupported = append(unsupported, validityChecks)
+	}
+
+	const privateReporting = "security.private_vulnerability_reporting"
+
+	if desired.PrivateVulnerabilityReporting != nil && current.GetPrivate() &&
+		!isSettingExcluded(privateReporting, exclude) {
+		unsupported = append(unsupported, privateReporting)
+	}
+
+	return unsupported
+}
+
+// planNonProviderPatterns compares whether secret scanning detects non-provider
+// patterns. The repository resource only reports the setting where it is available.
+func planNonProviderPatterns(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.SecurityConfig,
+	exclude []string,
+	change *securityChange,
+) error {
+	const field = "security.secret_scanning_non_provider_patterns"
+
+	status := desired.SecretScanningNonProviderPatterns
+	if status == nil || isSettingExcluded(field, exclude) {
+		return nil
+	}
+
+	patterns, err := fetchNonProviderPatterns(ctx, client, org, repo)
+	if err != nil {
+		return err
+	}
+
+	if patterns == nil {
+		change.Unsupported = append(change.Unsupported, field)
+
+		return nil
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	change.Fields = appendDiff(change.Fields, field, patterns.GetStatus(), *status)
+
+	if len(change.Fields) > fieldsBefore {
+		change.NonProviderPatterns = status
+	}
+
+	return nil
+}
+
+// fetchNonProviderPatterns returns whether secret scanning detects non-provider
+// patterns, or nil when the repository does not report the setting.
+func fetchNonProviderPatterns(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (*github.SecretScanning, error) {
+	req, err := client.NewRequest(http.MethodGet, "repos/"+org+"/"+repo, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating repository request")
+	}
+
+	var current repositorySecurityAnalysis
+
+	if _, err := client.Do(ctx, req, &current); err != nil {
+		return nil, errors.Wrap(err, "getting security and analysis settings")
+	}
+
+	return current.SecurityAndAnalysis.SecretScanningNonProviderPatterns, nil
+}
+
+// planVulnerabilityAlerts compares whether Dependabot vulnerability alerts are
+// enabled.
+func planVulnerabilityAlerts(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.SecurityConfig,
+	exclude []string,
+	change *securityChange,
+) error {
+	const field = "security.vulnerability_alerts"
+
+	status := desired.VulnerabilityAlerts
+	if status == nil || isSettingExcluded(field, exclude) {
+		return nil
+	}
+
+	enabled, _, err := client.Repositories.GetVulnerabilityAlerts(ctx, org, repo)
+	if err != nil {
+		if isUnsupportedFeatureError(err) {
+			change.Unsupported = append(change.Unsupported, field)
+
+			return nil
+		}
+
+		return errors.Wrap(err, "getting vulnerability alerts")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	change.Fields = appendDiff(change.Fields, field, securityStatus(enabled), *status)
+
+	if len(change.Fields) > fieldsBefore {
+		change.VulnerabilityAlerts = github.Ptr(*status == securityStatusEnabled)
+	}
+
+	return nil
+}
+
+// planPrivateReporting compares whether private vulnerability reporting is enabled.
+func planPrivateReporting(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.SecurityConfig,
+	exclude []string,
+	change *securityChange,
+) error {
+	const field = "security.private_vulnerability_reporting"
+
+	status := desired.PrivateVulnerabilityReporting
+	if status == nil || isSettingExcluded(field, exclude) ||
+		slices.Contains(change.Unsupported, field) {
+		return nil
+	}
+
+	enabled, _, err := client.Repositories.IsPrivateReportingEnabled(ctx, org, repo)
+	if err != nil {
+		if isUnsupportedFeatureError(err) {
+			change.Unsupported = append(change.Unsupported, field)
+
+			return nil
+		}
+
+		return errors.Wrap(err, "getting private vulnerability reporting")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	change.Fields = appendDiff(change.Fields, field, securityStatus(enabled), *status)
+
+	if len(change.Fields) > fieldsBefore {
+		change.PrivateReporting = github.Ptr(*status == securityStatusEnabled)
+	}
+
+	return nil
+}
+
+// planCodeScanningDefaultSetup compares the code scanning default setup. Languages and
+// query suite are only compared while default setup is configured.
+func planCodeScanningDefaultSetup(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	desired *configtypes.SecurityConfig,
+	exclude []string,
+	change *securityChange,
+) error {
+	const field = "security.code_scanning_default_setup"
+
+	setup := desired.CodeScanningDefaultSetup
+	if setup == nil || isSettingExcluded(field, exclude) {
+		return nil
+	}
+
+	current, _, err := client.CodeScanning.GetDefaultSetupConfiguration(ctx, org, repo)
+	if err != nil {
+		if isUnsupportedFeatureError(err) {
+			change.Unsupported = append(change.Unsupported, field)
+
+			return nil
+		}
+
+		return errors.Wrap(err, "getting code scanning default setup")
+	}
+
+	fieldsBefore := len(change.Fields)
+
+	update := &github.UpdateDefaultSetupConfigurationOptions{
+		State:      current.GetState(),
+		QuerySuite: current.QuerySuite,
+		Languages:  current.Languages,
+	}
+
+	if setup.State != nil && !isSettingExcluded(field+".state", exclude) {
+		change.Fields = appendDiff(change.Fields, field+".state",
+			current.GetState(), *setup.State)
+		update.State = *setup.State
+	}
+
+	if update.State == codeScanningConfigured {
+		if setup.Languages != nil && !isSettingExcluded(field+".languages", exclude) {
+			change.Fields = appendSetDiff(change.Fields, field+".languages",
+				current.Languages, setup.Languages)
+			update.Languages = setup.Languages
+		}
+
+		if setup.QuerySuite != nil && !isSettingExcluded(field+".query_suite", exclude) {
+			change.Fields = appendDiff(change.Fields, field+".query_suite",
+				current.GetQuerySuite(), *setup.QuerySuite)
+			update.QuerySuite = setup.QuerySuite
+		}
+	}
+
+	if len(change.Fields) == fieldsBefore {
+		return nil
+	}
+
+	// Languages and query suite are rejected when disabling default setup
+	if update.State == codeScanningUnconfigured {
+		update.Languages = nil
+		update.QuerySuite = nil
+	}
+
+	change.CodeScanning = update
+
+	return nil
+}
+
+// applySecurityChange applies planned security feature updates.
+func applySecurityChange(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	change *securityChange,
+) error {
+	if change.NonProviderPatterns != nil {
+		err := updateNonProviderPatterns(ctx, client, org, repo, *change.NonProviderPatterns)
+		if err != nil {
+			return err
+		}
+	}
+
+	if change.VulnerabilityAlerts != nil {
+		update := client.Repositories.DisableVulnerabilityAlerts
+		if *change.VulnerabilityAlerts {
+			update = client.Repositories.EnableVulnerabilityAlerts
+		}
+
+		if _, err := update(ctx, org, repo); err != nil {
+			return errors.Wrap(err, "updating vulnerability alerts")
+		}
+	}
+
+	if change.PrivateReporting != nil {
+		update := client.Repositories.DisablePrivateReporting
+		if *change.PrivateReporting {
+			update = client.Repositories.EnablePrivateReporting
+		}
+
+		if _, err := update(ctx, org, repo); err != nil {
+			return errors.Wrap(err, "updating private vulnerability reporting")
+		}
+	}
+
+	if change.CodeScanning != nil {
+		_, _, err := client.CodeScanning.UpdateDefaultSetupConfiguration(
+			ctx, org, repo, change.CodeScanning,
+		)
+		if err != nil {
+			return errors.Wrap(err, "updating code scanning default setup")
+		}
+	}
+
+	return nil
+}
+
+// updateNonProviderPatterns enables or disables secret scanning of non-provider
+// patterns, which go-github's repository edit request does not support.
+func updateNonProviderPatterns(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	status string,
+) error {
+	var body repositorySecurityAnalysis
+
+	body.SecurityAndAnalysis.SecretScanningNonProviderPatterns = &github.SecretScanning{
+		Status: github.Ptr(status),
+	}
+
+	req, err := client.NewRequest(http.MethodPatch, "repos/"+org+"/"+repo, body)
+	if err != nil {
+		return errors.Wrap(err, "creating repository request")
+	}
+
+	if _, err := client.Do(ctx, req, nil); err != nil {
+		return errors.Wrap(err, "updating secret scanning non-provider patterns")
+	}
+
+	return nil
+}
+
+// isUnsupportedFeatureError reports whether the API rejected a request because a
+// feature is not available for the repository. Forbidden responses only count when
+// they name GitHub Advanced Security; others point to missing token permissions.
+func isUnsupportedFeatureError(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil {
+		return false
+	}
+
+	switch ghErr.Response.StatusCode {
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return true
+	case http.StatusForbidden:
+		return strings.Contains(strings.ToLower(ghErr.Message), "advanced security")
+	default:
+		return false
+	}
+}
+
+// securityStatus returns the enabled or disabled status of a feature.
+func securityStatus(enabled bool) string {
+	if enabled {
+		return securityStatusEnabled
+	}
+
+	return securityStatusDisabled
+}
+
+// logSecurityChange logs planned security feature changes and unsupported features.
+func logSecurityChange(log *logger.Logger, change *securityChange) {
+	if change == nil {
+		return
+	}
+
+	for _, field := range change.Unsupported {
+		log.Warn("security feature unsupported by repository", "setting", field)
+	}
+
+	if len(change.Fields) == 0 {
+		return
+	}
+
+	log.Info("security settings to update:")
+
+	for _, diff := range change.Fields {
+		log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+	}
+}
+
+// securityDiffs returns the field diffs of a planned security change.
+func securityDiffs(change *securityChange) []FieldDiff {
+	if change == nil {
+		return nil
+	}
+
+	return slices.Clone(change.Fields)
+}
+
+// unsupportedSecuritySettings returns the settings of a planned security change that
+// are not available for the repository.
+func unsupportedSecuritySettings(change *securityChange) []string {
+	if change == nil {
+		return nil
+	}
+
+	return slices.Clone(change.Unsupported)
+}
@@ -0,0 +1,200 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+)
+
+func TestPlanSecurityFeatures(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo": `{"security_and_analysis":{
+			"secret_scanning_non_provider_patterns":{"status":"disabled"}}}`,
+		"GET /repos/org/repo/private-vulnerability-reporting": `{"enabled":false}`,
+		"PATCH /repos/org/repo":                               `{}`,
+		"PUT /repos/org/repo/vulnerability-alerts":            ``,
+		"PUT /repos/org/repo/private-vulnerability-reporting": ``,
+	})
+
+	desired := &configtypes.SecurityConfig{
+		SecretScanningValidityChecks:      github.Ptr("enabled"),
+		SecretScanningNonProviderPatterns: github.Ptr("enabled"),
+		VulnerabilityAlerts:               github.Ptr("enabled"),
+		PrivateVulnerabilityReporting:     github.Ptr("enabled"),
+		CodeScanningDefaultSetup: &configtypes.CodeScanningDefaultSetupConfig{
+			State: github.Ptr("configured"),
+		},
+	}
+
+	change, err := planSecurityFeatures(
+		context.Background(), client, "org", "repo", desired, &github.Repository{}, nil,
+	)
+	if err != nil {
+		t.Fatalf("planSecurityFeatures() error = %v", err)
+	}
+
+	wantFields := []FieldDiff{
+		{
+			Field:   "security.secret_scanning_non_provider_patterns",
+			Current: "disabled",
+			Desired: "enabled",
+		},
+		{Field: "security.vulnerability_alerts", Current: "disabled", Desired: "enabled"},
+		{
+			Field:   "security.private_vulnerability_reporting",
+			Current: "disabled",
+			Desired: "enabled",
+		},
+	}
+
+	if diff := cmp.Diff(wantFields, securityDiffs(change)); diff != "" {
+		t.Errorf("fields mismatch (-want +got):\n%s", diff)
+	}
+
+	wantUnsupported := []string{
+		"security.secret_scanning_validity_checks",
+		"security.code_scanning_default_setup",
+	}
+
+	if diff := cmp.Diff(wantUnsupported, unsupportedSecuritySettings(change)); diff != "" {
+		t.Errorf("unsupported mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := applySecurityChange(context.Background(), client, "org", "repo", change); err != nil {
+		t.Fatalf("applySecurityChange() error = %v", err)
+	}
+
+	body := fake.body("PATCH /repos/org/repo")
+	if !strings.Contains(body, `"secret_scanning_non_provider_patterns":{"status":"enabled"}`) {
+		t.Errorf("non-provider patterns not enabled: %s", body)
+	}
+
+	for _, key := range []string{
+		"PUT /repos/org/repo/vulnerability-alerts",
+		"PUT /repos/org/repo/private-vulnerability-reporting",
+	} {
+		if !fake.called(key) {
+			t.Errorf("expected request %s", key)
+		}
+	}
+}
+
+func TestPlanCodeScanningDefaultSetup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current string
+		setup   configtypes.CodeScanningDefaultSetupConfig
+		want    *github.UpdateDefaultSetupConfigurationOptions
+	}{
+		{
+			name:    "in sync",
+			current: `{"state":"configured","languages":["go"],"query_suite":"default"}`,
+			setup: configtypes.CodeScanningDefaultSetupConfig{
+				State:     github.Ptr("configured"),
+				Languages: []string{"go"},
+			},
+		},
+		{
+			name:    "languages and query suite",
+			current: `{"state":"configured","languages":["go"],"query_suite":"default"}`,
+			setup: configtypes.CodeScanningDefaultSetupConfig{
+				Languages:  []string{"go", "actions"},
+				QuerySuite: github.Ptr("extended"),
+			},
+			want: &github.UpdateDefaultSetupConfigurationOptions{
+				State:      "configured",
+				Languages:  []string{"go", "actions"},
+				QuerySuite: github.Ptr("extended"),
+			},
+		},
+		{
+			name:    "disable",
+			current: `{"state":"configured","languages":["go"],"query_suite":"default"}`,
+			setup: configtypes.CodeScanningDefaultSetupConfig{
+				State:     github.Ptr("not-configured"),
+				Languages: []string{"go", "actions"},
+			},
+			want: &github.UpdateDefaultSetupConfigurationOptions{State: "not-configured"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/code-scanning/default-setup": tt.current,
+			})
+
+			desired := &configtypes.SecurityConfig{CodeScanningDefaultSetup: &tt.setup}
+			change := &securityChange{}
+
+			err := planCodeScanningDefaultSetup(
+				context.Background(), client, "org", "repo", desired, nil, change,
+			)
+			if err != nil {
+				t.Fatalf("planCodeScanningDefaultSetup() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, change.CodeScanning); diff != "" {
+				t.Errorf("update mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsUnsupportedFeatureError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		message string
+		want    bool
+	}{
+		{name: "not found", status: http.StatusNotFound, message: "Not Found", want: true},
+		{
+			name:    "unprocessable",
+			status:  http.StatusUnprocessableEntity,
+			message: "Validation Failed",
+			want:    true,
+		},
+		{
+			name:    "forbidden without Advanced Security",
+			status:  http.StatusForbidden,
+			message: "Advanced Security must be enabled for this repository to use code scanning.",
+			want:    true,
+		},
+		{
+			name:    "forbidden by token permissions",
+			status:  http.StatusForbidden,
+			message: "Resource not accessible by integration",
+		},
+		{name: "server error", status: http.StatusInternalServerError, message: "Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := &github.ErrorResponse{
+				Response: &http.Response{StatusCode: tt.status},
+				Message:  tt.message,
+			}
+
+			if got := isUnsupportedFeatureError(err); got != tt.want {
+				t.Errorf("isUnsupportedFeatureError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
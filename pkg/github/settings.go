@@ -3,7 +3,8 @@ package github
 import (
 	"context"
 	"os"
-	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/cockroachdb/errors"
@@ -16,19 +17,31 @@ import (
 
 // SettingsFile represents the structure of a settings YAML file.
 type SettingsFile struct {
-	Settings SettingsDefinition `yaml:"settings"`
+	Settings SettingsDefinition `json:"settings" yaml:"settings"`
 }
 
-// SettingsDefinition contains all repository settings to sync.
+// SettingsDefinition contains all repository settings to sync. Organization rulesets are
+// synced once per organization by `dotsync settings sync-org`.
 type SettingsDefinition struct {
-	Repository       configtypes.RepositorySettingsConfig     `yaml:"repository"`
-	Features         configtypes.FeaturesConfig               `yaml:"features"`
-	Security         configtypes.SecurityConfig               `yaml:"security"`
-	BranchProtection []configtypes.BranchProtectionRuleConfig `yaml:"branch_protection"`
-	Rulesets         []configtypes.RulesetConfig              `yaml:"rulesets"`
+	Repository       configtypes.RepositorySettingsConfig     `json:"repository"        yaml:"repository"`
+	Features         configtypes.FeaturesConfig               `json:"features"          yaml:"features"`
+	Security         configtypes.SecurityConfig               `json:"security"          yaml:"security"`
+	Actions          configtypes.ActionsConfig                `json:"actions"           yaml:"actions"`
+	Access           configtypes.AccessConfig                 `json:"access"            yaml:"access"`
+	BranchProtection []configtypes.BranchProtectionRuleConfig `json:"branch_protection" yaml:"branch_protection"`
+	Rulesets         []configtypes.RulesetConfig              `json:"rulesets"          yaml:"rulesets"`
+	Environments     []configtypes.EnvironmentConfig          `json:"environments"      yaml:"environments"`
+	Variables        []configtypes.VariableConfig             `json:"variables"         yaml:"variables"`
+	Secrets          []configtypes.SecretConfig               `json:"secrets"           yaml:"secrets"`
+	Webhooks         []configtypes.WebhookConfig              `json:"webhooks"          yaml:"webhooks"`
+	CustomProperties map[string]any                           `json:"custom_properties" yaml:"custom_properties"`
+	OrgRulesets      []configtypes.OrgRulesetConfig           `json:"org_rulesets"      yaml:"org_rulesets"`
+	Profiles         []configtypes.SettingsProfileConfig      `json:"profiles"          yaml:"profiles"`
 }
 
 // SyncSettings synchronizes repository settings from a YAML file to a target repository.
+// Changes weakening the repository's security are blocked and reported unless
+// allowWeakening is set or the sync config justifies them.
 func SyncSettings(
 	ctx context.Context,
 	log *logger.Logger,
@@ -37,6 +50,7 @@ func SyncSettings(
 	repo string,
 	settingsFile string,
 	syncConfig *configtypes.SyncConfig,
+	allowWeakening bool,
 	dryRun bool,
 ) (*SettingsSyncResult, error) {
 	result := NewSettingsSyncResult(repo, dryRun)
@@ -57,6 +71,16 @@ func SyncSettings(
 		return result, err
 	}
 
+	// Layer the settings profile assigned to the repository on top of the base settings
+	desiredSettings, result.Profile, err = ApplySettingsProfile(
+		ctx, log, client, org, repo, desiredSettings, currentRepo, syncConfig,
+	)
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "applying settings profile"))
+
+		return result, err
+	}
+
 	// Apply merge configurations if present
 	desiredSettings, err = ApplySettingsMerge(log, desiredSettings, syncConfig)
 	if err != nil {
@@ -65,43 +89,104 @@ func SyncSettings(
 		return result, err
 	}
 
+	// Require the checks of the repository's workflows where discovery is enabled
+	err = resolveDiscoveredStatusChecks(
+		ctx, log, client, org, repo, desiredSettings, currentRepo.GetDefaultBranch(),
+	)
+	if err != nil {
+		result.CompleteWithError(err)
+
+		return result, err
+	}
+
 	// Compute all changes
-	repoChanges, hasBranchProtectionChanges := computeAllSettingsChanges(
+	repoChanges, manageBranchProtection := computeAllSettingsChanges(
 		desiredSettings,
 		currentRepo,
 		syncConfig.Sync.Settings.Exclude,
 	)
 
+	var protectionChanges []branchProtectionChange
+
+	if manageBranchProtection {
+		protectionChanges, err = planBranchProtection(
+			ctx, log, client, org, repo, desiredSettings.BranchProtection,
+		)
+		if err != nil {
+			result.CompleteWithError(errors.Wrap(err, "computing branch protection diff"))
+
+			return result, err
+		}
+	}
+
+	actionsChanges, err := planActionsSettings(
+		ctx, client, org, repo, &desiredSettings.Actions, currentRepo,
+		syncConfig.Sync.Settings.Exclude,
+	)
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "computing actions settings diff"))
+
+		return result, err
+	}
+
+	securityChanges, err := planSecurityFeatures(
+		ctx, client, org, repo, &desiredSettings.Security, currentRepo,
+		syncConfig.Sync.Settings.Exclude,
+	)
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "computing security settings diff"))
+
+		return result, err
+	}
+
+	// Keep settings whose changes would weaken the repository's security
+	policy := &WeakeningPolicy{
+		Allow:         allowWeakening,
+		Justification: syncConfig.Sync.Settings.WeakeningJustification,
+	}
+
+	repoChanges = policy.reviewRepositorySecurity(log, repoChanges, currentRepo)
+	protectionChanges = policy.reviewBranchProtection(log, protectionChanges)
+
 	log.Info("computed settings diff",
 		"has_repo_changes", repoChanges != nil,
-		"has_branch_protection", hasBranchProtectionChanges,
+		"branch_protection_changes", len(protectionChanges),
+		"has_actions_changes", len(actionsDiffs(actionsChanges)) > 0,
+		"security_changes", len(securityDiffs(securityChanges)),
 	)
 
 	// Count changes
-	changesCount := 0
+	changesCount := len(protectionChanges)
 	if repoChanges != nil {
 		changesCount++
 	}
 
-	if hasBranchProtectionChanges {
+	if len(actionsDiffs(actionsChanges)) > 0 {
+		changesCount++
+	}
+
+	if len(securityDiffs(securityChanges)) > 0 {
 		changesCount++
 	}
 
 	result.ChangesApplied = changesCount
+	result.BranchProtection = branchProtectionDiffs(protectionChanges)
+	result.Actions = actionsDiffs(actionsChanges)
+	result.Security = securityDiffs(securityChanges)
+	result.Unsupported = append(
+		unsupportedActionsSettings(actionsChanges),
+		unsupportedSecuritySettings(securityChanges)...,
+	)
+	result.Ignored = ignoredActionsSettings(actionsChanges)
+	result.Blocked = policy.Blocked
 
 	// Handle dry-run mode or apply changes
 	if dryRun {
-		err = handleDryRun(log, repoChanges, hasBranchProtectionChanges, desiredSettings)
+		err = handleDryRun(log, repoChanges, protectionChanges, actionsChanges, securityChanges)
 	} else {
 		err = applyAllSettingsChanges(
-			ctx,
-			log,
-			client,
-			org,
-			repo,
-			repoChanges,
-			hasBranchProtectionChanges,
-			desiredSettings.BranchProtection,
+			ctx, log, client, org, repo,
+			repoChanges, protectionChanges, actionsChanges, securityChanges,
 		)
 	}
 
@@ -112,7 +197,7 @@ func SyncSettings(
 	}
 
 	// Sync rulesets
-	if err := SyncRulesets(
+	rulesetOutcomes, err := SyncRulesets(
 		ctx,
 		log,
 		client,
@@ -120,13 +205,151 @@ func SyncSettings(
 		repo,
 		desiredSettings.Rulesets,
 		syncConfig.Sync.Settings.Exclude,
+		syncConfig.Sync.Settings.AllowRemoval,
+		policy,
 		dryRun,
-	); err != nil {
+	)
+
+	result.Rulesets = rulesetOutcomes
+	result.ChangesApplied += countRulesetChanges(rulesetOutcomes)
+	result.Blocked = policy.Blocked
+
+	if err != nil {
 		result.CompleteWithError(errors.Wrap(err, "syncing rulesets"))
 
 		return result, err
 	}
 
+	// Sync deployment environments
+	environmentOutcomes, err := SyncEnvironments(
+		ctx,
+		log,
+		client,
+		org,
+		repo,
+		desiredSettings.Environments,
+		syncConfig.Sync.Settings.Exclude,
+		syncConfig.Sync.Settings.AllowRemoval,
+		dryRun,
+	)
+
+	result.Environments = environmentOutcomes
+	result.ChangesApplied += countEnvironmentChanges(environmentOutcomes)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing environments"))
+
+		return result, err
+	}
+
+	// Sync Actions variables and secrets
+	variableDiffs, err := SyncVariables(
+		ctx,
+		log,
+		client,
+		org,
+		repo,
+		desiredSettings.Variables,
+		currentRepo,
+		syncConfig.Sync.Settings.Exclude,
+		dryRun,
+	)
+
+	result.Variables = variableDiffs
+	result.ChangesApplied += len(variableDiffs)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing variables"))
+
+		return result, err
+	}
+
+	secretOutcomes, err := SyncSecrets(
+		ctx,
+		log,
+		client,
+		org,
+		repo,
+		desiredSettings.Secrets,
+		syncConfig.Sync.Settings.Exclude,
+		dryRun,
+	)
+
+	result.Secrets = secretOutcomes
+	result.ChangesApplied += countSecretChanges(secretOutcomes)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing secrets"))
+
+		return result, err
+	}
+
+	// Sync webhooks
+	webhookOutcomes, err := SyncWebhooks(
+		ctx,
+		log,
+		client,
+		org,
+		repo,
+		desiredSettings.Webhooks,
+		syncConfig.Sync.Settings.Exclude,
+		syncConfig.Sync.Settings.AllowRemoval,
+		dryRun,
+	)
+
+	result.Webhooks = webhookOutcomes
+	result.ChangesApplied += countWebhookChanges(webhookOutcomes)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing webhooks"))
+
+		return result, err
+	}
+
+	// Sync team and collaborator access
+	accessChanges, err := SyncAccess(
+		ctx,
+		log,
+		client,
+		org,
+		repo,
+		&desiredSettings.Access,
+		syncConfig.Sync.Settings.Exclude,
+		dryRun,
+	)
+
+	result.Access = accessChanges
+	result.ChangesApplied += len(accessChanges)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing access"))
+
+		return result, err
+	}
+
+	// Sync custom property values
+	propertyDiffs, err := SyncCustomProperties(
+		ctx,
+		log,
+		client,
+		org,
+		repo,
+		desiredSettings.CustomProperties,
+		syncConfig.Sync.Settings.CustomProperties,
+		currentRepo,
+		syncConfig.Sync.Settings.Exclude,
+		dryRun,
+	)
+
+	result.CustomProperties = propertyDiffs
+	result.ChangesApplied += len(propertyDiffs)
+
+	if err != nil {
+		result.CompleteWithError(errors.Wrap(err, "syncing custom properties"))
+
+		return result, err
+	}
+
 	result.Complete(StatusSuccess)
 
 	return result, nil
@@ -158,7 +381,8 @@ func loadSettings(
 	return desiredSettings, currentRepo, nil
 }
 
-// computeAllSettingsChanges computes all repository and branch protection changes.
+// computeAllSettingsChanges computes repository changes and whether branch
+// protection is managed.
 func computeAllSettingsChanges(
 	desired *SettingsDefinition,
 	current *github.Repository,
@@ -170,10 +394,10 @@ func computeAllSettingsChanges(
 
 	repoChanges := mergeRepositoryUpdates(repoUpdate, featuresUpdate, securityUpdate)
 
-	hasBranchProtectionChanges := len(desired.BranchProtection) > 0 &&
+	manageBranchProtection := len(desired.BranchProtection) > 0 &&
 		!isSettingExcluded("branch_protection", exclude)
 
-	return repoChanges, hasBranchProtectionChanges
+	return repoChanges, manageBranchProtection
 }
 
 // handleDryRun logs planned changes without applying them.
@@ -182,8 +406,9 @@ func computeAllSettingsChanges(
 func handleDryRun(
 	log *logger.Logger,
 	repoChanges *github.Repository,
-	hasBranchProtectionChanges bool,
-	desired *SettingsDefinition,
+	protectionChanges []branchProtectionChange,
+	actionsChanges *actionsChange,
+	securityChanges *securityChange,
 ) error {
 	log.Info("dry-run mode: skipping settings changes")
 
@@ -191,14 +416,15 @@ func handleDryRun(
 		logRepositoryChanges(log, repoChanges)
 	}
 
-	if hasBranchProtectionChanges {
-		log.Info("branch protection rules would be synced", "count", len(desired.BranchProtection))
-	}
+	logBranchProtectionChanges(log, protectionChanges)
+	logActionsChange(log, actionsChanges)
+	logSecurityChange(log, securityChanges)
 
 	return nil
 }
 
-// applyAllSettingsChanges applies repository and branch protection changes.
+// applyAllSettingsChanges applies repository, branch protection, Actions and security
+// feature changes.
 func applyAllSettingsChanges(
 	ctx context.Context,
 	log *logger.Logger,
@@ -206,8 +432,9 @@ func applyAllSettingsChanges(
 	org string,
 	repo string,
 	repoChanges *github.Repository,
-	hasBranchProtectionChanges bool,
-	branchProtectionRules []configtypes.BranchProtectionRuleConfig,
+	protectionChanges []branchProtectionChange,
+	actionsChanges *actionsChange,
+	securityChanges *securityChange,
 ) error {
 	if repoChanges != nil {
 		if err := applyRepositoryChanges(ctx, client, org, repo, repoChanges); err != nil {
@@ -217,15 +444,39 @@ func applyAllSettingsChanges(
 		log.Info("repository settings updated successfully")
 	}
 
-	if hasBranchProtectionChanges {
-		if err := syncBranchProtection(ctx, log, client, org, repo, branchProtectionRules); err != nil {
+	if len(protectionChanges) > 0 {
+		logBranchProtectionChanges(log, protectionChanges)
+
+		err := applyBranchProtectionChanges(ctx, log, client, org, repo, protectionChanges)
+		if err != nil {
 			return errors.Wrap(err, "syncing branch protection")
 		}
 
-		log.Info("branch protection synced successfully")
+		log.Info("branch protection synced successfully", "branches", len(protectionChanges))
 	}
 
-	if repoChanges == nil && !hasBranchProtectionChanges {
+	logActionsChange(log, actionsChanges)
+
+	if len(actionsDiffs(actionsChanges)) > 0 {
+		if err := applyActionsChange(ctx, client, org, repo, actionsChanges); err != nil {
+			return errors.Wrap(err, "applying actions settings")
+		}
+
+		log.Info("actions settings updated successfully")
+	}
+
+	logSecurityChange(log, securityChanges)
+
+	if len(securityDiffs(securityChanges)) > 0 {
+		if err := applySecurityChange(ctx, client, org, repo, securityChanges); err != nil {
+			return errors.Wrap(err, "applying security settings")
+		}
+
+		log.Info("security settings updated successfully")
+	}
+
+	if repoChanges == nil && len(protectionChanges) == 0 &&
+		len(actionsDiffs(actionsChanges)) == 0 && len(securityDiffs(securityChanges)) == 0 {
 		log.Info("no settings changes needed")
 	}
 
@@ -283,53 +534,122 @@ func computeRepositorySettingsDiff(
 	exclude []string,
 ) *github.Repository {
 	update := &github.Repository{}
-	hasChanges := false
 
-	// Allow squash merge
-	if desired.AllowSquashMerge != nil &&
-		!isSettingExcluded("repository.allow_squash_merge", exclude) &&
-		current.GetAllowSquashMerge() != *desired.AllowSquashMerge {
-		update.AllowSquashMerge = desired.AllowSquashMerge
-		hasChanges = true
+	// Merge settings
+	update.AllowSquashMerge = diffBoolSetting("repository.allow_squash_merge",
+		desired.AllowSquashMerge, current.GetAllowSquashMerge(), exclude)
+	update.AllowMergeCommit = diffBoolSetting("repository.allow_merge_commit",
+		desired.AllowMergeCommit, current.GetAllowMergeCommit(), exclude)
+	update.AllowRebaseMerge = diffBoolSetting("repository.allow_rebase_merge",
+		desired.AllowRebaseMerge, current.GetAllowRebaseMerge(), exclude)
+	update.AllowAutoMerge = diffBoolSetting("repository.allow_auto_merge",
+		desired.AllowAutoMerge, current.GetAllowAutoMerge(), exclude)
+	update.DeleteBranchOnMerge = diffBoolSetting("repository.delete_branch_on_merge",
+		desired.DeleteBranchOnMerge, current.GetDeleteBranchOnMerge(), exclude)
+	update.AllowUpdateBranch = diffBoolSetting("repository.allow_update_branch",
+		desired.AllowUpdateBranch, current.GetAllowUpdateBranch(), exclude)
+
+	// Merge commit formats
+	update.SquashMergeCommitTitle = diffStringSetting("repository.squash_merge_commit_title",
+		desired.SquashMergeCommitTitle, current.GetSquashMergeCommitTitle(), exclude)
+	update.SquashMergeCommitMessage = diffStringSetting("repository.squash_merge_commit_message",
+		desired.SquashMergeCommitMessage, current.GetSquashMergeCommitMessage(), exclude)
+	update.MergeCommitTitle = diffStringSetting("repository.merge_commit_title",
+		desired.MergeCommitTitle, current.GetMergeCommitTitle(), exclude)
+	update.MergeCommitMessage = diffStringSetting("repository.merge_commit_message",
+		desired.MergeCommitMessage, current.GetMergeCommitMessage(), exclude)
+
+	// Repository options
+	update.WebCommitSignoffRequired = diffBoolSetting("repository.web_commit_signoff_required",
+		desired.WebCommitSignoffRequired, current.GetWebCommitSignoffRequired(), exclude)
+	update.AllowForking = diffBoolSetting("repository.allow_forking",
+		desired.AllowForking, current.GetAllowForking(), exclude)
+	update.IsTemplate = diffBoolSetting("repository.is_template",
+		desired.IsTemplate, current.GetIsTemplate(), exclude)
+	update.HasDownloads = diffBoolSetting("repository.has_downloads",
+		desired.HasDownloads, current.GetHasDownloads(), exclude)
+	update.Visibility = diffStringSetting("repository.visibility",
+		desired.Visibility, current.GetVisibility(), exclude)
+	update.DefaultBranch = diffStringSetting("repository.default_branch",
+		desired.DefaultBranch, current.GetDefaultBranch(), exclude)
+
+	// Metadata
+	update.Description = diffStringSetting("repository.description",
+		renderRepositoryTemplate(desired.Description, current),
+		current.GetDescription(), exclude)
+	update.Homepage = diffStringSetting("repository.homepage",
+		renderRepositoryTemplate(desired.Homepage, current),
+		current.GetHomepage(), exclude)
+
+	if desired.Topics != nil && !isSettingExcluded("repository.topics", exclude) {
+		update.Topics = computeTopicsDiff(desired.Topics, current.Topics)
+	}
+
+	if reflect.ValueOf(*update).IsZero() {
+		return nil
 	}
 
-	// Allow merge commit
-	if desired.AllowMergeCommit != nil &&
-		!isSettingExcluded("repository.allow_merge_commit", exclude) &&
-		current.GetAllowMergeCommit() != *desired.AllowMergeCommit {
-		update.AllowMergeCommit = desired.AllowMergeCommit
-		hasChanges = true
+	return update
+}
+
+// diffBoolSetting returns the desired value when it is set, not excluded and
+// differs from the current value, otherwise nil.
+func diffBoolSetting(path string, desired *bool, current bool, exclude []string) *bool {
+	if desired == nil || isSettingExcluded(path, exclude) || *desired == current {
+		return nil
 	}
 
-	// Allow rebase merge
-	if desired.AllowRebaseMerge != nil &&
-		!isSettingExcluded("repository.allow_rebase_merge", exclude) &&
-		current.GetAllowRebaseMerge() != *desired.AllowRebaseMerge {
-		update.AllowRebaseMerge = desired.AllowRebaseMerge
-		hasChanges = true
+	return desired
+}
+
+// diffStringSetting returns the desired value when it is set, not excluded and
+// differs from the current value, otherwise nil.
+func diffStringSetting(path string, desired *string, current string, exclude []string) *string {
+	if desired == nil || isSettingExcluded(path, exclude) || *desired == current {
+		return nil
 	}
 
-	// Allow auto-merge
-	if desired.AllowAutoMerge != nil &&
-		!isSettingExcluded("repository.allow_auto_merge", exclude) &&
-		current.GetAllowAutoMerge() != *desired.AllowAutoMerge {
-		update.AllowAutoMerge = desired.AllowAutoMerge
-		hasChanges = true
+	return desired
+}
+
+// renderRepositoryTemplate replaces {{REPO_NAME}}, {{OWNER}}, {{DEFAULT_BRANCH}} and
+// {{LANGUAGE}} placeholders with values of the repository. Returns nil when value is
+// nil.
+func renderRepositoryTemplate(value *string, repository *github.Repository) *string {
+	if value == nil {
+		return nil
 	}
 
-	// Delete branch on merge
-	if desired.DeleteBranchOnMerge != nil &&
-		!isSettingExcluded("repository.delete_branch_on_merge", exclude) &&
-		current.GetDeleteBranchOnMerge() != *desired.DeleteBranchOnMerge {
-		update.DeleteBranchOnMerge = desired.DeleteBranchOnMerge
-		hasChanges = true
+	rendered := strings.NewReplacer(
+		"{{REPO_NAME}}", repository.GetName(),
+		"{{OWNER}}", repository.GetOwner().GetLogin(),
+		"{{DEFAULT_BRANCH}}", repository.GetDefaultBranch(),
+		"{{LANGUAGE}}", repository.GetLanguage(),
+	).Replace(*value)
+
+	return &rendered
+}
+
+// computeTopicsDiff returns the topics the repository should have, or nil when its
+// topics are already in sync. In add-only mode topics not listed are kept.
+func computeTopicsDiff(desired *configtypes.TopicsConfig, current []string) []string {
+	topics := make([]string, 0, len(desired.Names)+len(current))
+
+	for _, name := range desired.Names {
+		topics = append(topics, strings.ToLower(name))
 	}
 
-	if !hasChanges {
+	if desired.Mode != configtypes.TopicsModeExact {
+		topics = append(topics, current...)
+	}
+
+	topics = sortedSet(topics)
+
+	if slices.Equal(topics, sortedSet(current)) {
 		return nil
 	}
 
-	return update
+	return topics
 }
 
 // computeFeaturesDiff computes feature settings that need updating.
@@ -392,6 +712,7 @@ func computeSecurityDiff(
 	// Only proceed if we have security settings to sync
 	if desired.SecretScanning == nil &&
 		desired.SecretScanningPushProtection == nil &&
+		desired.SecretScanningValidityChecks == nil &&
 		desired.DependabotSecurityUpdates == nil {
 		return nil
 	}
@@ -427,6 +748,22 @@ func computeSecurityDiff(
 		}
 	}
 
+	// Secret scanning validity checks are only reported where they are available. Missing
+	// ones are recorded as unsupported by planSecurityFeatures.
+	if desired.SecretScanningValidityChecks != nil &&
+		currentSecurity.GetSecretScanningValidityChecks() != nil &&
+		!isSettingExcluded("security.secret_scanning_validity_checks", exclude) {
+		desiredStatus := *desired.SecretScanningValidityChecks
+		currentStatus := currentSecurity.SecretScanningValidityChecks.GetStatus()
+
+		if currentStatus != desiredStatus {
+			securityUpdate.SecretScanningValidityChecks = &github.SecretScanningValidityChecks{
+				Status: github.Ptr(desiredStatus),
+			}
+			hasChanges = true
+		}
+	}
+
 	// Dependabot security updates
 	if desired.DependabotSecurityUpdates != nil &&
 		!isSettingExcluded("security.dependabot_security_updates", exclude) {
@@ -508,6 +845,62 @@ func mergeRepositoryFields(merged, update *github.Repository) {
 	if update.DeleteBranchOnMerge != nil {
 		merged.DeleteBranchOnMerge = update.DeleteBranchOnMerge
 	}
+
+	if update.AllowUpdateBranch != nil {
+		merged.AllowUpdateBranch = update.AllowUpdateBranch
+	}
+
+	if update.SquashMergeCommitTitle != nil {
+		merged.SquashMergeCommitTitle = update.SquashMergeCommitTitle
+	}
+
+	if update.SquashMergeCommitMessage != nil {
+		merged.SquashMergeCommitMessage = update.SquashMergeCommitMessage
+	}
+
+	if update.MergeCommitTitle != nil {
+		merged.MergeCommitTitle = update.MergeCommitTitle
+	}
+
+	if update.MergeCommitMessage != nil {
+		merged.MergeCommitMessage = update.MergeCommitMessage
+	}
+
+	if update.WebCommitSignoffRequired != nil {
+		merged.WebCommitSignoffRequired = update.WebCommitSignoffRequired
+	}
+
+	if update.AllowForking != nil {
+		merged.AllowForking = update.AllowForking
+	}
+
+	if update.IsTemplate != nil {
+		merged.IsTemplate = update.IsTemplate
+	}
+
+	if update.HasDownloads != nil {
+		merged.HasDownloads = update.HasDownloads
+	}
+
+	if update.Visibility != nil {
+		merged.Visibility = update.Visibility
+	}
+
+	if update.DefaultBranch != nil {
+		merged.DefaultBranch = update.DefaultBranch
+	}
+
+	if update.Description != nil {
+		merged.Description = update.Description
+	}
+
+	if update.Homepage != nil {
+		merged.Homepage = update.Homepage
+	}
+
+	if update.Topics != nil {
+		merged.Topics = update.Topics
+	}
 }
 
 // mergeFeatureFields merges feature settings fields.
@@ -544,9 +937,21 @@ func applyRepositoryChanges(
 	repo string,
 	update *github.Repository,
 ) error {
-	_, _, err := client.Repositories.Edit(ctx, org, repo, update)
-	if err != nil {
-		return errors.Wrap(err, "updating repository")
+	// Topics are replaced through their own endpoint
+	edit := *update
+	edit.Topics = nil
+
+	if !reflect.ValueOf(edit).IsZero() {
+		if _, _, err := client.Repositories.Edit(ctx, org, repo, &edit); err != nil {
+			return errors.Wrap(err, "updating repository")
+		}
+	}
+
+	if update.Topics != nil {
+		_, _, err := client.Repositories.ReplaceAllTopics(ctx, org, repo, update.Topics)
+		if err != nil {
+			return errors.Wrap(err, "replacing repository topics")
+		}
 	}
 
 	return nil
@@ -576,6 +981,8 @@ func logRepositoryChanges(log *logger.Logger, update *github.Repository) {
 		log.Info("  ~ delete_branch_on_merge", "value", *update.DeleteBranchOnMerge)
 	}
 
+	logRepositoryOptionChanges(log, update)
+
 	if update.HasIssues != nil {
 		log.Info("  ~ has_issues", "value", *update.HasIssues)
 	}
@@ -604,6 +1011,11 @@ func logRepositoryChanges(log *logger.Logger, update *github.Repository) {
 				"status", security.SecretScanningPushProtection.GetStatus())
 		}
 
+		if security.SecretScanningValidityChecks != nil {
+			log.Info("  ~ secret_scanning_validity_checks",
+				"status", security.SecretScanningValidityChecks.GetStatus())
+		}
+
 		if security.DependabotSecurityUpdates != nil {
 			log.Info("  ~ dependabot_security_updates",
 				"status", security.DependabotSecurityUpdates.GetStatus())
@@ -611,44 +1023,64 @@ func logRepositoryChanges(log *logger.Logger, update *github.Repository) {
 	}
 }
 
-// syncBranchProtection synchronizes branch protection rules.
-func syncBranchProtection(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	rules []configtypes.BranchProtectionRuleConfig,
-) error {
-	// Get all branches in the repository
-	branches, err := fetchBranches(ctx, client, org, repo)
-	if err != nil {
-		return errors.Wrap(err, "fetching branches")
+// logRepositoryOptionChanges logs planned merge commit format, option and metadata
+// changes.
+func logRepositoryOptionChanges(log *logger.Logger, update *github.Repository) {
+	if update.AllowUpdateBranch != nil {
+		log.Info("  ~ allow_update_branch", "value", *update.AllowUpdateBranch)
 	}
 
-	log.Debug("fetched branches", "count", len(branches))
+	if update.SquashMergeCommitTitle != nil {
+		log.Info("  ~ squash_merge_commit_title", "value", *update.SquashMergeCommitTitle)
+	}
 
-	// Apply each protection rule
-	for _, rule := range rules {
-		// Find matching branches
-		matchingBranches := findMatchingBranches(branches, rule.Pattern)
+	if update.SquashMergeCommitMessage != nil {
+		log.Info("  ~ squash_merge_commit_message", "value", *update.SquashMergeCommitMessage)
+	}
 
-		log.Debug("found matching branches",
-			"pattern", rule.Pattern,
-			"count", len(matchingBranches),
-		)
+	if update.MergeCommitTitle != nil {
+		log.Info("  ~ merge_commit_title", "value", *update.MergeCommitTitle)
+	}
 
-		// Apply protection to each matching branch
-		for _, branch := range matchingBranches {
-			if err := applyBranchProtection(ctx, log, client, org, repo, branch, &rule); err != nil {
-				return errors.Wrapf(err, "applying protection to branch %q", branch)
-			}
+	if update.MergeCommitMessage != nil {
+		log.Info("  ~ merge_commit_message", "value", *update.MergeCommitMessage)
+	}
 
-			log.Debug("applied branch protection", "branch", branch, "pattern", rule.Pattern)
-		}
+	if update.WebCommitSignoffRequired != nil {
+		log.Info("  ~ web_commit_signoff_required", "value", *update.WebCommitSignoffRequired)
 	}
 
-	return nil
+	if update.AllowForking != nil {
+		log.Info("  ~ allow_forking", "value", *update.AllowForking)
+	}
+
+	if update.IsTemplate != nil {
+		log.Info("  ~ is_template", "value", *update.IsTemplate)
+	}
+
+	if update.HasDownloads != nil {
+		log.Info("  ~ has_downloads", "value", *update.HasDownloads)
+	}
+
+	if update.Visibility != nil {
+		log.Info("  ~ visibility", "value", *update.Visibility)
+	}
+
+	if update.DefaultBranch != nil {
+		log.Info("  ~ default_branch", "value", *update.DefaultBranch)
+	}
+
+	if update.Description != nil {
+		log.Info("  ~ description", "value", *update.Description)
+	}
+
+	if update.Homepage != nil {
+		log.Info("  ~ homepage", "value", *update.Homepage)
+	}
+
+	if update.Topics != nil {
+		log.Info("  ~ topics", "value", update.Topics)
+	}
 }
 
 // fetchBranches retrieves all branch names from a repository.
@@ -699,52 +1131,8 @@ func findMatchingBranches(branches []string, pattern string) []string {
 	return matches
 }
 
-// matchBranchPattern checks if a branch name matches a pattern.
-func matchBranchPattern(branch, pattern string) bool {
-	// Exact match
-	if branch == pattern {
-		return true
-	}
-
-	// Glob pattern support (simple wildcard matching)
-	matched, err := filepath.Match(pattern, branch)
-	if err != nil {
-		return false
-	}
-
-	return matched
-}
-
-// applyBranchProtection applies protection rules to a specific branch.
-func applyBranchProtection(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	branch string,
-	rule *configtypes.BranchProtectionRuleConfig,
-) error {
-	// Fetch current protection settings
-	currentProtection, err := fetchBranchProtection(ctx, client, org, repo, branch)
-	if err != nil {
-		// If branch protection doesn't exist, that's okay - we'll create it
-		log.Debug("no existing protection found for branch", "branch", branch)
-	}
-
-	// Build protection request
-	protectionReq := buildProtectionRequest(rule, currentProtection)
-
-	// Apply protection
-	_, _, err = client.Repositories.UpdateBranchProtection(ctx, org, repo, branch, protectionReq)
-	if err != nil {
-		return errors.Wrapf(err, "updating branch protection for %q", branch)
-	}
-
-	return nil
-}
-
 // fetchBranchProtection retrieves current branch protection settings.
+// Returns nil when the branch is not protected.
 func fetchBranchProtection(
 	ctx context.Context,
 	client *Client,
@@ -753,6 +1141,10 @@ func fetchBranchProtection(
 	branch string,
 ) (*github.Protection, error) {
 	protection, _, err := client.Repositories.GetBranchProtection(ctx, org, repo, branch)
+	if errors.Is(err, github.ErrBranchNotProtected) || isNotFoundError(err) {
+		return nil, nil //nolint:nilnil // unprotected branch is not an error
+	}
+
 	if err != nil {
 		return nil, errors.Wrap(err, "getting branch protection")
 	}
@@ -862,3 +1254,21 @@ func getBoolValue(val *bool) bool {
 
 	return *val
 }
+
+// getIntValueOr returns the int value if not nil, otherwise returns fallback.
+func getIntValueOr(val *int, fallback int) int {
+	if val == nil {
+		return fallback
+	}
+
+	return *val
+}
+
+// getBoolValueOr returns the bool value if not nil, otherwise returns fallback.
+func getBoolValueOr(val *bool, fallback bool) bool {
+	if val == nil {
+		return fallback
+	}
+
+	return *val
+}
@@ -0,0 +1,812 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+	"go.yaml.in/yaml/v4"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+	"github.com/smykla-labs/.github/pkg/merge"
+)
+
+// exportIndent is the indentation of exported YAML, matching the settings files.
+const exportIndent = 2
+
+// ExportSettingsFile reads the current settings of a repository and returns them as a
+// settings file. When settingsFile is set, the sync-config.yml merge overrides that
+// turn the organization settings into the repository's settings are returned instead.
+func ExportSettingsFile(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	settingsFile string,
+) ([]byte, error) {
+	exported, err := ExportSettings(ctx, log, client, org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if settingsFile == "" {
+		return marshalExportYAML(SettingsFile{Settings: *exported})
+	}
+
+	orgSettings, err := parseSettingsFile(settingsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing settings file")
+	}
+
+	overrides, err := ExportSettingsOverrides(log, orgSettings, exported)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalExportYAML(configtypes.SyncConfig{
+		Sync: configtypes.SyncSettings{
+			Settings: configtypes.SettingsConfig{Merge: overrides},
+		},
+	})
+}
+
+// ExportSettings reads the repository flags, features, security settings, branch
+// protection rules and rulesets of a repository in the settings file format. Security
+// features the repository does not offer are left out, as are rulesets inherited from
+// the organization.
+func ExportSettings(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+) (*SettingsDefinition, error) {
+	repository, err := fetchRepository(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching repository")
+	}
+
+	security, err := exportSecurity(ctx, client, org, repo, repository)
+	if err != nil {
+		return nil, errors.Wrap(err, "exporting security settings")
+	}
+
+	protection, err := fetchBranchProtectionRules(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching branch protection rules")
+	}
+
+	rulesets, err := exportRulesets(ctx, &repoRulesetStore{client: client, org: org, repo: repo})
+	if err != nil {
+		return nil, errors.Wrap(err, "exporting rulesets")
+	}
+
+	settings := &SettingsDefinition{
+		Repository: exportRepositorySettings(repository),
+		Features: configtypes.FeaturesConfig{
+			HasIssues:      repository.HasIssues,
+			HasWiki:        repository.HasWiki,
+			HasProjects:    repository.HasProjects,
+			HasDiscussions: repository.HasDiscussions,
+		},
+		Security: *security,
+		Rulesets: rulesets,
+	}
+
+	for _, pattern := range slices.Sorted(maps.Keys(protection.Rules)) {
+		settings.BranchProtection = append(settings.BranchProtection,
+			exportBranchProtectionRule(protection.Rules[pattern]))
+	}
+
+	log.Info("exported repository settings",
+		"branch_protection", len(settings.BranchProtection),
+		"rulesets", len(settings.Rulesets),
+	)
+
+	return settings, nil
+}
+
+// exportRepositorySettings returns the merge and repository flags of a repository.
+// Topics are exported in add-only mode.
+func exportRepositorySettings(repository *github.Repository) configtypes.RepositorySettingsConfig {
+	settings := configtypes.RepositorySettingsConfig{
+		AllowSquashMerge:         repository.AllowSquashMerge,
+		AllowMergeCommit:         repository.AllowMergeCommit,
+		AllowRebaseMerge:         repository.AllowRebaseMerge,
+		AllowAutoMerge:           repository.AllowAutoMerge,
+		DeleteBranchOnMerge:      repository.DeleteBranchOnMerge,
+		AllowUpdateBranch:        repository.AllowUpdateBranch,
+		SquashMergeCommitTitle:   repository.SquashMergeCommitTitle,
+		SquashMergeCommitMessage: repository.SquashMergeCommitMessage,
+		MergeCommitTitle:         repository.MergeCommitTitle,
+		MergeCommitMessage:       repository.MergeCommitMessage,
+		WebCommitSignoffRequired: repository.WebCommitSignoffRequired,
+		AllowForking:             repository.AllowForking,
+		IsTemplate:               repository.IsTemplate,
+		HasDownloads:             repository.HasDownloads,
+		Visibility:               repository.Visibility,
+		DefaultBranch:            repository.DefaultBranch,
+		Description:              exportedString(repository.GetDescription()),
+		Homepage:                 exportedString(repository.GetHomepage()),
+	}
+
+	if len(repository.Topics) > 0 {
+		settings.Topics = &configtypes.TopicsConfig{Names: repository.Topics}
+	}
+
+	return settings
+}
+
+// exportSecurity returns the security settings of a repository. Features that are not
+// available for the repository are left unset.
+func exportSecurity(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	repository *github.Repository,
+) (*configtypes.SecurityConfig, error) {
+	analysis := repository.GetSecurityAndAnalysis()
+
+	security := &configtypes.SecurityConfig{
+		SecretScanning: exportedString(getSecurityFeatureStatus(analysis.GetSecretScanning())),
+		SecretScanningPushProtection: exportedString(
+			getSecurityFeatureStatus(analysis.GetSecretScanningPushProtection()),
+		),
+		SecretScanningValidityChecks: exportedString(
+			getSecurityFeatureStatus(analysis.GetSecretScanningValidityChecks()),
+		),
+		DependabotSecurityUpdates: exportedString(
+			getSecurityFeatureStatus(analysis.GetDependabotSecurityUpdates()),
+		),
+	}
+
+	patterns, err := fetchNonProviderPatterns(ctx, client, org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	security.SecretScanningNonProviderPatterns = exportedString(patterns.GetStatus())
+
+	alerts, _, err := client.Repositories.GetVulnerabilityAlerts(ctx, org, repo)
+	if err != nil && !isUnsupportedFeatureError(err) {
+		return nil, errors.Wrap(err, "getting vulnerability alerts")
+	}
+
+	if err == nil {
+		security.VulnerabilityAlerts = github.Ptr(securityStatus(alerts))
+	}
+
+	if !repository.GetPrivate() {
+		reporting, _, err := client.Repositories.IsPrivateReportingEnabled(ctx, org, repo)
+		if err != nil && !isUnsupportedFeatureError(err) {
+			return nil, errors.Wrap(err, "getting private vulnerability reporting")
+		}
+
+		if err == nil {
+			security.PrivateVulnerabilityReporting = github.Ptr(securityStatus(reporting))
+		}
+	}
+
+	setup, _, err := client.CodeScanning.GetDefaultSetupConfiguration(ctx, org, repo)
+	if err != nil && !isUnsupportedFeatureError(err) {
+		return nil, errors.Wrap(err, "getting code scanning default setup")
+	}
+
+	if err == nil {
+		security.CodeScanningDefaultSetup = &configtypes.CodeScanningDefaultSetupConfig{
+			State: github.Ptr(setup.GetState()),
+		}
+
+		if setup.GetState() == codeScanningConfigured {
+			security.CodeScanningDefaultSetup.Languages = exportedList(setup.Languages)
+			security.CodeScanningDefaultSetup.QuerySuite = setup.QuerySuite
+		}
+	}
+
+	return security, nil
+}
+
+// exportBranchProtectionRule converts a branch protection rule to its settings file
+// form. Sections the rule does not enable are left unset.
+func exportBranchProtectionRule(
+	node *branchProtectionRuleNode,
+) configtypes.BranchProtectionRuleConfig {
+	rule := configtypes.BranchProtectionRuleConfig{
+		Pattern:                        node.Pattern,
+		EnforceAdmins:                  github.Ptr(node.IsAdminEnforced),
+		RequireLinearHistory:           github.Ptr(node.RequiresLinearHistory),
+		AllowForcePushes:               github.Ptr(node.AllowsForcePushes),
+		AllowDeletions:                 github.Ptr(node.AllowsDeletions),
+		RequiredConversationResolution: github.Ptr(node.RequiresConversationResolution),
+	}
+
+	if node.RequiresStatusChecks {
+		rule.RequiredStatusChecks = &configtypes.RequiredStatusChecks{
+			Strict:   github.Ptr(node.RequiresStrictStatusChecks),
+			Contexts: exportedList(node.RequiredStatusCheckContexts),
+		}
+	}
+
+	if node.RequiresApprovingReviews {
+		reviews := &configtypes.RequiredReviews{
+			RequiredApprovingReviewCount: github.Ptr(node.RequiredApprovingReviewCount),
+			DismissStaleReviews:          github.Ptr(node.DismissesStaleReviews),
+			RequireCodeOwnerReviews:      github.Ptr(node.RequiresCodeOwnerReviews),
+			RequireLastPushApproval:      github.Ptr(node.RequireLastPushApproval),
+		}
+
+		users, teams, apps := node.BypassPullRequestAllowances.actors()
+		if len(users)+len(teams)+len(apps) > 0 {
+			reviews.BypassPullRequestAllowances = &configtypes.BypassPullRequestAllowances{
+				Users: exportedList(userLogins(users)),
+				Teams: exportedList(teamSlugs(teams)),
+				Apps:  exportedList(appSlugs(apps)),
+			}
+		}
+
+		rule.RequiredReviews = reviews
+	}
+
+	if node.RestrictsPushes {
+		users, teams, apps := node.PushAllowances.actors()
+
+		rule.Restrictions = &configtypes.BranchRestrictionsConfig{
+			Users: exportedList(userLogins(users)),
+			Teams: exportedList(teamSlugs(teams)),
+			Apps:  exportedList(appSlugs(apps)),
+		}
+	}
+
+	return rule
+}
+
+// exportRulesets returns the rulesets defined on the repository itself. The list
+// endpoint omits rules, so each ruleset is fetched in full.
+func exportRulesets(ctx context.Context, store rulesetStore) ([]configtypes.RulesetConfig, error) {
+	existing, err := store.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rulesets []configtypes.RulesetConfig
+
+	for _, summary := range existing {
+		if summary.SourceType != nil && *summary.SourceType != store.sourceType() {
+			continue
+		}
+
+		ruleset, err := store.get(ctx, summary.GetID())
+		if err != nil {
+			return nil, errors.Wrapf(err, "ruleset %q", summary.Name)
+		}
+
+		rulesets = append(rulesets, exportRuleset(ruleset))
+	}
+
+	return rulesets, nil
+}
+
+// exportRuleset converts a ruleset to its settings file form.
+func exportRuleset(ruleset *github.RepositoryRuleset) configtypes.RulesetConfig {
+	config := configtypes.RulesetConfig{
+		Name:         ruleset.Name,
+		Target:       rulesetTargetValue(ruleset.GetTarget()),
+		Enforcement:  string(ruleset.Enforcement),
+		BypassActors: exportBypassActors(ruleset.BypassActors),
+	}
+
+	if refName := ruleset.GetConditions().GetRefName(); refName != nil {
+		config.Conditions = &configtypes.RulesetConditionsConfig{
+			RefName: &configtypes.RefNameCondition{
+				Include: exportedList(refName.Include),
+				Exclude: exportedList(refName.Exclude),
+			},
+		}
+	}
+
+	if ruleset.Rules != nil {
+		config.Rules = exportRulesetRules(ruleset.Rules)
+	}
+
+	return config
+}
+
+// exportBypassActors converts bypass actors to their settings file form. Organization
+// admins and built-in repository roles are exported by name.
+func exportBypassActors(actors []*github.BypassActor) []configtypes.BypassActorConfig {
+	var exported []configtypes.BypassActorConfig
+
+	for _, actor := range actors {
+		var config configtypes.BypassActorConfig
+
+		if actor.BypassMode != nil {
+			config.BypassMode = string(*actor.BypassMode)
+		}
+
+		var actorType string
+
+		if actor.ActorType != nil {
+			actorType = string(*actor.ActorType)
+		}
+
+		role := builtinRepositoryRoleName(actor.GetActorID())
+
+		switch {
+		case actorType == bypassActorTypeOrgAdmin:
+			config.OrgAdmin = github.Ptr(true)
+		case actorType == bypassActorTypeRepositoryRole && role != "":
+			config.Role = role
+		default:
+			config.ActorID = actor.GetActorID()
+			config.ActorType = actorType
+		}
+
+		exported = append(exported, config)
+	}
+
+	return exported
+}
+
+// builtinRepositoryRoleName returns the built-in repository role with the given actor
+// ID, or an empty string for custom roles.
+func builtinRepositoryRoleName(id int64) string {
+	for name, roleID := range builtinRepositoryRoleIDs {
+		if roleID == id {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// exportRulesetRules converts ruleset rules to their settings file form.
+func exportRulesetRules(rules *github.RepositoryRulesetRules) *configtypes.RulesetRulesConfig {
+	config := &configtypes.RulesetRulesConfig{
+		Deletion:                 enabledRule(rules.Deletion != nil),
+		NonFastForward:           enabledRule(rules.NonFastForward != nil),
+		RequiredLinearHistory:    enabledRule(rules.RequiredLinearHistory != nil),
+		RequiredSignatures:       enabledRule(rules.RequiredSignatures != nil),
+		Creation:                 enabledRule(rules.Creation != nil),
+		Update:                   enabledRule(rules.Update != nil),
+		CommitMessagePattern:     exportPatternRule(rules.CommitMessagePattern),
+		CommitAuthorEmailPattern: exportPatternRule(rules.CommitAuthorEmailPattern),
+		BranchNamePattern:        exportPatternRule(rules.BranchNamePattern),
+		TagNamePattern:           exportPatternRule(rules.TagNamePattern),
+	}
+
+	if pr := rules.PullRequest; pr != nil {
+		config.PullRequest = &configtypes.PullRequestRuleConfig{
+			DismissStaleReviewsOnPush:      github.Ptr(pr.DismissStaleReviewsOnPush),
+			RequireCodeOwnerReview:         github.Ptr(pr.RequireCodeOwnerReview),
+			RequireLastPushApproval:        github.Ptr(pr.RequireLastPushApproval),
+			RequiredApprovingReviewCount:   github.Ptr(pr.RequiredApprovingReviewCount),
+			RequiredReviewThreadResolution: github.Ptr(pr.RequiredReviewThreadResolution),
+		}
+
+		for _, method := range pr.AllowedMergeMethods {
+			config.PullRequest.AllowedMergeMethods = append(
+				config.PullRequest.AllowedMergeMethods, string(method),
+			)
+		}
+	}
+
+	if checks := rules.RequiredStatusChecks; checks != nil {
+		config.RequiredStatusChecks = &configtypes.StatusChecksRuleConfig{
+			StrictRequiredStatusChecksPolicy: github.Ptr(checks.StrictRequiredStatusChecksPolicy),
+			DoNotEnforceOnCreate:             checks.DoNotEnforceOnCreate,
+		}
+
+		for _, check := range checks.RequiredStatusChecks {
+			config.RequiredStatusChecks.RequiredStatusChecks = append(
+				config.RequiredStatusChecks.RequiredStatusChecks,
+				configtypes.StatusCheckConfig{
+					Context:       check.Context,
+					IntegrationID: check.IntegrationID,
+				},
+			)
+		}
+	}
+
+	if rules.CodeScanning != nil {
+		config.CodeScanning = &configtypes.CodeScanningRuleConfig{}
+
+		for _, tool := range rules.CodeScanning.CodeScanningTools {
+			config.CodeScanning.CodeScanningTools = append(
+				config.CodeScanning.CodeScanningTools,
+				configtypes.CodeScanningToolConfig{
+					Tool:                    tool.Tool,
+					AlertsThreshold:         string(tool.AlertsThreshold),
+					SecurityAlertsThreshold: string(tool.SecurityAlertsThreshold),
+				},
+			)
+		}
+	}
+
+	exportPushRules(rules, config)
+
+	if queue := rules.MergeQueue; queue != nil {
+		config.MergeQueue = &configtypes.MergeQueueRuleConfig{
+			CheckResponseTimeoutMinutes:  github.Ptr(queue.CheckResponseTimeoutMinutes),
+			GroupingStrategy:             string(queue.GroupingStrategy),
+			MaxEntriesToBuild:            github.Ptr(queue.MaxEntriesToBuild),
+			MaxEntriesToMerge:            github.Ptr(queue.MaxEntriesToMerge),
+			MergeMethod:                  string(queue.MergeMethod),
+			MinEntriesToMerge:            github.Ptr(queue.MinEntriesToMerge),
+			MinEntriesToMergeWaitMinutes: github.Ptr(queue.MinEntriesToMergeWaitMinutes),
+		}
+	}
+
+	if rules.Workflows != nil {
+		config.Workflows = &configtypes.WorkflowsRuleConfig{
+			DoNotEnforceOnCreate: rules.Workflows.DoNotEnforceOnCreate,
+		}
+
+		for _, workflow := range rules.Workflows.Workflows {
+			config.Workflows.Workflows = append(config.Workflows.Workflows,
+				configtypes.WorkflowRuleConfig{
+					Path:         workflow.Path,
+					RepositoryID: workflow.RepositoryID,
+					Ref:          workflow.GetRef(),
+					SHA:          workflow.GetSHA(),
+				},
+			)
+		}
+	}
+
+	return config
+}
+
+// exportPushRules converts the file and deployment rules of a ruleset.
+func exportPushRules(rules *github.RepositoryRulesetRules, config *configtypes.RulesetRulesConfig) {
+	if rules.FilePathRestriction != nil {
+		config.FilePathRestriction = &configtypes.FilePathRestrictionRuleConfig{
+			RestrictedFilePaths: exportedList(rules.FilePathRestriction.RestrictedFilePaths),
+		}
+	}
+
+	if rules.MaxFileSize != nil {
+		config.MaxFileSize = &configtypes.MaxFileSizeRuleConfig{
+			MaxFileSize: rules.MaxFileSize.MaxFileSize,
+		}
+	}
+
+	if rules.FileExtensionRestriction != nil {
+		config.FileExtensionRestriction = &configtypes.FileExtensionRestrictionRuleConfig{
+			RestrictedFileExtensions: exportedList(
+				rules.FileExtensionRestriction.RestrictedFileExtensions,
+			),
+		}
+	}
+
+	if rules.RequiredDeployments != nil {
+		config.RequiredDeployments = &configtypes.RequiredDeploymentsRuleConfig{
+			RequiredDeploymentEnvironments: exportedList(
+				rules.RequiredDeployments.RequiredDeploymentEnvironments,
+			),
+		}
+	}
+}
+
+// exportPatternRule converts a pattern rule, returning nil when it is not set.
+func exportPatternRule(rule *github.PatternRuleParameters) *configtypes.PatternRuleConfig {
+	if rule == nil {
+		return nil
+	}
+
+	return &configtypes.PatternRuleConfig{
+		Operator: string(rule.Operator),
+		Pattern:  rule.Pattern,
+		Negate:   rule.Negate,
+		Name:     rule.GetName(),
+	}
+}
+
+// enabledRule returns true for rules that are present and nil otherwise, since the
+// settings file only turns rules on.
+func enabledRule(present bool) *bool {
+	if !present {
+		return nil
+	}
+
+	return github.Ptr(true)
+}
+
+// exportedString returns nil for empty values so they are left out of the export.
+func exportedString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return github.Ptr(value)
+}
+
+// exportedList returns nil for empty lists so they are left out of the export.
+func exportedList(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+// ExportSettingsOverrides returns the merge overrides that turn the organization
+// settings into the exported settings of a repository. The repository, features and
+// security sections are compared field by field, branch protection rules and rulesets
+// are matched by pattern and name. Rules and rulesets defined on only one side cannot
+// be expressed as overrides and are logged instead.
+func ExportSettingsOverrides(
+	log *logger.Logger,
+	orgSettings *SettingsDefinition,
+	exported *SettingsDefinition,
+) ([]configtypes.SettingsMergeConfig, error) {
+	type section struct {
+		name     string
+		org      any
+		exported any
+	}
+
+	sections := []section{
+		{name: "repository", org: orgSettings.Repository, exported: exported.Repository},
+		{name: "features", org: orgSettings.Features, exported: exported.Features},
+		{name: "security", org: orgSettings.Security, exported: exported.Security},
+	}
+
+	for _, rule := range exported.BranchProtection {
+		index := slices.IndexFunc(orgSettings.BranchProtection,
+			func(org configtypes.BranchProtectionRuleConfig) bool {
+				return org.Pattern == rule.Pattern
+			})
+		if index < 0 {
+			log.Warn("branch protection rule not in organization settings", "pattern", rule.Pattern)
+
+			continue
+		}
+
+		sections = append(sections, section{
+			name:     rule.Pattern,
+			org:      orgSettings.BranchProtection[index],
+			exported: rule,
+		})
+	}
+
+	for _, ruleset := range exported.Rulesets {
+		index := slices.IndexFunc(orgSettings.Rulesets, func(org configtypes.RulesetConfig) bool {
+			return org.Name == ruleset.Name
+		})
+		if index < 0 {
+			log.Warn("ruleset not in organization settings", "name", ruleset.Name)
+
+			continue
+		}
+
+		sections = append(sections, section{
+			name:     ruleset.Name,
+			org:      orgSettings.Rulesets[index],
+			exported: ruleset,
+		})
+	}
+
+	var overrides []configtypes.SettingsMergeConfig
+
+	for _, s := range sections {
+		orgMap, err := exportMap(s.org)
+		if err != nil {
+			return nil, errors.Wrapf(err, "section %q", s.name)
+		}
+
+		exportedMap, err := exportMap(s.exported)
+		if err != nil {
+			return nil, errors.Wrapf(err, "section %q", s.name)
+		}
+
+		if diff := diffOverrides(orgMap, exportedMap); len(diff) > 0 {
+			overrides = append(overrides, configtypes.SettingsMergeConfig{
+				Section:   s.name,
+				Overrides: diff,
+			})
+		}
+	}
+
+	return overrides, nil
+}
+
+// diffOverrides returns the deep-merge overrides that turn org into exported. Nested
+// maps are compared recursively, other values replace the organization's value and
+// keys missing from exported are cleared with null.
+func diffOverrides(org map[string]any, exported map[string]any) map[string]any {
+	overrides := make(map[string]any)
+
+	for key, value := range exported {
+		orgValue, ok := org[key]
+		if ok && reflect.DeepEqual(orgValue, value) {
+			continue
+		}
+
+		orgMap, orgIsMap := orgValue.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+
+		if orgIsMap && valueIsMap {
+			overrides[key] = diffOverrides(orgMap, valueMap)
+
+			continue
+		}
+
+		overrides[key] = value
+	}
+
+	for key := range org {
+		if _, ok := exported[key]; !ok {
+			overrides[key] = nil
+		}
+	}
+
+	return overrides
+}
+
+// exportMap converts a settings section to the map form used by merge overrides.
+func exportMap(value any) (map[string]any, error) {
+	data, err := marshalExportYAML(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := merge.ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		result = make(map[string]any)
+	}
+
+	return result, nil
+}
+
+// marshalExportYAML marshals a settings value to YAML with unset fields left out, so
+// parsing the output yields the same value.
+func marshalExportYAML(value any) ([]byte, error) {
+	node, err := exportNode(reflect.ValueOf(value), true)
+	if err != nil {
+		return nil, errors.Wrap(err, "building YAML")
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(exportIndent)
+
+	if err := encoder.Encode(node); err != nil {
+		return nil, errors.Wrap(err, "marshaling YAML")
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, errors.Wrap(err, "marshaling YAML")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// exportNode converts a value to a YAML node. Nil pointers, empty lists and maps, and
+// zero values of non-pointer fields are left out by returning nil. Values behind
+// pointers or inside lists and maps are kept when keep is set, since a pointer to false
+// or an empty string is meaningful in the settings file.
+func exportNode(value reflect.Value, keep bool) (*yaml.Node, error) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			if keep {
+				return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+			}
+
+			return nil, nil //nolint:nilnil // unset values are left out
+		}
+
+		return exportNode(value.Elem(), true)
+	case reflect.Struct:
+		return exportStructNode(value, keep)
+	case reflect.Slice, reflect.Array:
+		if value.Len() == 0 && !keep {
+			return nil, nil //nolint:nilnil // empty lists are left out
+		}
+
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+
+		for i := range value.Len() {
+			item, err := exportNode(value.Index(i), true)
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content, item)
+		}
+
+		return node, nil
+	case reflect.Map:
+		return exportMapNode(value, keep)
+	default:
+		if value.IsZero() && !keep {
+			return nil, nil //nolint:nilnil // zero values are left out
+		}
+
+		node := &yaml.Node{}
+		if err := node.Encode(value.Interface()); err != nil {
+			return nil, errors.Wrapf(err, "encoding %v", value.Interface())
+		}
+
+		return node, nil
+	}
+}
+
+// exportStructNode converts a struct to a YAML mapping keyed by the yaml tags of its
+// fields.
+func exportStructNode(value reflect.Value, keep bool) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		child, err := exportNode(value.Field(i), false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s", name)
+		}
+
+		if child != nil {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: name}, child)
+		}
+	}
+
+	if len(node.Content) == 0 && !keep {
+		return nil, nil //nolint:nilnil // empty sections are left out
+	}
+
+	return node, nil
+}
+
+// exportMapNode converts a map to a YAML mapping with sorted keys.
+func exportMapNode(value reflect.Value, keep bool) (*yaml.Node, error) {
+	if value.Len() == 0 && !keep {
+		return nil, nil //nolint:nilnil // empty maps are left out
+	}
+
+	keys := value.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+	})
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, key := range keys {
+		child, err := exportNode(value.MapIndex(key), true)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(key.Interface())},
+			child)
+	}
+
+	return node, nil
+}
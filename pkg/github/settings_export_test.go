@@ -0,0 +1,276 @@
+package github
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+var exportResponses = map[string]string{
+	"GET /repos/org/repo": `{
+		"allow_squash_merge":true,"allow_merge_commit":false,"allow_rebase_merge":false,
+		"delete_branch_on_merge":true,"squash_merge_commit_title":"PR_TITLE",
+		"visibility":"public","default_branch":"main","description":"",
+		"topics":["go","cli"],"has_issues":true,"has_wiki":false,
+		"security_and_analysis":{
+			"secret_scanning":{"status":"enabled"},
+			"secret_scanning_non_provider_patterns":{"status":"disabled"}}}`,
+	"GET /repos/org/repo/vulnerability-alerts":            ``,
+	"GET /repos/org/repo/private-vulnerability-reporting": `{"enabled":false}`,
+	"POST /graphql": `{"data":{"repository":{"id":"R_1","branchProtectionRules":{
+		"nodes":[{
+			"id":"BPR_1","pattern":"main",
+			"requiresStatusChecks":true,"requiresStrictStatusChecks":true,
+			"requiredStatusCheckContexts":[],
+			"requiresApprovingReviews":true,"requiredApprovingReviewCount":1,
+			"bypassPullRequestAllowances":{"nodes":[
+				{"actor":{"__typename":"App","slug":"renovate"}}]},
+			"restrictsPushes":true,"pushAllowances":{"nodes":[]}
+		}],
+		"pageInfo":{"hasNextPage":false}
+	}}}}`,
+	"GET /repos/org/repo/rulesets": `[
+		{"id":1,"name":"main","source_type":"Repository"},
+		{"id":2,"name":"org-sync/base","source_type":"Organization"}]`,
+	"GET /repos/org/repo/rulesets/1": `{
+		"id":1,"name":"main","target":"branch","enforcement":"active",
+		"conditions":{"ref_name":{"include":["~DEFAULT_BRANCH"],"exclude":[]}},
+		"bypass_actors":[
+			{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"always"},
+			{"actor_id":5,"actor_type":"RepositoryRole","bypass_mode":"pull_request"},
+			{"actor_id":42,"actor_type":"Integration","bypass_mode":"always"}],
+		"rules":[
+			{"type":"deletion"},
+			{"type":"pull_request","parameters":{"required_approving_review_count":2,
+				"dismiss_stale_reviews_on_push":true,"require_code_owner_review":false,
+				"require_last_push_approval":false,"required_review_thread_resolution":true,
+				"allowed_merge_methods":["squash"]}},
+			{"type":"required_status_checks","parameters":{
+				"strict_required_status_checks_policy":false,
+				"required_status_checks":[{"context":"test","integration_id":15368}]}}]}`,
+}
+
+func TestExportSettings(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, exportResponses)
+
+	got, err := ExportSettings(context.Background(), logger.New("error"), client, "org", "repo")
+	if err != nil {
+		t.Fatalf("ExportSettings() error = %v", err)
+	}
+
+	want := &SettingsDefinition{
+		Repository: configtypes.RepositorySettingsConfig{
+			AllowSquashMerge:       github.Ptr(true),
+			AllowMergeCommit:       github.Ptr(false),
+			AllowRebaseMerge:       github.Ptr(false),
+			DeleteBranchOnMerge:    github.Ptr(true),
+			SquashMergeCommitTitle: github.Ptr("PR_TITLE"),
+			Visibility:             github.Ptr("public"),
+			DefaultBranch:          github.Ptr("main"),
+			Topics:                 &configtypes.TopicsConfig{Names: []string{"go", "cli"}},
+		},
+		Features: configtypes.FeaturesConfig{
+			HasIssues: github.Ptr(true),
+			HasWiki:   github.Ptr(false),
+		},
+		Security: configtypes.SecurityConfig{
+			SecretScanning:                    github.Ptr("enabled"),
+			SecretScanningNonProviderPatterns: github.Ptr("disabled"),
+			VulnerabilityAlerts:               github.Ptr("enabled"),
+			PrivateVulnerabilityReporting:     github.Ptr("disabled"),
+		},
+		BranchProtection: []configtypes.BranchProtectionRuleConfig{
+			{
+				Pattern: "main",
+				RequiredStatusChecks: &configtypes.RequiredStatusChecks{
+					Strict: github.Ptr(true),
+				},
+				RequiredReviews: &configtypes.RequiredReviews{
+					RequiredApprovingReviewCount: github.Ptr(1),
+					DismissStaleReviews:          github.Ptr(false),
+					RequireCodeOwnerReviews:      github.Ptr(false),
+					RequireLastPushApproval:      github.Ptr(false),
+					BypassPullRequestAllowances: &configtypes.BypassPullRequestAllowances{
+						Apps: []string{"renovate"},
+					},
+				},
+				EnforceAdmins:                  github.Ptr(false),
+				RequireLinearHistory:           github.Ptr(false),
+				AllowForcePushes:               github.Ptr(false),
+				AllowDeletions:                 github.Ptr(false),
+				RequiredConversationResolution: github.Ptr(false),
+				Restrictions:                   &configtypes.BranchRestrictionsConfig{},
+			},
+		},
+		Rulesets: []configtypes.RulesetConfig{
+			{
+				Name:        "main",
+				Target:      "branch",
+				Enforcement: "active",
+				Conditions: &configtypes.RulesetConditionsConfig{
+					RefName: &configtypes.RefNameCondition{Include: []string{"~DEFAULT_BRANCH"}},
+				},
+				BypassActors: []configtypes.BypassActorConfig{
+					{OrgAdmin: github.Ptr(true), BypassMode: "always"},
+					{Role: "admin", BypassMode: "pull_request"},
+					{ActorID: 42, ActorType: "Integration", BypassMode: "always"},
+				},
+				Rules: &configtypes.RulesetRulesConfig{
+					PullRequest: &configtypes.PullRequestRuleConfig{
+						DismissStaleReviewsOnPush:      github.Ptr(true),
+						RequireCodeOwnerReview:         github.Ptr(false),
+						RequireLastPushApproval:        github.Ptr(false),
+						RequiredApprovingReviewCount:   github.Ptr(2),
+						RequiredReviewThreadResolution: github.Ptr(true),
+						AllowedMergeMethods:            []string{"squash"},
+					},
+					RequiredStatusChecks: &configtypes.StatusChecksRuleConfig{
+						StrictRequiredStatusChecksPolicy: github.Ptr(false),
+						RequiredStatusChecks: []configtypes.StatusCheckConfig{
+							{Context: "test", IntegrationID: github.Ptr(int64(15368))},
+						},
+					},
+					Deletion: github.Ptr(true),
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ExportSettings() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExportSettingsFileRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, exportResponses)
+	log := logger.New("error")
+
+	exported, err := ExportSettings(context.Background(), log, client, "org", "repo")
+	if err != nil {
+		t.Fatalf("ExportSettings() error = %v", err)
+	}
+
+	data, err := ExportSettingsFile(context.Background(), log, client, "org", "repo", "")
+	if err != nil {
+		t.Fatalf("ExportSettingsFile() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "settings.yml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("writing settings file: %v", err)
+	}
+
+	parsed, err := parseSettingsFile(path)
+	if err != nil {
+		t.Fatalf("parseSettingsFile() error = %v", err)
+	}
+
+	if diff := cmp.Diff(exported, parsed); diff != "" {
+		t.Errorf("round trip mismatch (-exported +parsed):\n%s\n%s", diff, data)
+	}
+}
+
+func TestExportSettingsOverrides(t *testing.T) {
+	t.Parallel()
+
+	orgSettings := &SettingsDefinition{
+		Repository: configtypes.RepositorySettingsConfig{
+			AllowSquashMerge: github.Ptr(true),
+			AllowMergeCommit: github.Ptr(true),
+			Description:      github.Ptr("{{REPO_NAME}}"),
+		},
+		Features: configtypes.FeaturesConfig{HasIssues: github.Ptr(true)},
+		BranchProtection: []configtypes.BranchProtectionRuleConfig{
+			{
+				Pattern: "main",
+				RequiredReviews: &configtypes.RequiredReviews{
+					RequiredApprovingReviewCount: github.Ptr(1),
+					DismissStaleReviews:          github.Ptr(true),
+				},
+			},
+		},
+	}
+
+	exported := &SettingsDefinition{
+		Repository: configtypes.RepositorySettingsConfig{
+			AllowSquashMerge: github.Ptr(true),
+			AllowMergeCommit: github.Ptr(false),
+		},
+		Features: configtypes.FeaturesConfig{HasIssues: github.Ptr(true)},
+		BranchProtection: []configtypes.BranchProtectionRuleConfig{
+			{
+				Pattern: "main",
+				RequiredReviews: &configtypes.RequiredReviews{
+					RequiredApprovingReviewCount: github.Ptr(2),
+					DismissStaleReviews:          github.Ptr(true),
+				},
+			},
+			{Pattern: "release/*", AllowDeletions: github.Ptr(false)},
+		},
+	}
+
+	overrides, err := ExportSettingsOverrides(logger.New("error"), orgSettings, exported)
+	if err != nil {
+		t.Fatalf("ExportSettingsOverrides() error = %v", err)
+	}
+
+	want := []configtypes.SettingsMergeConfig{
+		{
+			Section:   "repository",
+			Overrides: map[string]any{"allow_merge_commit": false, "description": nil},
+		},
+		{
+			Section: "main",
+			Overrides: map[string]any{
+				"required_reviews": map[string]any{"count": 2},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, overrides); diff != "" {
+		t.Errorf("ExportSettingsOverrides() mismatch (-want +got):\n%s", diff)
+	}
+
+	merged, err := ApplySettingsMerge(logger.New("error"), orgSettings,
+		&configtypes.SyncConfig{
+			Sync: configtypes.SyncSettings{
+				Settings: configtypes.SettingsConfig{Merge: overrides},
+			},
+		})
+	if err != nil {
+		t.Fatalf("ApplySettingsMerge() error = %v", err)
+	}
+
+	for _, section := range []struct {
+		name           string
+		merged, wanted any
+	}{
+		{name: "repository", merged: merged.Repository, wanted: exported.Repository},
+		{name: "main", merged: merged.BranchProtection[0], wanted: exported.BranchProtection[0]},
+	} {
+		got, err := marshalExportYAML(section.merged)
+		if err != nil {
+			t.Fatalf("marshalExportYAML() error = %v", err)
+		}
+
+		wanted, err := marshalExportYAML(section.wanted)
+		if err != nil {
+			t.Fatalf("marshalExportYAML() error = %v", err)
+		}
+
+		if diff := cmp.Diff(string(wanted), string(got)); diff != "" {
+			t.Errorf("merged %s mismatch (-want +got):\n%s", section.name, diff)
+		}
+	}
+}
@@ -97,6 +97,36 @@ func mergeSecuritySettings(
 	return result, nil
 }
 
+// mergeActionsSettings merges actions settings with overrides.
+func mergeActionsSettings(
+	org *configtypes.ActionsConfig,
+	overrides map[string]any,
+	strategy configtypes.MergeStrategy,
+) (*configtypes.ActionsConfig, error) {
+	result := &configtypes.ActionsConfig{}
+
+	if err := mergeStructWithOverrides(org, overrides, strategy, result); err != nil {
+		return nil, errors.Wrap(err, "merging actions settings")
+	}
+
+	return result, nil
+}
+
+// mergeAccessSettings merges access settings with overrides.
+func mergeAccessSettings(
+	org *configtypes.AccessConfig,
+	overrides map[string]any,
+	strategy configtypes.MergeStrategy,
+) (*configtypes.AccessConfig, error) {
+	result := &configtypes.AccessConfig{}
+
+	if err := mergeStructWithOverrides(org, overrides, strategy, result); err != nil {
+		return nil, errors.Wrap(err, "merging access settings")
+	}
+
+	return result, nil
+}
+
 // mergeBranchProtectionRule merges a branch protection rule with overrides.
 func mergeBranchProtectionRule(
 	org *configtypes.BranchProtectionRuleConfig,
@@ -127,6 +157,21 @@ func mergeRulesetConfig(
 	return result, nil
 }
 
+// mergeEnvironmentConfig merges an environment config with overrides.
+func mergeEnvironmentConfig(
+	org *configtypes.EnvironmentConfig,
+	overrides map[string]any,
+	strategy configtypes.MergeStrategy,
+) (*configtypes.EnvironmentConfig, error) {
+	result := &configtypes.EnvironmentConfig{}
+
+	if err := mergeStructWithOverrides(org, overrides, strategy, result); err != nil {
+		return nil, errors.Wrap(err, "merging environment config")
+	}
+
+	return result, nil
+}
+
 // ApplySettingsMerge applies merge configurations to settings.
 // It iterates through merge configs and applies overrides to matching sections.
 // Returns original settings and nil error if no merge configs exist (graceful no-op).
@@ -140,21 +185,33 @@ func ApplySettingsMerge(
 	}
 
 	// Create a copy to avoid mutating the original.
-	// Repository, Features, Security are value types (no pointer fields) - safe for shallow copy.
-	// BranchProtection and Rulesets slices are deep copied below.
+	// Repository, Features, Security, Actions are value types - safe for shallow copy.
+	// Access maps are replaced, never modified, when merging.
+	// Variables, Secrets, Webhooks and CustomProperties are not merged and share the
+	// original values.
+	// BranchProtection, Rulesets and Environments slices are deep copied below.
 	bpLen := len(orgSettings.BranchProtection)
 	rsLen := len(orgSettings.Rulesets)
+	envLen := len(orgSettings.Environments)
 
 	result := &SettingsDefinition{
 		Repository:       orgSettings.Repository,
 		Features:         orgSettings.Features,
 		Security:         orgSettings.Security,
+		Actions:          orgSettings.Actions,
+		Access:           orgSettings.Access,
 		BranchProtection: make([]configtypes.BranchProtectionRuleConfig, bpLen),
 		Rulesets:         make([]configtypes.RulesetConfig, rsLen),
+		Environments:     make([]configtypes.EnvironmentConfig, envLen),
+		Variables:        orgSettings.Variables,
+		Secrets:          orgSettings.Secrets,
+		Webhooks:         orgSettings.Webhooks,
+		CustomProperties: orgSettings.CustomProperties,
 	}
 
 	copy(result.BranchProtection, orgSettings.BranchProtection)
 	copy(result.Rulesets, orgSettings.Rulesets)
+	copy(result.Environments, orgSettings.Environments)
 
 	// Apply each merge configuration
 	for _, mergeConfig := range syncConfig.Sync.Settings.Merge {
@@ -211,6 +268,22 @@ func applySingleMerge(
 
 		settings.Security = *merged
 
+	case "actions":
+		merged, err := mergeActionsSettings(&settings.Actions, overrides, strategy)
+		if err != nil {
+			return err
+		}
+
+		settings.Actions = *merged
+
+	case "access":
+		merged, err := mergeAccessSettings(&settings.Access, overrides, strategy)
+		if err != nil {
+			return err
+		}
+
+		settings.Access = *merged
+
 	default:
 		// Try branch protection pattern match
 		if err := tryMergeBranchProtection(settings, section, overrides, strategy); err == nil {
@@ -222,6 +295,11 @@ func applySingleMerge(
 			return nil
 		}
 
+		// Try environment name match
+		if err := tryMergeEnvironment(settings, section, overrides, strategy); err == nil {
+			return nil
+		}
+
 		// Unknown section - skip silently (graceful degradation)
 		return errors.Wrapf(ErrSettingsMerge, "unknown section: %s", section)
 	}
@@ -278,3 +356,26 @@ func tryMergeRuleset(
 
 	return errors.Wrapf(ErrSettingsMerge, "ruleset not found: %s", name)
 }
+
+// tryMergeEnvironment attempts to merge an environment by name.
+func tryMergeEnvironment(
+	settings *SettingsDefinition,
+	name string,
+	overrides map[string]any,
+	strategy configtypes.MergeStrategy,
+) error {
+	for i := range settings.Environments {
+		if settings.Environments[i].Name == name {
+			merged, err := mergeEnvironmentConfig(&settings.Environments[i], overrides, strategy)
+			if err != nil {
+				return err
+			}
+
+			settings.Environments[i] = *merged
+
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrSettingsMerge, "environment not found: %s", name)
+}
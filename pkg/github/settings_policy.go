@@ -0,0 +1,347 @@
+package github
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// deletedField is the field under which blocked ruleset and branch protection rule
+// deletions are reported.
+const deletedField = "deleted"
+
+// weakeningRules classify the field changes that weaken a repository's security, keyed
+// by the field names used in settings diffs. Disabling secret scanning or push
+// protection, dropping admin enforcement, linear history, signatures or required status
+// checks, allowing force pushes or deletions, deleting branch protection rules, and
+// deleting or deactivating rulesets are weakening. Lowering the required review count
+// is prevented separately, see getRequiredReviewCount.
+var weakeningRules = map[string]func(current any, desired any) bool{
+	"security.secret_scanning":                 disablesFeature,
+	"security.secret_scanning_push_protection": disablesFeature,
+
+	"enforce_admins":                  turnsOff,
+	"require_linear_history":          turnsOff,
+	"allow_force_pushes":              turnsOn,
+	"allow_deletions":                 turnsOn,
+	"required_status_checks":          turnsOff,
+	"required_status_checks.contexts": removesValues,
+
+	deletedField:                    turnsOn,
+	"enforcement":                   leavesActive,
+	"rules.required_signatures":     turnsOff,
+	"rules.required_linear_history": turnsOff,
+	"rules.non_fast_forward":        turnsOff,
+	"rules.deletion":                turnsOff,
+	"rules.required_status_checks":  turnsOff,
+	"rules.required_status_checks.required_status_checks": removesValues,
+}
+
+// WeakeningPolicy blocks settings changes that weaken a repository's security unless
+// they are explicitly allowed. Blocked changes keep their current values and are
+// recorded. A nil policy allows every change.
+type WeakeningPolicy struct {
+	// Allow applies weakening changes, e.g. when set by the --allow-weakening flag.
+	Allow bool
+	// Justification explains why the repository's security may be weakened. A
+	// non-empty justification allows weakening changes.
+	Justification string
+	// Blocked lists the weakening changes that were not applied.
+	Blocked []BlockedChange
+}
+
+// isWeakening reports whether a field change weakens a repository's security.
+func isWeakening(diff FieldDiff) bool {
+	weakens, ok := weakeningRules[diff.Field]
+
+	return ok && weakens(diff.Current, diff.Desired)
+}
+
+// admit reports whether a field change may be applied. Weakening changes are logged
+// and, unless allowed, recorded as blocked.
+func (p *WeakeningPolicy) admit(
+	log *logger.Logger,
+	section string,
+	target string,
+	diff FieldDiff,
+) bool {
+	if p == nil || !isWeakening(diff) {
+		return true
+	}
+
+	if p.Allow || p.Justification != "" {
+		log.Warn("applying settings change weakening security",
+			"section", section,
+			"target", target,
+			"field", diff.Field,
+			"current", diff.Current,
+			"desired", diff.Desired,
+			"justification", p.Justification,
+		)
+
+		return true
+	}
+
+	log.Warn("blocked settings change weakening security",
+		"section", section,
+		"target", target,
+		"field", diff.Field,
+		"current", diff.Current,
+		"desired", diff.Desired,
+	)
+
+	p.Blocked = append(p.Blocked, BlockedChange{
+		Section:   section,
+		Target:    target,
+		FieldDiff: diff,
+	})
+
+	return false
+}
+
+// reviewRepositorySecurity removes blocked secret scanning changes from a repository
+// update. Returns nil when nothing is left to update.
+func (p *WeakeningPolicy) reviewRepositorySecurity(
+	log *logger.Logger,
+	update *github.Repository,
+	current *github.Repository,
+) *github.Repository {
+	if update == nil || update.SecurityAndAnalysis == nil {
+		return update
+	}
+
+	security := update.SecurityAndAnalysis
+	currentSecurity := current.GetSecurityAndAnalysis()
+
+	if security.SecretScanning != nil && !p.admit(log, "security", "", FieldDiff{
+		Field:   "security.secret_scanning",
+		Current: getSecurityFeatureStatus(currentSecurity.GetSecretScanning()),
+		Desired: security.SecretScanning.GetStatus(),
+	}) {
+		security.SecretScanning = nil
+	}
+
+	if security.SecretScanningPushProtection != nil && !p.admit(log, "security", "", FieldDiff{
+		Field:   "security.secret_scanning_push_protection",
+		Current: getSecurityFeatureStatus(currentSecurity.GetSecretScanningPushProtection()),
+		Desired: security.SecretScanningPushProtection.GetStatus(),
+	}) {
+		security.SecretScanningPushProtection = nil
+	}
+
+	if reflect.DeepEqual(*security, github.SecurityAndAnalysis{}) {
+		update.SecurityAndAnalysis = nil
+	}
+
+	if reflect.DeepEqual(*update, github.Repository{}) {
+		return nil
+	}
+
+	return update
+}
+
+// reviewBranchProtection keeps the current values of blocked branch protection fields
+// in the planned requests. Changes left without differences are dropped. Unprotected
+// rules and branches have nothing to weaken.
+func (p *WeakeningPolicy) reviewBranchProtection(
+	log *logger.Logger,
+	changes []branchProtectionChange,
+) []branchProtectionChange {
+	reviewed := make([]branchProtectionChange, 0, len(changes))
+
+	for _, change := range changes {
+		if change.Current == nil {
+			reviewed = append(reviewed, change)
+
+			continue
+		}
+
+		target := change.Branch
+		if target == "" {
+			target = change.Pattern
+		}
+
+		var fields []FieldDiff
+
+		for _, diff := range change.Fields {
+			if p.admit(log, "branch_protection", target, diff) {
+				fields = append(fields, diff)
+
+				continue
+			}
+
+			fields = restoreProtectionField(fields, change.Request, change.Current, diff)
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		change.Fields = fields
+		reviewed = append(reviewed, change)
+	}
+
+	return reviewed
+}
+
+// restoreProtectionField sets a blocked field of a protection request back to its
+// current value. Removed status check contexts are kept while added ones are still
+// required, so the diff of the remaining additions is appended.
+func restoreProtectionField(
+	fields []FieldDiff,
+	req *github.ProtectionRequest,
+	current *github.Protection,
+	diff FieldDiff,
+) []FieldDiff {
+	switch diff.Field {
+	case "enforce_admins":
+		req.EnforceAdmins = true
+	case "require_linear_history":
+		req.RequireLinearHistory = github.Ptr(true)
+	case "allow_force_pushes":
+		req.AllowForcePushes = github.Ptr(false)
+	case "allow_deletions":
+		req.AllowDeletions = github.Ptr(false)
+	case "required_status_checks":
+		req.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   current.RequiredStatusChecks.Strict,
+			Contexts: github.Ptr(current.RequiredStatusChecks.GetContexts()),
+		}
+	case "required_status_checks.contexts":
+		currentContexts := current.RequiredStatusChecks.GetContexts()
+		contexts := sortedSet(
+			append(slices.Clone(currentContexts), req.RequiredStatusChecks.GetContexts()...),
+		)
+		req.RequiredStatusChecks.Contexts = &contexts
+
+		return appendSetDiff(fields, diff.Field, currentContexts, contexts)
+	}
+
+	return fields
+}
+
+// reviewRulesets keeps blocked rulesets and ruleset fields at their current state.
+// Updates left without differences become unchanged.
+func (p *WeakeningPolicy) reviewRulesets(log *logger.Logger, changes []rulesetChange) {
+	for i := range changes {
+		change := &changes[i]
+
+		switch change.Action {
+		case ResourceActionDeleted:
+			diff := FieldDiff{Field: deletedField, Current: false, Desired: true}
+			if !p.admit(log, "rulesets", change.Name, diff) {
+				change.Action = ResourceActionUnchanged
+			}
+		case ResourceActionUpdated:
+			restored := false
+
+			for _, diff := range change.Fields {
+				if !p.admit(log, "rulesets", change.Name, diff) {
+					restoreRulesetField(change.Ruleset, change.Current, diff.Field)
+
+					restored = true
+				}
+			}
+
+			if !restored {
+				continue
+			}
+
+			change.Fields = diffRuleset(change.Current, change.Ruleset)
+			if len(change.Fields) == 0 {
+				change.Action = ResourceActionUnchanged
+			}
+		case ResourceActionCreated, ResourceActionUnchanged:
+			continue
+		}
+	}
+}
+
+// restoreRulesetField sets a blocked field of a desired ruleset back to its current
+// value. Removed status checks are kept alongside the desired ones.
+func restoreRulesetField(
+	desired *github.RepositoryRuleset,
+	current *github.RepositoryRuleset,
+	field string,
+) {
+	switch field {
+	case "enforcement":
+		desired.Enforcement = current.Enforcement
+	case "rules.required_signatures":
+		desired.Rules.RequiredSignatures = current.Rules.RequiredSignatures
+	case "rules.required_linear_history":
+		desired.Rules.RequiredLinearHistory = current.Rules.RequiredLinearHistory
+	case "rules.non_fast_forward":
+		desired.Rules.NonFastForward = current.Rules.NonFastForward
+	case "rules.deletion":
+		desired.Rules.Deletion = current.Rules.Deletion
+	case "rules.required_status_checks":
+		desired.Rules.RequiredStatusChecks = current.Rules.RequiredStatusChecks
+	case "rules.required_status_checks.required_status_checks":
+		checks := desired.Rules.RequiredStatusChecks
+		checks.RequiredStatusChecks = mergeRuleStatusChecks(
+			current.Rules.RequiredStatusChecks.RequiredStatusChecks, checks.RequiredStatusChecks,
+		)
+	}
+}
+
+// mergeRuleStatusChecks returns the desired status checks followed by the current ones
+// they do not contain.
+func mergeRuleStatusChecks(current, desired []*github.RuleStatusCheck) []*github.RuleStatusCheck {
+	merged := slices.Clone(desired)
+
+	for _, check := range current {
+		if !slices.ContainsFunc(merged, func(other *github.RuleStatusCheck) bool {
+			return other.Context == check.Context &&
+				other.GetIntegrationID() == check.GetIntegrationID()
+		}) {
+			merged = append(merged, check)
+		}
+	}
+
+	return merged
+}
+
+// disablesFeature reports whether a security feature status changes from enabled to
+// disabled.
+func disablesFeature(current, desired any) bool {
+	return current == securityStatusEnabled && desired == securityStatusDisabled
+}
+
+// turnsOff reports whether a boolean setting changes from true to false.
+func turnsOff(current, desired any) bool {
+	return current == true && desired == false
+}
+
+// turnsOn reports whether a boolean setting changes from false to true.
+func turnsOn(current, desired any) bool {
+	return current == false && desired == true
+}
+
+// removesValues reports whether a list setting loses any of its current values.
+func removesValues(current, desired any) bool {
+	currentValues, ok := current.([]string)
+	if !ok {
+		return false
+	}
+
+	desiredValues, _ := desired.([]string)
+
+	for _, value := range currentValues {
+		if !slices.Contains(desiredValues, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// leavesActive reports whether a ruleset enforcement changes from active to evaluate
+// or disabled.
+func leavesActive(current, desired any) bool {
+	return current == string(github.RulesetEnforcementActive) &&
+		desired != string(github.RulesetEnforcementActive)
+}
@@ -0,0 +1,355 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestIsWeakening(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		diff FieldDiff
+		want bool
+	}{
+		{
+			name: "disabling secret scanning",
+			diff: FieldDiff{
+				Field:   "security.secret_scanning",
+				Current: "enabled",
+				Desired: "disabled",
+			},
+			want: true,
+		},
+		{
+			name: "enabling push protection",
+			diff: FieldDiff{
+				Field:   "security.secret_scanning_push_protection",
+				Current: "disabled",
+				Desired: "enabled",
+			},
+		},
+		{
+			name: "allowing force pushes",
+			diff: FieldDiff{Field: "allow_force_pushes", Current: false, Desired: true},
+			want: true,
+		},
+		{
+			name: "enforcing admins",
+			diff: FieldDiff{Field: "enforce_admins", Current: false, Desired: true},
+		},
+		{
+			name: "removing a status check",
+			diff: FieldDiff{
+				Field:   "required_status_checks.contexts",
+				Current: []string{"lint", "test"},
+				Desired: []string{"build", "test"},
+			},
+			want: true,
+		},
+		{
+			name: "adding a status check",
+			diff: FieldDiff{
+				Field:   "required_status_checks.contexts",
+				Current: []string{"test"},
+				Desired: []string{"lint", "test"},
+			},
+		},
+		{
+			name: "dropping required signatures",
+			diff: FieldDiff{Field: "rules.required_signatures", Current: true, Desired: false},
+			want: true,
+		},
+		{
+			name: "switching an active ruleset to evaluate",
+			diff: FieldDiff{Field: "enforcement", Current: "active", Desired: "evaluate"},
+			want: true,
+		},
+		{
+			name: "unclassified field",
+			diff: FieldDiff{Field: "required_reviews.dismiss_stale", Current: true, Desired: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isWeakening(tt.diff); got != tt.want {
+				t.Errorf("isWeakening() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeakeningPolicyReviewBranchProtection(t *testing.T) {
+	t.Parallel()
+
+	plan := func() []branchProtectionChange {
+		return []branchProtectionChange{
+			{
+				BranchProtectionDiff: BranchProtectionDiff{
+					Pattern: "main",
+					Fields: []FieldDiff{
+						{
+							Field:   "required_status_checks.contexts",
+							Current: []string{"lint", "test"},
+							Desired: []string{"build", "test"},
+						},
+						{Field: "enforce_admins", Current: true, Desired: false},
+						{Field: "required_conversation_resolution", Current: false, Desired: true},
+					},
+				},
+				Request: &github.ProtectionRequest{
+					RequiredStatusChecks: &github.RequiredStatusChecks{
+						Contexts: &[]string{"build", "test"},
+					},
+					RequiredConversationResolution: github.Ptr(true),
+				},
+				Current: &github.Protection{
+					RequiredStatusChecks: &github.RequiredStatusChecks{
+						Contexts: &[]string{"lint", "test"},
+					},
+					EnforceAdmins: &github.AdminEnforcement{Enabled: true},
+				},
+			},
+			{
+				BranchProtectionDiff: BranchProtectionDiff{
+					Pattern: "release/*",
+					Fields: []FieldDiff{
+						{Field: "allow_force_pushes", Current: false, Desired: true},
+					},
+				},
+				Request: &github.ProtectionRequest{AllowForcePushes: github.Ptr(true)},
+				Current: &github.Protection{},
+			},
+			{
+				BranchProtectionDiff: BranchProtectionDiff{
+					Pattern: "release/1.0",
+					Fields:  []FieldDiff{{Field: deletedField, Current: false, Desired: true}},
+				},
+				Current: &github.Protection{},
+				RuleID:  "BPR_2",
+				Delete:  true,
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		policy       *WeakeningPolicy
+		wantFields   map[string][]FieldDiff
+		wantBlocked  []string
+		wantContexts []string
+	}{
+		{
+			name:   "blocked",
+			policy: &WeakeningPolicy{},
+			wantFields: map[string][]FieldDiff{
+				"main": {
+					{
+						Field:   "required_status_checks.contexts",
+						Current: []string{"lint", "test"},
+						Desired: []string{"build", "lint", "test"},
+					},
+					{Field: "required_conversation_resolution", Current: false, Desired: true},
+				},
+			},
+			wantBlocked: []string{
+				"main:required_status_checks.contexts",
+				"main:enforce_admins",
+				"release/*:allow_force_pushes",
+				"release/1.0:deleted",
+			},
+			wantContexts: []string{"build", "lint", "test"},
+		},
+		{
+			name:   "justified",
+			policy: &WeakeningPolicy{Justification: "legacy checks removed"},
+			wantFields: map[string][]FieldDiff{
+				"main":        plan()[0].Fields,
+				"release/*":   plan()[1].Fields,
+				"release/1.0": plan()[2].Fields,
+			},
+			wantContexts: []string{"build", "test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reviewed := tt.policy.reviewBranchProtection(logger.New("error"), plan())
+
+			fields := make(map[string][]FieldDiff, len(reviewed))
+			for _, change := range reviewed {
+				fields[change.Pattern] = change.Fields
+			}
+
+			if diff := cmp.Diff(tt.wantFields, fields); diff != "" {
+				t.Errorf("reviewed fields mismatch (-want +got):\n%s", diff)
+			}
+
+			var blocked []string
+			for _, change := range tt.policy.Blocked {
+				blocked = append(blocked, change.Target+":"+change.Field)
+			}
+
+			if diff := cmp.Diff(tt.wantBlocked, blocked); diff != "" {
+				t.Errorf("blocked changes mismatch (-want +got):\n%s", diff)
+			}
+
+			request := reviewed[0].Request
+
+			contexts := request.RequiredStatusChecks.GetContexts()
+			if diff := cmp.Diff(tt.wantContexts, contexts); diff != "" {
+				t.Errorf("requested contexts mismatch (-want +got):\n%s", diff)
+			}
+
+			if want := tt.policy.Justification != ""; request.EnforceAdmins != !want {
+				t.Errorf("requested enforce_admins = %v, want %v", request.EnforceAdmins, !want)
+			}
+		})
+	}
+}
+
+func TestWeakeningPolicyReviewRulesets(t *testing.T) {
+	t.Parallel()
+
+	current := &github.RepositoryRuleset{
+		ID:          github.Ptr(int64(1)),
+		Name:        "main",
+		Enforcement: github.RulesetEnforcementActive,
+		Rules: &github.RepositoryRulesetRules{
+			Deletion: &github.EmptyRuleParameters{},
+			RequiredStatusChecks: &github.RequiredStatusChecksRuleParameters{
+				RequiredStatusChecks: []*github.RuleStatusCheck{
+					{Context: "lint"},
+					{Context: "test"},
+				},
+			},
+		},
+	}
+	desired := &github.RepositoryRuleset{
+		Name:        "main",
+		Enforcement: github.RulesetEnforcementActive,
+		Rules: &github.RepositoryRulesetRules{
+			NonFastForward: &github.EmptyRuleParameters{},
+			RequiredStatusChecks: &github.RequiredStatusChecksRuleParameters{
+				RequiredStatusChecks: []*github.RuleStatusCheck{{Context: "test"}},
+			},
+		},
+	}
+
+	changes := []rulesetChange{
+		{
+			RulesetOutcome: RulesetOutcome{
+				Name:   "main",
+				ID:     1,
+				Action: ResourceActionUpdated,
+				Fields: diffRuleset(current, desired),
+			},
+			Ruleset: desired,
+			Current: current,
+		},
+		{
+			RulesetOutcome: RulesetOutcome{
+				Name:   "org-sync/legacy",
+				ID:     2,
+				Action: ResourceActionDeleted,
+			},
+		},
+	}
+
+	policy := &WeakeningPolicy{}
+	policy.reviewRulesets(logger.New("error"), changes)
+
+	want := []RulesetOutcome{
+		{
+			Name:   "main",
+			ID:     1,
+			Action: ResourceActionUpdated,
+			Fields: []FieldDiff{{Field: "rules.non_fast_forward", Current: false, Desired: true}},
+		},
+		{Name: "org-sync/legacy", ID: 2, Action: ResourceActionUnchanged},
+	}
+
+	if diff := cmp.Diff(want, rulesetOutcomes(changes)); diff != "" {
+		t.Errorf("reviewed rulesets mismatch (-want +got):\n%s", diff)
+	}
+
+	wantBlocked := []BlockedChange{
+		{
+			Section:   "rulesets",
+			Target:    "main",
+			FieldDiff: FieldDiff{Field: "rules.deletion", Current: true, Desired: false},
+		},
+		{
+			Section: "rulesets",
+			Target:  "main",
+			FieldDiff: FieldDiff{
+				Field:   "rules.required_status_checks.required_status_checks",
+				Current: []string{`{"context":"lint"}`, `{"context":"test"}`},
+				Desired: []string{`{"context":"test"}`},
+			},
+		},
+		{
+			Section:   "rulesets",
+			Target:    "org-sync/legacy",
+			FieldDiff: FieldDiff{Field: deletedField, Current: false, Desired: true},
+		},
+	}
+
+	if diff := cmp.Diff(wantBlocked, policy.Blocked); diff != "" {
+		t.Errorf("blocked changes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWeakeningPolicyReviewRepositorySecurity(t *testing.T) {
+	t.Parallel()
+
+	current := &github.Repository{
+		SecurityAndAnalysis: &github.SecurityAndAnalysis{
+			SecretScanning: &github.SecretScanning{Status: github.Ptr("enabled")},
+		},
+	}
+	update := &github.Repository{
+		SecurityAndAnalysis: &github.SecurityAndAnalysis{
+			SecretScanning: &github.SecretScanning{Status: github.Ptr("disabled")},
+			SecretScanningPushProtection: &github.SecretScanningPushProtection{
+				Status: github.Ptr("enabled"),
+			},
+		},
+	}
+
+	policy := &WeakeningPolicy{}
+
+	got := policy.reviewRepositorySecurity(logger.New("error"), update, current)
+
+	want := &github.Repository{
+		SecurityAndAnalysis: &github.SecurityAndAnalysis{
+			SecretScanningPushProtection: &github.SecretScanningPushProtection{
+				Status: github.Ptr("enabled"),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("reviewRepositorySecurity() mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(policy.Blocked) != 1 || policy.Blocked[0].Field != "security.secret_scanning" {
+		t.Errorf("blocked = %+v, want security.secret_scanning", policy.Blocked)
+	}
+
+	update.SecurityAndAnalysis.SecretScanningPushProtection = nil
+
+	if got := policy.reviewRepositorySecurity(logger.New("error"), update, current); got != nil {
+		t.Errorf("reviewRepositorySecurity() = %+v, want nil without remaining changes", got)
+	}
+}
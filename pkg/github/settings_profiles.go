@@ -0,0 +1,182 @@
+package github
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// ErrUnknownSettingsProfile indicates sync.settings.profile names a profile the
+// settings file does not define.
+var ErrUnknownSettingsProfile = errors.New("unknown settings profile")
+
+// ApplySettingsProfile layers the settings profile assigned to a repository on top of
+// the base settings. An explicit sync.settings.profile wins; otherwise the first
+// profile whose topics, custom properties or name pattern match is used. Returns the
+// base settings when no profile applies, along with the profile name and the reason it
+// was selected.
+func ApplySettingsProfile(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	settings *SettingsDefinition,
+	repository *github.Repository,
+	syncConfig *configtypes.SyncConfig,
+) (*SettingsDefinition, string, error) {
+	if len(settings.Profiles) == 0 && syncConfig.Sync.Settings.Profile == "" {
+		return settings, "", nil
+	}
+
+	profile, reason, err := resolveSettingsProfile(
+		ctx, client, org, repo, settings.Profiles, repository,
+		syncConfig.Sync.Settings.Profile,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	base := *settings
+	base.Profiles = nil
+
+	if profile == nil {
+		log.Info("no settings profile matched, using base settings")
+
+		return &base, "", nil
+	}
+
+	log.Info("resolved settings profile", "profile", profile.Name, "reason", reason)
+
+	result := &SettingsDefinition{}
+
+	err = mergeStructWithOverrides(&base, profile.Settings, configtypes.MergeStrategyDeep, result)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "applying settings profile %q", profile.Name)
+	}
+
+	return result, profile.Name, nil
+}
+
+// resolveSettingsProfile returns the profile assigned to a repository and why it was
+// selected, or nil when no profile matches. Custom property values are only fetched
+// when a profile selects by them.
+func resolveSettingsProfile(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	profiles []configtypes.SettingsProfileConfig,
+	repository *github.Repository,
+	explicit string,
+) (*configtypes.SettingsProfileConfig, string, error) {
+	if explicit != "" {
+		for i := range profiles {
+			if profiles[i].Name == explicit {
+				return &profiles[i], "sync.settings.profile", nil
+			}
+		}
+
+		return nil, "", errors.Wrapf(ErrUnknownSettingsProfile, "%q", explicit)
+	}
+
+	var properties map[string][]string
+
+	for i := range profiles {
+		profile := &profiles[i]
+
+		if len(profile.Match.CustomProperties) > 0 && properties == nil {
+			var err error
+
+			properties, err = fetchCustomPropertyValues(ctx, client, org, repo)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		reason, err := matchSettingsProfile(profile, repository, properties)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if reason != "" {
+			return profile, reason, nil
+		}
+	}
+
+	return nil, "", nil
+}
+
+// matchSettingsProfile returns why a profile matches a repository, or an empty string
+// when none of its selectors match.
+func matchSettingsProfile(
+	profile *configtypes.SettingsProfileConfig,
+	repository *github.Repository,
+	properties map[string][]string,
+) (string, error) {
+	for _, topic := range profile.Match.Topics {
+		if slices.Contains(repository.Topics, strings.ToLower(topic)) {
+			return "topic " + topic, nil
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(profile.Match.CustomProperties)) {
+		value := profile.Match.CustomProperties[name]
+
+		if slices.ContainsFunc(properties[name], func(current string) bool {
+			return strings.EqualFold(current, value)
+		}) {
+			return "custom property " + name + "=" + value, nil
+		}
+	}
+
+	if profile.Match.NamePattern == "" {
+		return "", nil
+	}
+
+	pattern, err := regexp.Compile(profile.Match.NamePattern)
+	if err != nil {
+		return "", errors.Wrapf(err, "compiling name pattern of settings profile %q", profile.Name)
+	}
+
+	if pattern.MatchString(repository.GetName()) {
+		return "name pattern " + profile.Match.NamePattern, nil
+	}
+
+	return "", nil
+}
+
+// fetchCustomPropertyValues returns the custom property values of a repository. Single
+// values are returned as one-element lists.
+func fetchCustomPropertyValues(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (map[string][]string, error) {
+	values, _, err := client.Repositories.GetAllCustomPropertyValues(ctx, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting custom property values")
+	}
+
+	properties := make(map[string][]string, len(values))
+
+	for _, value := range values {
+		switch v := value.Value.(type) {
+		case string:
+			properties[value.PropertyName] = []string{v}
+		case []string:
+			properties[value.PropertyName] = v
+		}
+	}
+
+	return properties, nil
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+var testProfiles = []configtypes.SettingsProfileConfig{
+	{
+		Name:  "library",
+		Match: configtypes.SettingsProfileMatchConfig{Topics: []string{"Library"}},
+		Settings: map[string]any{
+			"features": map[string]any{"has_wiki": true},
+		},
+	},
+	{
+		Name: "service",
+		Match: configtypes.SettingsProfileMatchConfig{
+			CustomProperties: map[string]string{"kind": "service"},
+		},
+		Settings: map[string]any{
+			"repository": map[string]any{"allow_merge_commit": true},
+		},
+	},
+	{
+		Name:  "docs",
+		Match: configtypes.SettingsProfileMatchConfig{NamePattern: `^docs-`},
+	},
+}
+
+func TestResolveSettingsProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		repository *github.Repository
+		explicit   string
+		want       string
+		wantReason string
+		wantErr    error
+	}{
+		{
+			name:       "topic",
+			repository: &github.Repository{Name: github.Ptr("lib"), Topics: []string{"library"}},
+			want:       "library",
+			wantReason: "topic Library",
+		},
+		{
+			name:       "custom property",
+			repository: &github.Repository{Name: github.Ptr("api")},
+			want:       "service",
+			wantReason: "custom property kind=service",
+		},
+		{
+			name:       "explicit profile wins",
+			repository: &github.Repository{Name: github.Ptr("docs-site")},
+			explicit:   "library",
+			want:       "library",
+			wantReason: "sync.settings.profile",
+		},
+		{
+			name:       "unknown explicit profile",
+			repository: &github.Repository{Name: github.Ptr("api")},
+			explicit:   "archive-ready",
+			wantErr:    ErrUnknownSettingsProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/api/properties/values": `[
+					{"property_name":"kind","value":"Service"}]`,
+				"GET /repos/org/docs-site/properties/values": `[]`,
+			})
+
+			profile, reason, err := resolveSettingsProfile(
+				context.Background(), client, "org", tt.repository.GetName(),
+				testProfiles, tt.repository, tt.explicit,
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveSettingsProfile() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var got string
+			if profile != nil {
+				got = profile.Name
+			}
+
+			if got != tt.want || reason != tt.wantReason {
+				t.Errorf("resolveSettingsProfile() = %q (%s), want %q (%s)",
+					got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestApplySettingsProfile(t *testing.T) {
+	t.Parallel()
+
+	_, client := newFakeGitHub(t, map[string]string{})
+
+	base := &SettingsDefinition{
+		Repository: configtypes.RepositorySettingsConfig{
+			AllowSquashMerge: github.Ptr(true),
+		},
+		Features: configtypes.FeaturesConfig{HasIssues: github.Ptr(true)},
+		Profiles: testProfiles,
+	}
+
+	got, profile, err := ApplySettingsProfile(
+		context.Background(), logger.New("error"), client, "org", "lib", base,
+		&github.Repository{Name: github.Ptr("lib"), Topics: []string{"library"}},
+		&configtypes.SyncConfig{},
+	)
+	if err != nil {
+		t.Fatalf("ApplySettingsProfile() error = %v", err)
+	}
+
+	if profile != "library" {
+		t.Errorf("profile = %q, want library", profile)
+	}
+
+	wantRepository := configtypes.RepositorySettingsConfig{AllowSquashMerge: github.Ptr(true)}
+	if diff := cmp.Diff(wantRepository, got.Repository); diff != "" {
+		t.Errorf("repository mismatch (-want +got):\n%s", diff)
+	}
+
+	wantFeatures := configtypes.FeaturesConfig{
+		HasIssues: github.Ptr(true),
+		HasWiki:   github.Ptr(true),
+	}
+	if diff := cmp.Diff(wantFeatures, got.Features); diff != "" {
+		t.Errorf("features mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(got.Profiles) > 0 {
+		t.Error("profiles should not be part of the resolved settings")
+	}
+}
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+)
+
+func TestComputeRepositorySettingsDiff(t *testing.T) {
+	t.Parallel()
+
+	current := &github.Repository{
+		Name:                     github.Ptr("repo"),
+		Owner:                    &github.User{Login: github.Ptr("org")},
+		DefaultBranch:            github.Ptr("main"),
+		Description:              github.Ptr("repo by org"),
+		MergeCommitTitle:         github.Ptr("MERGE_MESSAGE"),
+		WebCommitSignoffRequired: github.Ptr(false),
+		HasDownloads:             github.Ptr(true),
+		Topics:                   []string{"go"},
+	}
+
+	tests := []struct {
+		name    string
+		desired configtypes.RepositorySettingsConfig
+		exclude []string
+		want    *github.Repository
+	}{
+		{
+			name: "in sync with rendered description",
+			desired: configtypes.RepositorySettingsConfig{
+				Description:  github.Ptr("{{REPO_NAME}} by {{OWNER}}"),
+				HasDownloads: github.Ptr(true),
+				Topics:       &configtypes.TopicsConfig{Names: []string{"Go"}},
+			},
+		},
+		{
+			name: "changed fields",
+			desired: configtypes.RepositorySettingsConfig{
+				MergeCommitTitle:         github.Ptr("PR_TITLE"),
+				WebCommitSignoffRequired: github.Ptr(true),
+				Homepage:                 github.Ptr("https://{{OWNER}}.github.io/{{REPO_NAME}}"),
+				Topics:                   &configtypes.TopicsConfig{Names: []string{"cli"}},
+			},
+			want: &github.Repository{
+				MergeCommitTitle:         github.Ptr("PR_TITLE"),
+				WebCommitSignoffRequired: github.Ptr(true),
+				Homepage:                 github.Ptr("https://org.github.io/repo"),
+				Topics:                   []string{"cli", "go"},
+			},
+		},
+		{
+			name: "excluded fields",
+			desired: configtypes.RepositorySettingsConfig{
+				MergeCommitTitle: github.Ptr("PR_TITLE"),
+				Topics: &configtypes.TopicsConfig{
+					Names: []string{"cli"},
+					Mode:  configtypes.TopicsModeExact,
+				},
+			},
+			exclude: []string{"repository.merge_commit_title", "repository.topics"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := computeRepositorySettingsDiff(&tt.desired, current, tt.exclude)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("computeRepositorySettingsDiff() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestComputeTopicsDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		desired configtypes.TopicsConfig
+		current []string
+		want    []string
+	}{
+		{
+			name:    "add-only keeps existing topics",
+			desired: configtypes.TopicsConfig{Names: []string{"cli"}},
+			current: []string{"go"},
+			want:    []string{"cli", "go"},
+		},
+		{
+			name:    "add-only in sync",
+			desired: configtypes.TopicsConfig{Names: []string{"go"}},
+			current: []string{"go", "cli"},
+		},
+		{
+			name: "exact removes topics not listed",
+			desired: configtypes.TopicsConfig{
+				Names: []string{"go"},
+				Mode:  configtypes.TopicsModeExact,
+			},
+			current: []string{"go", "cli"},
+			want:    []string{"go"},
+		},
+		{
+			name: "exact with no topics clears them",
+			desired: configtypes.TopicsConfig{
+				Names: []string{},
+				Mode:  configtypes.TopicsModeExact,
+			},
+			current: []string{"go"},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := computeTopicsDiff(&tt.desired, tt.current)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("computeTopicsDiff() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyRepositoryChangesTopicsOnly(t *testing.T) {
+	t.Parallel()
+
+	fake, client := newFakeGitHub(t, map[string]string{
+		"PUT /repos/org/repo/topics": `{"names":["cli","go"]}`,
+	})
+
+	update := &github.Repository{Topics: []string{"cli", "go"}}
+
+	err := applyRepositoryChanges(context.Background(), client, "org", "repo", update)
+	if err != nil {
+		t.Fatalf("applyRepositoryChanges() error = %v", err)
+	}
+
+	if fake.called("PATCH /repos/org/repo") {
+		t.Error("expected repository not to be edited when only topics change")
+	}
+
+	body := fake.body("PUT /repos/org/repo/topics")
+	if diff := cmp.Diff(`{"names":["cli","go"]}`+"\n", body); diff != "" {
+		t.Errorf("topics request mismatch (-want +got):\n%s", diff)
+	}
+}
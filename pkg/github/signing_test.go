@@ -0,0 +1,473 @@
+package github
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHSigner(t *testing.T) {
+	t.Parallel()
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		key        any
+		passphrase []byte
+	}{
+		{name: "ed25519", key: ed25519Key},
+		{name: "ed25519 with passphrase", key: ed25519Key, passphrase: []byte("secret")},
+		{name: "rsa", key: rsaKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyPEM := marshalSSHKey(t, tt.key, tt.passphrase)
+
+			signer, err := NewCommitSigner("", keyPEM, tt.passphrase)
+			if err != nil {
+				t.Fatalf("NewCommitSigner() unexpected error: %v", err)
+			}
+
+			message := []byte("tree abc\nauthor A <a@example.com> 0 +0000\n\nchore(sync): test")
+
+			var signature bytes.Buffer
+			if err := signer.Sign(&signature, bytes.NewReader(message)); err != nil {
+				t.Fatalf("Sign() unexpected error: %v", err)
+			}
+
+			verifySSHSignature(t, signature.Bytes(), message)
+			verifySSHSignatureWithSSHKeygen(t, keyPEM, tt.passphrase, signature.Bytes(), message)
+		})
+	}
+}
+
+func TestSSHSignerInvalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted := marshalSSHKey(t, ed25519Key, []byte("secret"))
+
+	if _, err := NewSSHSigner(encrypted, []byte("wrong")); !errors.Is(err, ErrInvalidSigningKey) {
+		t.Errorf("NewSSHSigner() with wrong passphrase error = %v, want ErrInvalidSigningKey", err)
+	}
+
+	if _, err := NewSSHSigner([]byte("not a key"), nil); !errors.Is(err, ErrInvalidSigningKey) {
+		t.Errorf("NewSSHSigner() with garbage error = %v, want ErrInvalidSigningKey", err)
+	}
+
+	if _, err := NewCommitSigner("x509", encrypted, nil); !errors.Is(err, ErrUnsupportedSigningFormat) {
+		t.Errorf("NewCommitSigner() with unknown format error = %v, want ErrUnsupportedSigningFormat", err)
+	}
+}
+
+func TestOpenPGPSigner(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		passphrase []byte
+	}{
+		{name: "unencrypted key"},
+		{name: "encrypted key", passphrase: []byte("secret")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entity, err := openpgp.NewEntity("Sync Bot", "", "bot@example.com", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Keep an unencrypted copy of the public keyring for verification
+			var public bytes.Buffer
+			if err := entity.Serialize(&public); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.passphrase != nil {
+				if err := entity.EncryptPrivateKeys(tt.passphrase, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			keyArmored := armorOpenPGPKey(t, entity)
+
+			signer, err := NewCommitSigner("", keyArmored, tt.passphrase)
+			if err != nil {
+				t.Fatalf("NewCommitSigner() unexpected error: %v", err)
+			}
+
+			message := []byte("tree abc\n\nchore(sync): test")
+
+			var signature bytes.Buffer
+			if err := signer.Sign(&signature, bytes.NewReader(message)); err != nil {
+				t.Fatalf("Sign() unexpected error: %v", err)
+			}
+
+			keyring, err := openpgp.ReadKeyRing(&public)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := openpgp.CheckArmoredDetachedSignature(
+				keyring, bytes.NewReader(message), &signature, nil,
+			); err != nil {
+				t.Errorf("signature verification failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestAppendCoAuthors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		message   string
+		coAuthors []string
+		want      string
+	}{
+		{
+			name:    "no co-authors",
+			message: "chore(sync): sync organization files",
+			want:    "chore(sync): sync organization files",
+		},
+		{
+			name:      "single co-author",
+			message:   "chore(sync): sync organization files",
+			coAuthors: []string{"Jane <jane@example.com>"},
+			want:      "chore(sync): sync organization files\n\nCo-authored-by: Jane <jane@example.com>",
+		},
+		{
+			name:      "multiple co-authors after body",
+			message:   "chore(schema): regenerate\n\nBody.\n",
+			coAuthors: []string{"A <a@example.com>", "B <b@example.com>"},
+			want: "chore(schema): regenerate\n\nBody.\n\n" +
+				"Co-authored-by: A <a@example.com>\nCo-authored-by: B <b@example.com>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := appendCoAuthors(tt.message, tt.coAuthors); got != tt.want {
+				t.Errorf("appendCoAuthors() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCreateCommitSignature verifies that the signature sent to the API matches the
+// commit object GitHub builds from the submitted author, committer and message.
+func TestCreateCommitSignature(t *testing.T) {
+	t.Parallel()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewSSHSigner(marshalSSHKey(t, key, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received struct {
+		Message   string   `json:"message"`
+		Tree      string   `json:"tree"`
+		Parents   []string `json:"parents"`
+		Signature string   `json:"signature"`
+		Author    struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+		Committer struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"committer"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sha":"0123456789abcdef"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := newTestClient(t, server.URL)
+
+	opts := &CommitOptions{
+		Author: &github.CommitAuthor{
+			Name:  github.Ptr("Sync Bot"),
+			Email: github.Ptr("bot@example.com"),
+		},
+		CoAuthors: []string{"Jane <jane@example.com>"},
+		Signer:    signer,
+	}
+
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	commit := github.Commit{
+		Message: github.Ptr(filesCommitMessage),
+		Tree:    &github.Tree{SHA: github.Ptr("tree-sha")},
+		Parents: []*github.Commit{{SHA: github.Ptr("parent-sha")}},
+	}
+
+	if _, err := createCommit(t.Context(), client, "org", "repo", commit, opts); err != nil {
+		t.Fatalf("createCommit() unexpected error: %v", err)
+	}
+
+	if received.Committer.Name != "Sync Bot" || !received.Committer.Date.Equal(received.Author.Date) {
+		t.Errorf("committer = %+v, want author identity and date", received.Committer)
+	}
+
+	if !strings.HasSuffix(received.Message, "\n\nCo-authored-by: Jane <jane@example.com>") {
+		t.Errorf("message %q lacks Co-authored-by trailer", received.Message)
+	}
+
+	// Rebuild the commit object the way git does from the submitted fields
+	payload := fmt.Sprintf("tree %s\nparent %s\nauthor %s <%s> %d +0000\ncommitter %s <%s> %d +0000\n\n%s",
+		received.Tree,
+		received.Parents[0],
+		received.Author.Name, received.Author.Email, received.Author.Date.Unix(),
+		received.Committer.Name, received.Committer.Email, received.Committer.Date.Unix(),
+		received.Message,
+	)
+
+	verifySSHSignature(t, []byte(received.Signature), []byte(payload))
+}
+
+func TestCommitOptionsRequiresAuthorForSigning(t *testing.T) {
+	t.Parallel()
+
+	signer := github.MessageSignerFunc(func(_ io.Writer, _ io.Reader) error { return nil })
+
+	if err := (&CommitOptions{Signer: signer}).Validate(); !errors.Is(err, ErrCommitAuthorRequired) {
+		t.Errorf("Validate() error = %v, want ErrCommitAuthorRequired", err)
+	}
+
+	if err := (*CommitOptions)(nil).Validate(); err != nil {
+		t.Errorf("Validate() on nil options unexpected error: %v", err)
+	}
+}
+
+func newTestClient(t *testing.T, serverURL string) *Client {
+	t.Helper()
+
+	baseURL, err := url.Parse(serverURL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := github.NewClient(nil)
+	client.BaseURL = baseURL
+
+	return &Client{Client: client}
+}
+
+func marshalSSHKey(t *testing.T, key any, passphrase []byte) []byte {
+	t.Helper()
+
+	var (
+		block *pem.Block
+		err   error
+	)
+
+	if passphrase != nil {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "test", passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(key, "test")
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(block)
+}
+
+func armorOpenPGPKey(t *testing.T, entity *openpgp.Entity) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := entity.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// verifySSHSignature verifies an armored sshsig signature following the
+// PROTOCOL.sshsig specification, independently of the signing implementation.
+func verifySSHSignature(t *testing.T, armored []byte, message []byte) {
+	t.Helper()
+
+	block := strings.TrimSpace(string(armored))
+	block = strings.TrimPrefix(block, "-----BEGIN SSH SIGNATURE-----")
+	block = strings.TrimSuffix(block, "-----END SSH SIGNATURE-----")
+
+	blob, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(block, "\n", ""))
+	if err != nil {
+		t.Fatalf("decoding signature armor: %v", err)
+	}
+
+	if !bytes.HasPrefix(blob, []byte("SSHSIG")) {
+		t.Fatalf("signature blob lacks SSHSIG magic")
+	}
+
+	var sig struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Signature []byte
+	}
+
+	if err := ssh.Unmarshal(blob[len("SSHSIG"):], &sig); err != nil {
+		t.Fatalf("parsing signature blob: %v", err)
+	}
+
+	if sig.Version != 1 || sig.Namespace != "git" || sig.HashAlg != "sha512" {
+		t.Fatalf("unexpected signature header: version=%d namespace=%q hash=%q",
+			sig.Version, sig.Namespace, sig.HashAlg)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		t.Fatalf("parsing public key: %v", err)
+	}
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		t.Fatalf("parsing signature: %v", err)
+	}
+
+	hash := sha512.Sum512(message)
+
+	var signed bytes.Buffer
+
+	signed.WriteString("SSHSIG")
+	signed.Write(ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		HashAlg   string
+		Hash      string
+	}{"git", "", "sha512", string(hash[:])}))
+
+	if err := publicKey.Verify(signed.Bytes(), &signature); err != nil {
+		t.Errorf("signature verification failed: %v", err)
+	}
+}
+
+// verifySSHSignatureWithSSHKeygen cross-checks a signature with OpenSSH when available.
+func verifySSHSignatureWithSSHKeygen(
+	t *testing.T,
+	keyPEM []byte,
+	passphrase []byte,
+	signature []byte,
+	message []byte,
+) {
+	t.Helper()
+
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Log("ssh-keygen not available, skipping OpenSSH verification")
+
+		return
+	}
+
+	var (
+		signer ssh.Signer
+		err    error
+	)
+
+	if passphrase != nil {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(keyPEM, passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(keyPEM)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	sigFile := filepath.Join(dir, "message.sig")
+
+	if err := os.WriteFile(sigFile, signature, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	allowedSigners := filepath.Join(dir, "allowed_signers")
+	entry := "bot@example.com " + string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+
+	if err := os.WriteFile(allowedSigners, []byte(entry), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("ssh-keygen", "-Y", "verify",
+		"-f", allowedSigners, "-I", "bot@example.com", "-n", "git", "-s", sigFile)
+	cmd.Stdin = bytes.NewReader(message)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("ssh-keygen verification failed: %v\n%s", err, output)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/errors"
-	"github.com/google/go-github/v80/github"
 	"go.yaml.in/yaml/v4"
 
 	"github.com/smykla-labs/.github/internal/configtypes"
@@ -17,8 +16,9 @@ import (
 )
 
 const (
-	smyklotBranchPrefix = "chore/sync-smyklot"
-	smyklotPRLabel      = "ci/skip-all"
+	smyklotBranchPrefix  = "chore/sync-smyklot"
+	smyklotPRLabel       = "ci/skip-all"
+	smyklotCommitMessage = syncCommitPrefix + " sync smyklot workflows"
 
 	// Workflow template names (current)
 	WorkflowPrCommands = "smyklot-pr-commands"
@@ -64,9 +64,12 @@ func SyncSmyklot(
 	syncConfig *configtypes.SyncConfig,
 	templatesDir string,
 	smyklotFilePath string,
+	mergeMethod string,
+	commitOpts *CommitOptions,
 	dryRun bool,
 ) (*SmyklotSyncResult, error) {
 	result := NewSmyklotSyncResult(repo, dryRun)
+	prManager := NewPRManager(log, client, org, repo)
 
 	// Check if sync is skipped
 	if syncConfig.Sync.Skip || syncConfig.Sync.Smyklot.Skip {
@@ -75,14 +78,6 @@ func SyncSmyklot(
 		return result, err
 	}
 
-	// Fetch org-level smyklot config
-	orgConfig, err := fetchSmyklotOrgConfig(ctx, client, org, smyklotFilePath)
-	if err != nil {
-		result.CompleteWithError(errors.Wrap(err, "fetching org smyklot config"))
-
-		return result, err
-	}
-
 	// Get repository info
 	defaultBranch, baseSHA, err := getRepoBaseInfo(ctx, log, client, org, repo)
 	if err != nil {
@@ -91,51 +86,15 @@ func SyncSmyklot(
 		return result, err
 	}
 
-	// List existing workflow files
-	workflowFiles, err := listWorkflowFiles(ctx, log, client, org, repo)
-	if err != nil {
-		result.CompleteWithError(errors.Wrap(err, "listing workflow files"))
-
-		return result, err
-	}
-
-	// Build map of existing workflows (name without extension -> full path)
-	existingWorkflows := buildExistingWorkflowsMap(workflowFiles)
-
-	// Process workflow templates
-	stats := &SmyklotSyncStats{}
-
-	changes, err := syncManagedWorkflows(
-		ctx, log, client, org, repo, tag, sha,
-		orgConfig, syncConfig, templatesDir, existingWorkflows, stats,
+	changes, stats, err := planSmyklotChanges(
+		ctx, log, client, org, repo, version, tag, sha, syncConfig, templatesDir, smyklotFilePath,
 	)
 	if err != nil {
-		result.CompleteWithError(errors.Wrap(err, "syncing managed workflows"))
+		result.CompleteWithError(err)
 
 		return result, err
 	}
 
-	// Version-only sync for other workflows if enabled
-	versionChanges, err := syncVersionOnlyWorkflows(
-		ctx, log, client, org, repo, version, tag,
-		orgConfig, syncConfig, workflowFiles, stats,
-	)
-	if err != nil {
-		result.CompleteWithError(errors.Wrap(err, "syncing version-only workflows"))
-
-		return result, err
-	}
-
-	changes = append(changes, versionChanges...)
-
-	// Log stats
-	log.Info("smyklot sync summary",
-		"installed", stats.Installed,
-		"replaced", stats.Replaced,
-		"version_only", stats.VersionOnly,
-		"skipped", stats.Skipped,
-	)
-
 	// Populate result from stats
 	result.InstalledFiles = stats.InstalledFiles
 	result.ReplacedFiles = stats.ReplacedFiles
@@ -145,7 +104,7 @@ func SyncSmyklot(
 	if len(changes) == 0 {
 		log.Info("no changes needed")
 
-		if closeErr := closeSmyklotPR(ctx, log, client, org, repo,
+		if _, closeErr := prManager.Close(ctx, smyklotBranchPrefix, PRCloseReasonInSync,
 			"smyklot is already up to date. Closing this PR."); closeErr != nil {
 			log.Warn("failed to close existing PR", "error", closeErr)
 		}
@@ -164,26 +123,27 @@ func SyncSmyklot(
 	}
 
 	// Create/update PR
-	prNumber, prURL, err := createSmyklotPRWithResult(
-		ctx,
-		log,
-		client,
-		org,
-		repo,
-		defaultBranch,
-		baseSHA,
-		tag,
-		changes,
-		stats,
-	)
+	spec := &pullRequestSpec{
+		Branch:      smyklotBranchPrefix,
+		Base:        defaultBranch,
+		Title:       buildSmyklotPRTitle(tag, stats),
+		Body:        buildSmyklotPRBody(tag, stats),
+		Labels:      []string{smyklotPRLabel},
+		MergeMethod: mergeMethod,
+	}
+
+	groups := []commitGroup{{Message: smyklotCommitMessage, Changes: changes}}
+
+	pr, err := prManager.Publish(ctx, baseSHA, spec, groups, commitOpts)
 	if err != nil {
 		result.CompleteWithError(errors.Wrap(err, "creating or updating PR"))
 
 		return result, err
 	}
 
-	result.PRNumber = prNumber
-	result.PRURL = prURL
+	result.PRNumber = pr.Number
+	result.PRURL = pr.URL
+	result.AutoMerge = pr.AutoMerge
 
 	log.Info("smyklot sync completed successfully")
 	result.Complete(StatusSuccess)
@@ -191,6 +151,69 @@ func SyncSmyklot(
 	return result, nil
 }
 
+// planSmyklotChanges computes the workflow changes needed to bring a repository's
+// smyklot workflows and version references up to date.
+func planSmyklotChanges(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	version string,
+	tag string,
+	sha string,
+	syncConfig *configtypes.SyncConfig,
+	templatesDir string,
+	smyklotFilePath string,
+) ([]FileChange, *SmyklotSyncStats, error) {
+	// Fetch org-level smyklot config
+	orgConfig, err := fetchSmyklotOrgConfig(ctx, client, org, smyklotFilePath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "fetching org smyklot config")
+	}
+
+	// List existing workflow files
+	workflowFiles, err := listWorkflowFiles(ctx, log, client, org, repo)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "listing workflow files")
+	}
+
+	// Build map of existing workflows (name without extension -> full path)
+	existingWorkflows := buildExistingWorkflowsMap(workflowFiles)
+
+	// Process workflow templates
+	stats := &SmyklotSyncStats{}
+
+	changes, err := syncManagedWorkflows(
+		ctx, log, client, org, repo, tag, sha,
+		orgConfig, syncConfig, templatesDir, existingWorkflows, stats,
+	)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "syncing managed workflows")
+	}
+
+	// Version-only sync for other workflows if enabled
+	versionChanges, err := syncVersionOnlyWorkflows(
+		ctx, log, client, org, repo, version, tag,
+		orgConfig, syncConfig, workflowFiles, stats,
+	)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "syncing version-only workflows")
+	}
+
+	changes = append(changes, versionChanges...)
+
+	// Log stats
+	log.Info("smyklot sync summary",
+		"installed", stats.Installed,
+		"replaced", stats.Replaced,
+		"version_only", stats.VersionOnly,
+		"skipped", stats.Skipped,
+	)
+
+	return changes, stats, nil
+}
+
 // getSkipReason returns the reason for skipping smyklot sync.
 func getSkipReason(syncConfig *configtypes.SyncConfig) string {
 	if syncConfig.Sync.Skip {
@@ -215,7 +238,10 @@ func handleSkippedSyncWithResult(
 	log.Info("smyklot sync skipped by config")
 
 	skipReason := getSkipReason(syncConfig)
-	if err := closeSmyklotPR(ctx, log, client, org, repo, skipReason); err != nil {
+	prManager := NewPRManager(log, client, org, repo)
+
+	_, err := prManager.Close(ctx, smyklotBranchPrefix, PRCloseReasonDisabled, skipReason)
+	if err != nil {
 		log.Warn("failed to close existing PR", "error", err)
 	}
 
@@ -648,189 +674,6 @@ func applyVersionReplacements(content string, version string, tag string) (strin
 	return content, content != original
 }
 
-// closeSmyklotPR closes an existing smyklot PR if it exists.
-func closeSmyklotPR(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	comment string,
-) error {
-	branchName := smyklotBranchPrefix
-
-	// List open PRs for the branch
-	prs, _, err := client.PullRequests.List(ctx, org, repo, &github.PullRequestListOptions{
-		State: "open",
-		Head:  org + ":" + branchName,
-	})
-	if err != nil {
-		return errors.Wrap(err, "listing PRs")
-	}
-
-	if len(prs) == 0 {
-		return nil
-	}
-
-	prNumber := prs[0].GetNumber()
-	log.Info("closing existing PR", "pr", prNumber)
-
-	// Close the PR
-	pr := &github.PullRequest{
-		State: github.Ptr("closed"),
-	}
-
-	_, _, err = client.PullRequests.Edit(ctx, org, repo, prNumber, pr)
-	if err != nil {
-		return errors.Wrap(err, "closing PR")
-	}
-
-	// Add comment
-	prComment := &github.IssueComment{
-		Body: github.Ptr(comment),
-	}
-
-	_, _, err = client.Issues.CreateComment(ctx, org, repo, prNumber, prComment)
-	if err != nil {
-		log.Warn("failed to add PR comment", "error", err)
-	}
-
-	return nil
-}
-
-// createSmyklotPRWithResult creates or updates a PR and returns PR number and URL.
-func createSmyklotPRWithResult(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	defaultBranch string,
-	baseSHA string,
-	tag string,
-	changes []FileChange,
-	stats *SmyklotSyncStats,
-) (int, string, error) {
-	branchName := smyklotBranchPrefix
-	log.Info("creating/updating PR", "branch", branchName)
-
-	// Ensure branch exists
-	if err := ensureBranchExists(ctx, log, client, org, repo, branchName, baseSHA); err != nil {
-		return 0, "", errors.Wrap(err, "ensuring branch exists")
-	}
-
-	// Create Git commit
-	if err := createGitCommit(ctx, log, client, org, repo, branchName, baseSHA, changes); err != nil {
-		return 0, "", errors.Wrap(err, "creating Git commit")
-	}
-
-	// Create or update pull request
-	prNumber, prURL, err := upsertSmyklotPullRequestWithURL(
-		ctx, log, client, org, repo, defaultBranch, branchName, tag, stats,
-	)
-	if err != nil {
-		return 0, "", errors.Wrap(err, "upserting pull request")
-	}
-
-	// Add labels and enable auto-merge
-	if err := finalizeSmyklotPR(ctx, log, client, org, repo, prNumber); err != nil {
-		return prNumber, prURL, err
-	}
-
-	return prNumber, prURL, nil
-}
-
-// upsertSmyklotPullRequestWithURL creates or updates a PR and returns number and URL.
-func upsertSmyklotPullRequestWithURL(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	defaultBranch string,
-	branchName string,
-	tag string,
-	stats *SmyklotSyncStats,
-) (int, string, error) {
-	prTitle := buildSmyklotPRTitle(tag, stats)
-	prBody := buildSmyklotPRBody(tag, stats)
-
-	// Check for existing PR
-	prs, _, err := client.PullRequests.List(ctx, org, repo, &github.PullRequestListOptions{
-		State: "open",
-		Head:  org + ":" + branchName,
-	})
-	if err != nil {
-		return 0, "", errors.Wrap(err, "listing PRs")
-	}
-
-	if len(prs) > 0 {
-		// Update existing PR
-		prNumber := prs[0].GetNumber()
-		prURL := prs[0].GetHTMLURL()
-
-		log.Info("updating existing PR", "pr", prNumber)
-
-		pr := &github.PullRequest{
-			Title: github.Ptr(prTitle),
-			Body:  github.Ptr(prBody),
-		}
-
-		_, _, editErr := client.PullRequests.Edit(ctx, org, repo, prNumber, pr)
-		if editErr != nil {
-			return 0, "", errors.Wrap(editErr, "updating PR")
-		}
-
-		return prNumber, prURL, nil
-	}
-
-	// Create new PR
-	log.Info("creating new PR")
-
-	pr := &github.NewPullRequest{
-		Title: github.Ptr(prTitle),
-		Head:  github.Ptr(branchName),
-		Base:  github.Ptr(defaultBranch),
-		Body:  github.Ptr(prBody),
-	}
-
-	createdPR, _, err := client.PullRequests.Create(ctx, org, repo, pr)
-	if err != nil {
-		return 0, "", errors.Wrap(err, "creating PR")
-	}
-
-	prNumber := createdPR.GetNumber()
-	prURL := createdPR.GetHTMLURL()
-	log.Info("created PR", "pr", prNumber, "url", prURL)
-
-	return prNumber, prURL, nil
-}
-
-// finalizeSmyklotPR adds labels and enables auto-merge for a smyklot PR.
-//
-//nolint:unparam // Error return kept for consistency with similar functions
-func finalizeSmyklotPR(
-	ctx context.Context,
-	log *logger.Logger,
-	client *Client,
-	org string,
-	repo string,
-	prNumber int,
-) error {
-	// Add label
-	_, _, err := client.Issues.AddLabelsToIssue(ctx, org, repo, prNumber, []string{smyklotPRLabel})
-	if err != nil {
-		log.Warn("failed to add label to PR", "error", err)
-	}
-
-	// Enable auto-merge
-	if err := enableAutoMerge(ctx, log, client, org, repo, prNumber); err != nil {
-		log.Warn("failed to enable auto-merge", "error", err)
-	}
-
-	return nil
-}
-
 // buildSmyklotPRTitle builds the PR title based on what changes are included.
 func buildSmyklotPRTitle(tag string, stats *SmyklotSyncStats) string {
 	hasWorkflowChanges := stats.Installed > 0 || stats.Replaced > 0
@@ -859,25 +702,43 @@ func buildSmyklotPRTitle(tag string, stats *SmyklotSyncStats) string {
 func buildSmyklotPRBody(tag string, stats *SmyklotSyncStats) string {
 	var body strings.Builder
 
+	writeSmyklotPRSections(&body, tag, stats, "##")
+
+	body.WriteString("\n---\n\n")
+	body.WriteString("*This PR was automatically created by the smyklot sync workflow*\n")
+
+	return body.String()
+}
+
+// writeSmyklotPRSections writes the smyklot sections of a PR body using the given
+// markdown heading level.
+func writeSmyklotPRSections(
+	body *strings.Builder,
+	tag string,
+	stats *SmyklotSyncStats,
+	heading string,
+) {
 	hasWorkflowChanges := stats.Installed > 0 || stats.Replaced > 0
 	hasVersionChanges := stats.VersionOnly > 0
 
 	switch {
 	case hasVersionChanges:
 		// Version changes present - mention the update
-		body.WriteString(fmt.Sprintf(
+		fmt.Fprintf(
+			body,
 			"Updates [`smykla-labs/smyklot`](https://github.com/smykla-labs/smyklot) "+
 				"to version [`%s`](https://github.com/smykla-labs/smyklot/releases/tag/%s).\n",
 			tag, tag,
-		))
+		)
 
 	case hasWorkflowChanges:
 		// Workflow-only changes
-		body.WriteString(fmt.Sprintf(
+		fmt.Fprintf(
+			body,
 			"Syncs smyklot workflow files from "+
 				"[`smykla-labs/smyklot@%s`](https://github.com/smykla-labs/smyklot/releases/tag/%s).\n",
 			tag, tag,
-		))
+		)
 
 	default:
 		body.WriteString("Syncs smyklot configuration.\n")
@@ -885,35 +746,30 @@ func buildSmyklotPRBody(tag string, stats *SmyklotSyncStats) string {
 
 	// Workflows installed section
 	if len(stats.InstalledFiles) > 0 {
-		body.WriteString("\n## Workflows Installed\n\n")
+		fmt.Fprintf(body, "\n%s Workflows Installed\n\n", heading)
 
 		for _, file := range stats.InstalledFiles {
-			body.WriteString(fmt.Sprintf("- `%s`\n", file))
+			fmt.Fprintf(body, "- `%s`\n", file)
 		}
 	}
 
 	// Workflows replaced section
 	if len(stats.ReplacedFiles) > 0 {
-		body.WriteString("\n## Workflows Replaced\n\n")
+		fmt.Fprintf(body, "\n%s Workflows Replaced\n\n", heading)
 
 		for _, file := range stats.ReplacedFiles {
-			body.WriteString(fmt.Sprintf("- `%s`\n", file))
+			fmt.Fprintf(body, "- `%s`\n", file)
 		}
 	}
 
 	// Version-only updates section
 	if len(stats.VersionOnlyFiles) > 0 {
-		body.WriteString("\n## Version Updates\n\n")
+		fmt.Fprintf(body, "\n%s Version Updates\n\n", heading)
 
 		for _, file := range stats.VersionOnlyFiles {
-			body.WriteString(fmt.Sprintf("- `%s`\n", file))
+			fmt.Fprintf(body, "- `%s`\n", file)
 		}
 	}
-
-	body.WriteString("\n---\n\n")
-	body.WriteString("*This PR was automatically created by the smyklot sync workflow*\n")
-
-	return body.String()
 }
 
 // logSmyklotChanges logs the planned smyklot changes in dry-run mode.
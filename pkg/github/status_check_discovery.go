@@ -0,0 +1,566 @@
+package github
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"go.yaml.in/yaml/v4"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// ErrUndiscoverableJob is returned when the check names of a workflow job depend on
+// values only known when the workflow runs.
+var ErrUndiscoverableJob = errors.New("job check names cannot be discovered")
+
+// matrixExpression matches a matrix value reference in a job name.
+var matrixExpression = regexp.MustCompile(`\$\{\{\s*matrix\.([A-Za-z0-9_-]+)\s*\}\}`)
+
+// pullRequestEvent is the workflow trigger of checks required on pull requests.
+const pullRequestEvent = "pull_request"
+
+// matrixValue is a single key and value of a matrix combination.
+type matrixValue struct {
+	Key   string
+	Value string
+}
+
+// pullRequestTrigger is the pull_request trigger of a workflow.
+type pullRequestTrigger struct {
+	Branches       []string `yaml:"branches"`
+	BranchesIgnore []string `yaml:"branches-ignore"`
+	Paths          []string `yaml:"paths"`
+	PathsIgnore    []string `yaml:"paths-ignore"`
+	Types          []string `yaml:"types"`
+}
+
+// workflowJob is the part of a workflow job that determines its check names.
+type workflowJob struct {
+	Name     string `yaml:"name"`
+	Uses     string `yaml:"uses"`
+	Strategy struct {
+		Matrix yaml.Node `yaml:"matrix"`
+	} `yaml:"strategy"`
+}
+
+// resolveDiscoveredStatusChecks adds the discovered checks of the repository's
+// workflows to branch protection rules and rulesets with discovery enabled. The
+// workflows are read at most once, and only when a rule asks for discovery.
+func resolveDiscoveredStatusChecks(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	settings *SettingsDefinition,
+	defaultBranch string,
+) error {
+	var (
+		discovered []string
+		loaded     bool
+	)
+
+	discover := func() ([]string, error) {
+		if loaded {
+			return discovered, nil
+		}
+
+		checks, err := discoverStatusChecks(ctx, log, client, org, repo, defaultBranch)
+		if err != nil {
+			return nil, errors.Wrap(err, "discovering status checks")
+		}
+
+		if len(checks) == 0 {
+			log.Warn("no status checks discovered in workflows", "branch", defaultBranch)
+		}
+
+		discovered, loaded = checks, true
+
+		return discovered, nil
+	}
+
+	settings.BranchProtection = slices.Clone(settings.BranchProtection)
+
+	for i := range settings.BranchProtection {
+		checks := settings.BranchProtection[i].RequiredStatusChecks
+		if checks == nil || !getBoolValue(checks.Discover) {
+			continue
+		}
+
+		names, err := discover()
+		if err != nil {
+			return err
+		}
+
+		resolved := *checks
+		resolved.Contexts = slices.Clone(checks.Contexts)
+
+		for _, name := range filterCheckNames(names, checks.Include, checks.Exclude) {
+			if !slices.Contains(resolved.Contexts, name) {
+				resolved.Contexts = append(resolved.Contexts, name)
+			}
+		}
+
+		settings.BranchProtection[i].RequiredStatusChecks = &resolved
+	}
+
+	settings.Rulesets = slices.Clone(settings.Rulesets)
+
+	for i := range settings.Rulesets {
+		rules := settings.Rulesets[i].Rules
+		if rules == nil || rules.RequiredStatusChecks == nil ||
+			!getBoolValue(rules.RequiredStatusChecks.Discover) {
+			continue
+		}
+
+		names, err := discover()
+		if err != nil {
+			return err
+		}
+
+		checks := *rules.RequiredStatusChecks
+		checks.RequiredStatusChecks = slices.Clone(checks.RequiredStatusChecks)
+
+		for _, name := range filterCheckNames(names, checks.Include, checks.Exclude) {
+			listed := slices.ContainsFunc(checks.RequiredStatusChecks,
+				func(check configtypes.StatusCheckConfig) bool { return check.Context == name })
+			if !listed {
+				checks.RequiredStatusChecks = append(checks.RequiredStatusChecks,
+					configtypes.StatusCheckConfig{Context: name})
+			}
+		}
+
+		resolved := *rules
+		resolved.RequiredStatusChecks = &checks
+		settings.Rulesets[i].Rules = &resolved
+	}
+
+	return nil
+}
+
+// filterCheckNames returns the names matching any include pattern, or all names
+// without include patterns, that match no exclude pattern.
+func filterCheckNames(names []string, include []string, exclude []string) []string {
+	matchesAny := func(patterns []string, name string) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			return matchSegment(pattern, name)
+		})
+	}
+
+	var filtered []string
+
+	for _, name := range names {
+		if len(include) > 0 && !matchesAny(include, name) {
+			continue
+		}
+
+		if !matchesAny(exclude, name) {
+			filtered = append(filtered, name)
+		}
+	}
+
+	return filtered
+}
+
+// discoverStatusChecks returns the sorted check names of the workflow jobs of a
+// repository that run on every pull request to the branch. Workflows with path
+// filters are skipped, as their checks would block pull requests they do not run on,
+// and so are jobs calling reusable workflows and jobs whose names are only known at
+// run time.
+func discoverStatusChecks(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	branch string,
+) ([]string, error) {
+	paths, err := listWorkflowFiles(ctx, log, client, org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []string
+
+	for _, path := range paths {
+		fileContent, _, _, err := client.Repositories.GetContents(ctx, org, repo, path, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching workflow file %s", path)
+		}
+
+		content, err := fileContent.GetContent()
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding workflow file %s", path)
+		}
+
+		names, err := workflowCheckNames(log, path, []byte(content), branch)
+		if err != nil {
+			log.Warn("skipping workflow that cannot be parsed", "path", path, "error", err)
+
+			continue
+		}
+
+		checks = append(checks, names...)
+	}
+
+	slices.Sort(checks)
+
+	return slices.Compact(checks), nil
+}
+
+// workflowCheckNames returns the check names of the jobs of a workflow, or nothing
+// when the workflow does not run on every pull request to the branch.
+func workflowCheckNames(
+	log *logger.Logger,
+	path string,
+	content []byte,
+	branch string,
+) ([]string, error) {
+	var workflow struct {
+		On   yaml.Node `yaml:"on"`
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, errors.Wrap(err, "parsing workflow")
+	}
+
+	runs, err := runsOnPullRequests(&workflow.On, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if !runs {
+		log.Debug("workflow does not run on every pull request", "path", path, "branch", branch)
+
+		return nil, nil
+	}
+
+	var names []string
+
+	for i := 0; i+1 < len(workflow.Jobs.Content); i += 2 {
+		id := workflow.Jobs.Content[i].Value
+
+		var job workflowJob
+		if err := workflow.Jobs.Content[i+1].Decode(&job); err != nil {
+			return nil, errors.Wrapf(err, "parsing job %s", id)
+		}
+
+		if job.Uses != "" {
+			log.Debug("skipping job calling a reusable workflow", "path", path, "job", id)
+
+			continue
+		}
+
+		jobNames, err := jobCheckNames(id, job.Name, &job.Strategy.Matrix)
+		if err != nil {
+			log.Warn("skipping job", "path", path, "job", id, "error", err)
+
+			continue
+		}
+
+		names = append(names, jobNames...)
+	}
+
+	return names, nil
+}
+
+// runsOnPullRequests reports whether a workflow trigger runs on every pull request to
+// the branch: the pull_request event without path filters, with branch filters
+// admitting the branch, and with activity types covering opened and synchronized PRs.
+func runsOnPullRequests(on *yaml.Node, branch string) (bool, error) {
+	switch on.Kind {
+	case yaml.ScalarNode:
+		return on.Value == pullRequestEvent, nil
+	case yaml.SequenceNode:
+		return slices.ContainsFunc(on.Content, func(event *yaml.Node) bool {
+			return event.Value == pullRequestEvent
+		}), nil
+	case yaml.MappingNode:
+	default:
+		return false, nil
+	}
+
+	for i := 0; i+1 < len(on.Content); i += 2 {
+		if on.Content[i].Value != pullRequestEvent {
+			continue
+		}
+
+		var trigger pullRequestTrigger
+		if err := on.Content[i+1].Decode(&trigger); err != nil {
+			return false, errors.Wrap(err, "parsing pull_request trigger")
+		}
+
+		if len(trigger.Paths) > 0 || len(trigger.PathsIgnore) > 0 {
+			return false, nil
+		}
+
+		// Without types, pull_request runs on opened, synchronize and reopened
+		if len(trigger.Types) > 0 && (!slices.Contains(trigger.Types, "opened") ||
+			!slices.Contains(trigger.Types, "synchronize")) {
+			return false, nil
+		}
+
+		if slices.ContainsFunc(trigger.BranchesIgnore, func(pattern string) bool {
+			return matchBranchPattern(branch, pattern)
+		}) {
+			return false, nil
+		}
+
+		return len(trigger.Branches) == 0 || matchBranchFilters(branch, trigger.Branches), nil
+	}
+
+	return false, nil
+}
+
+// matchBranchFilters applies workflow branch filters in order, where patterns
+// starting with "!" exclude branches matched by earlier patterns.
+func matchBranchFilters(branch string, patterns []string) bool {
+	matched := false
+
+	for _, pattern := range patterns {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			matched = matched && !matchBranchPattern(branch, negated)
+		} else {
+			matched = matched || matchBranchPattern(branch, pattern)
+		}
+	}
+
+	return matched
+}
+
+// jobCheckNames returns the check names of a job, one per matrix combination. Like
+// GitHub, the matrix values are appended to names not referring to them.
+func jobCheckNames(id string, name string, matrix *yaml.Node) ([]string, error) {
+	if name == "" {
+		name = id
+	}
+
+	combinations, err := expandMatrix(matrix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(combinations) == 0 {
+		if strings.Contains(name, "${{") {
+			return nil, errors.Wrapf(ErrUndiscoverableJob, "name %q uses an expression", name)
+		}
+
+		return []string{name}, nil
+	}
+
+	names := make([]string, 0, len(combinations))
+
+	for _, combination := range combinations {
+		if !strings.Contains(name, "${{") {
+			values := make([]string, 0, len(combination))
+			for _, value := range combination {
+				values = append(values, value.Value)
+			}
+
+			names = append(names, name+" ("+strings.Join(values, ", ")+")")
+
+			continue
+		}
+
+		resolved := matrixExpression.ReplaceAllStringFunc(name, func(expression string) string {
+			key := matrixExpression.FindStringSubmatch(expression)[1]
+
+			for _, value := range combination {
+				if value.Key == key {
+					return value.Value
+				}
+			}
+
+			return expression
+		})
+
+		if strings.Contains(resolved, "${{") {
+			return nil, errors.Wrapf(ErrUndiscoverableJob, "name %q uses an expression", name)
+		}
+
+		names = append(names, resolved)
+	}
+
+	return names, nil
+}
+
+// expandMatrix returns the combinations of a job matrix in the order GitHub runs
+// them: the product of the matrix values without excluded combinations, extended or
+// followed by the included ones.
+func expandMatrix(matrix *yaml.Node) ([][]matrixValue, error) {
+	if matrix.Kind == 0 {
+		return nil, nil
+	}
+
+	if matrix.Kind != yaml.MappingNode {
+		return nil, errors.Wrap(ErrUndiscoverableJob, "matrix is computed at run time")
+	}
+
+	var (
+		combinations     [][]matrixValue
+		include, exclude [][]matrixValue
+		keys             []string
+		err              error
+	)
+
+	for i := 0; i+1 < len(matrix.Content); i += 2 {
+		key, value := matrix.Content[i].Value, matrix.Content[i+1]
+
+		switch key {
+		case "include":
+			include, err = matrixEntries(key, value)
+		case "exclude":
+			exclude, err = matrixEntries(key, value)
+		default:
+			combinations, err = multiplyMatrix(combinations, len(keys) == 0, key, value)
+			keys = append(keys, key)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	combinations = slices.DeleteFunc(combinations, func(combination []matrixValue) bool {
+		return slices.ContainsFunc(exclude, func(entry []matrixValue) bool {
+			return matchesMatrixEntry(combination, entry, nil)
+		})
+	})
+
+	original := len(combinations)
+
+	for _, entry := range include {
+		added := false
+
+		for i := range original {
+			if !matchesMatrixEntry(combinations[i], entry, keys) {
+				continue
+			}
+
+			combinations[i] = extendCombination(combinations[i], entry)
+			added = true
+		}
+
+		if !added {
+			combinations = append(combinations, slices.Clone(entry))
+		}
+	}
+
+	return combinations, nil
+}
+
+// multiplyMatrix returns every combination extended with each value of a matrix key.
+func multiplyMatrix(
+	combinations [][]matrixValue,
+	first bool,
+	key string,
+	values *yaml.Node,
+) ([][]matrixValue, error) {
+	if values.Kind != yaml.SequenceNode {
+		return nil, runtimeMatrixError(key)
+	}
+
+	if first {
+		combinations = [][]matrixValue{nil}
+	}
+
+	product := make([][]matrixValue, 0, len(combinations)*len(values.Content))
+
+	for _, combination := range combinations {
+		for _, value := range values.Content {
+			if value.Kind != yaml.ScalarNode {
+				return nil, nonScalarMatrixError(key)
+			}
+
+			product = append(product,
+				append(slices.Clone(combination), matrixValue{Key: key, Value: value.Value}))
+		}
+	}
+
+	return product, nil
+}
+
+// matrixEntries returns the entries of a matrix include or exclude list.
+func matrixEntries(key string, list *yaml.Node) ([][]matrixValue, error) {
+	if list.Kind != yaml.SequenceNode {
+		return nil, runtimeMatrixError(key)
+	}
+
+	entries := make([][]matrixValue, 0, len(list.Content))
+
+	for _, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, runtimeMatrixError(key)
+		}
+
+		var entry []matrixValue
+
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i+1].Kind != yaml.ScalarNode {
+				return nil, nonScalarMatrixError(key)
+			}
+
+			entry = append(entry, matrixValue{
+				Key:   item.Content[i].Value,
+				Value: item.Content[i+1].Value,
+			})
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// matchesMatrixEntry reports whether a combination has the values of an entry for the
+// given keys, or for all keys of the entry when keys is nil.
+func matchesMatrixEntry(combination []matrixValue, entry []matrixValue, keys []string) bool {
+	for _, value := range entry {
+		if keys != nil && !slices.Contains(keys, value.Key) {
+			continue
+		}
+
+		index := slices.IndexFunc(combination, func(current matrixValue) bool {
+			return current.Key == value.Key
+		})
+		if index < 0 || combination[index].Value != value.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// extendCombination returns the combination with the values of an included entry,
+// overwriting values added by earlier entries.
+func extendCombination(combination []matrixValue, entry []matrixValue) []matrixValue {
+	extended := slices.Clone(combination)
+
+	for _, value := range entry {
+		index := slices.IndexFunc(extended, func(current matrixValue) bool {
+			return current.Key == value.Key
+		})
+		if index < 0 {
+			extended = append(extended, value)
+		} else {
+			extended[index] = value
+		}
+	}
+
+	return extended
+}
+
+// runtimeMatrixError reports a matrix key whose values are only known at run time.
+func runtimeMatrixError(key string) error {
+	return errors.Wrapf(ErrUndiscoverableJob, "matrix %s is computed at run time", key)
+}
+
+// nonScalarMatrixError reports a matrix key with values that are not plain scalars.
+func nonScalarMatrixError(key string) error {
+	return errors.Wrapf(ErrUndiscoverableJob, "matrix %s has non-scalar values", key)
+}
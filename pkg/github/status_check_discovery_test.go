@@ -0,0 +1,332 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestWorkflowCheckNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		workflow string
+		want     []string
+	}{
+		{
+			name: "job ids and names",
+			workflow: `
+on: pull_request
+jobs:
+  lint: {}
+  test:
+    name: Unit tests
+`,
+			want: []string{"lint", "Unit tests"},
+		},
+		{
+			name: "event list",
+			workflow: `
+on: [push, pull_request]
+jobs:
+  build: {}
+`,
+			want: []string{"build"},
+		},
+		{
+			name: "push only",
+			workflow: `
+on:
+  push:
+    branches: [main]
+jobs:
+  build: {}
+`,
+		},
+		{
+			name: "branch filters admitting the default branch",
+			workflow: `
+on:
+  pull_request:
+    branches: ["**", "!release/*"]
+jobs:
+  build: {}
+`,
+			want: []string{"build"},
+		},
+		{
+			name: "branch filters excluding the default branch",
+			workflow: `
+on:
+  pull_request:
+    branches-ignore: [main]
+jobs:
+  build: {}
+`,
+		},
+		{
+			name: "path filters",
+			workflow: `
+on:
+  pull_request:
+    paths: ["docs/**"]
+jobs:
+  docs: {}
+`,
+		},
+		{
+			name: "activity types covering every pull request",
+			workflow: `
+on:
+  pull_request:
+    types: [opened, synchronize, reopened, labeled]
+jobs:
+  build: {}
+`,
+			want: []string{"build"},
+		},
+		{
+			name: "activity types without synchronize",
+			workflow: `
+on:
+  pull_request:
+    types: [opened, reopened]
+jobs:
+  build: {}
+`,
+		},
+		{
+			name: "activity types limited to labels",
+			workflow: `
+on:
+  pull_request:
+    types: [labeled]
+jobs:
+  triage: {}
+`,
+		},
+		{
+			name: "matrix values appended to names",
+			workflow: `
+on:
+  pull_request:
+jobs:
+  test:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, macos-latest]
+        go: ["1.24", "1.25"]
+        exclude:
+          - os: macos-latest
+            go: "1.24"
+`,
+			want: []string{
+				"test (ubuntu-latest, 1.24)",
+				"test (ubuntu-latest, 1.25)",
+				"test (macos-latest, 1.25)",
+			},
+		},
+		{
+			name: "matrix expressions in names",
+			workflow: `
+on: pull_request
+jobs:
+  test:
+    name: Test on ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [linux, windows]
+        include:
+          - os: linux
+            race: true
+          - os: freebsd
+`,
+			want: []string{"Test on linux", "Test on windows", "Test on freebsd"},
+		},
+		{
+			name: "include extending combinations",
+			workflow: `
+on: pull_request
+jobs:
+  test:
+    strategy:
+      matrix:
+        go: ["1.25"]
+        include:
+          - experimental: true
+`,
+			want: []string{"test (1.25, true)"},
+		},
+		{
+			name: "undiscoverable jobs are skipped",
+			workflow: `
+on: pull_request
+jobs:
+  computed:
+    strategy:
+      matrix: ${{ fromJSON(needs.setup.outputs.matrix) }}
+  event:
+    name: Build ${{ github.event_name }}
+  reusable:
+    uses: ./.github/workflows/lint.yml
+  build: {}
+`,
+			want: []string{"build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := workflowCheckNames(
+				logger.New("error"), "ci.yml", []byte(tt.workflow), "main",
+			)
+			if err != nil {
+				t.Fatalf("workflowCheckNames() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("workflowCheckNames() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterCheckNames(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"lint", "test (linux)", "test (windows)", "codecov/patch"}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{name: "no patterns", want: names},
+		{
+			name:    "include",
+			include: []string{"test *"},
+			want:    []string{"test (linux)", "test (windows)"},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"test *", "lint"},
+			exclude: []string{"*windows*"},
+			want:    []string{"lint", "test (linux)"},
+		},
+		{
+			name:    "exclude across slashes",
+			exclude: []string{"codecov*"},
+			want:    []string{"lint", "test (linux)", "test (windows)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := filterCheckNames(names, tt.include, tt.exclude)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("filterCheckNames() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveDiscoveredStatusChecks(t *testing.T) {
+	t.Parallel()
+
+	workflow := func(content string) string {
+		data, err := json.Marshal(map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+		})
+		if err != nil {
+			t.Fatalf("marshaling workflow: %v", err)
+		}
+
+		return string(data)
+	}
+
+	_, client := newFakeGitHub(t, map[string]string{
+		"GET /repos/org/repo/contents/.github/workflows": `[
+			{"type":"file","name":"ci.yml","path":".github/workflows/ci.yml"},
+			{"type":"file","name":"release.yml","path":".github/workflows/release.yml"},
+			{"type":"file","name":"README.md","path":".github/workflows/README.md"}]`,
+		"GET /repos/org/repo/contents/.github/workflows/ci.yml": workflow(
+			"on: pull_request\njobs:\n  lint: {}\n  test: {}\n"),
+		"GET /repos/org/repo/contents/.github/workflows/release.yml": workflow(
+			"on:\n  push:\n    tags: ['v*']\njobs:\n  release: {}\n"),
+	})
+
+	settings := &SettingsDefinition{
+		BranchProtection: []configtypes.BranchProtectionRuleConfig{
+			{
+				Pattern: "main",
+				RequiredStatusChecks: &configtypes.RequiredStatusChecks{
+					Contexts: []string{"license/cla"},
+					Discover: github.Ptr(true),
+				},
+			},
+			{
+				Pattern:              "release/*",
+				RequiredStatusChecks: &configtypes.RequiredStatusChecks{Contexts: []string{"test"}},
+			},
+		},
+		Rulesets: []configtypes.RulesetConfig{
+			{
+				Name: "main",
+				Rules: &configtypes.RulesetRulesConfig{
+					RequiredStatusChecks: &configtypes.StatusChecksRuleConfig{
+						RequiredStatusChecks: []configtypes.StatusCheckConfig{
+							{Context: "test", IntegrationID: github.Ptr(int64(15368))},
+						},
+						Discover: github.Ptr(true),
+						Exclude:  []string{"lint"},
+					},
+				},
+			},
+		},
+	}
+
+	err := resolveDiscoveredStatusChecks(
+		context.Background(), logger.New("error"), client, "org", "repo", settings, "main",
+	)
+	if err != nil {
+		t.Fatalf("resolveDiscoveredStatusChecks() error = %v", err)
+	}
+
+	if diff := cmp.Diff(
+		[]string{"license/cla", "lint", "test"},
+		settings.BranchProtection[0].RequiredStatusChecks.Contexts,
+	); diff != "" {
+		t.Errorf("discovered contexts mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(
+		[]string{"test"},
+		settings.BranchProtection[1].RequiredStatusChecks.Contexts,
+	); diff != "" {
+		t.Errorf("contexts without discovery changed (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(
+		[]configtypes.StatusCheckConfig{
+			{Context: "test", IntegrationID: github.Ptr(int64(15368))},
+		},
+		settings.Rulesets[0].Rules.RequiredStatusChecks.RequiredStatusChecks,
+	); diff != "" {
+		t.Errorf("discovered ruleset checks mismatch (-want +got):\n%s", diff)
+	}
+}
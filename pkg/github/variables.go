@@ -0,0 +1,161 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+// variablesPerPage is the largest page size the variables API accepts.
+const variablesPerPage = 30
+
+// variableChange is a planned create or update of a repository variable.
+type variableChange struct {
+	Diff     FieldDiff
+	Variable *github.ActionsVariable
+	Exists   bool
+}
+
+// SyncVariables synchronizes GitHub Actions repository variables from configuration
+// to target repository. Values are rendered against repository before comparing, and
+// variables that are not configured are left untouched.
+func SyncVariables(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	variables []configtypes.VariableConfig,
+	repository *github.Repository,
+	exclude []string,
+	dryRun bool,
+) ([]FieldDiff, error) {
+	if isSettingExcluded("variables", exclude) {
+		log.Debug("variables sync excluded by config")
+
+		return nil, nil
+	}
+
+	if len(variables) == 0 {
+		log.Debug("no variables configured")
+
+		return nil, nil
+	}
+
+	changes, err := planVariables(ctx, client, org, repo, variables, repository, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]FieldDiff, 0, len(changes))
+	for _, change := range changes {
+		diffs = append(diffs, change.Diff)
+		log.Info("  ~ "+change.Diff.Field,
+			"current", change.Diff.Current,
+			"desired", change.Diff.Desired,
+		)
+	}
+
+	if dryRun {
+		log.Info("dry-run mode: skipping variable changes", "count", len(changes))
+
+		return diffs, nil
+	}
+
+	for _, change := range changes {
+		var err error
+
+		if change.Exists {
+			_, err = client.Actions.UpdateRepoVariable(ctx, org, repo, change.Variable)
+		} else {
+			_, err = client.Actions.CreateRepoVariable(ctx, org, repo, change.Variable)
+		}
+
+		if err != nil {
+			return diffs, errors.Wrapf(err, "writing variable %q", change.Variable.Name)
+		}
+	}
+
+	log.Info("variables synced successfully", "count", len(changes))
+
+	return diffs, nil
+}
+
+// planVariables compares configured variables with the existing ones and returns
+// the variables to create or update.
+func planVariables(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	variables []configtypes.VariableConfig,
+	repository *github.Repository,
+	exclude []string,
+) ([]variableChange, error) {
+	existing, err := listVariables(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching existing variables")
+	}
+
+	var changes []variableChange
+
+	for _, variable := range variables {
+		if isSettingExcluded("variables."+variable.Name, exclude) {
+			continue
+		}
+
+		value := *renderRepositoryTemplate(&variable.Value, repository)
+		change := variableChange{
+			Diff:     FieldDiff{Field: "variables." + variable.Name, Desired: value},
+			Variable: &github.ActionsVariable{Name: variable.Name, Value: value},
+		}
+
+		// GitHub stores variable names in upper case
+		current, ok := existing[strings.ToUpper(variable.Name)]
+		if ok {
+			if current == value {
+				continue
+			}
+
+			change.Diff.Current = current
+			change.Exists = true
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// listVariables returns the values of all repository variables keyed by name.
+func listVariables(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (map[string]string, error) {
+	opts := &github.ListOptions{PerPage: variablesPerPage}
+	values := make(map[string]string)
+
+	for {
+		page, resp, err := client.Actions.ListRepoVariables(ctx, org, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing variables")
+		}
+
+		for _, variable := range page.Variables {
+			values[strings.ToUpper(variable.Name)] = variable.Value
+		}
+
+		if resp.NextPage == 0 {
+			return values, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
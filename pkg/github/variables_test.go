@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+func TestSyncVariables(t *testing.T) {
+	t.Parallel()
+
+	repository := &github.Repository{
+		Name:  github.Ptr("repo"),
+		Owner: &github.User{Login: github.Ptr("org")},
+	}
+
+	variables := []configtypes.VariableConfig{
+		{Name: "APP_ID", Value: "123"},
+		{Name: "docs_url", Value: "https://{{OWNER}}.github.io/{{REPO_NAME}}"},
+		{Name: "NEW", Value: "value"},
+	}
+
+	tests := []struct {
+		name         string
+		exclude      []string
+		dryRun       bool
+		want         []FieldDiff
+		wantRequests []string
+	}{
+		{
+			name: "creates and updates changed variables",
+			want: []FieldDiff{
+				{
+					Field:   "variables.docs_url",
+					Current: "https://example.com",
+					Desired: "https://org.github.io/repo",
+				},
+				{Field: "variables.NEW", Desired: "value"},
+			},
+			wantRequests: []string{
+				"GET /repos/org/repo/actions/variables",
+				"PATCH /repos/org/repo/actions/variables/docs_url",
+				"POST /repos/org/repo/actions/variables",
+			},
+		},
+		{
+			name:    "dry run skips excluded variables",
+			exclude: []string{"variables.docs_url"},
+			dryRun:  true,
+			want:    []FieldDiff{{Field: "variables.NEW", Desired: "value"}},
+			wantRequests: []string{
+				"GET /repos/org/repo/actions/variables",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, client := newFakeGitHub(t, map[string]string{
+				"GET /repos/org/repo/actions/variables": `{"total_count":2,"variables":[
+					{"name":"APP_ID","value":"123"},
+					{"name":"DOCS_URL","value":"https://example.com"}]}`,
+				"PATCH /repos/org/repo/actions/variables/docs_url": ``,
+				"POST /repos/org/repo/actions/variables":           ``,
+			})
+
+			diffs, err := SyncVariables(
+				context.Background(), logger.New("error"), client, "org", "repo",
+				variables, repository, tt.exclude, tt.dryRun,
+			)
+			if err != nil {
+				t.Fatalf("SyncVariables() error = %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, diffs); diff != "" {
+				t.Errorf("diffs mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantRequests, fake.requests); diff != "" {
+				t.Errorf("requests mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v80/github"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const (
+	// webhookFingerprintsVariable is the repository variable storing a fingerprint of
+	// each managed webhook secret, keyed by webhook URL.
+	webhookFingerprintsVariable = "ORG_SYNC_WEBHOOK_FINGERPRINTS"
+	// webhookFingerprintKeyEnv holds the key of the webhook secret fingerprints. It is
+	// only available to the sync, so stored fingerprints cannot be used to guess secrets.
+	webhookFingerprintKeyEnv = "ORG_SYNC_WEBHOOK_FINGERPRINT_KEY"
+	// webhookFingerprintSize is the number of HMAC-SHA256 bytes kept in a fingerprint.
+	webhookFingerprintSize = 16
+	// webhooksPerPage is the page size used when listing webhooks.
+	webhooksPerPage = 100
+)
+
+// Webhook defaults applied to settings the config leaves unset.
+const (
+	defaultWebhookContentType = "json"
+	defaultWebhookEvent       = "push"
+	webhookSSLVerify          = "0"
+	webhookSSLInsecure        = "1"
+)
+
+// webhookChange is a planned webhook operation. Fingerprint is the fingerprint of
+// the desired secret, empty when the webhook has none.
+type webhookChange struct {
+	WebhookOutcome
+
+	Hook        *github.Hook
+	Fingerprint string
+}
+
+// SyncWebhooks synchronizes repository webhooks from configuration to target
+// repository, matching existing webhooks by URL. Webhooks that are not configured
+// are deleted when allowRemoval is set.
+func SyncWebhooks(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	webhooks []configtypes.WebhookConfig,
+	exclude []string,
+	allowRemoval bool,
+	dryRun bool,
+) ([]WebhookOutcome, error) {
+	if isSettingExcluded("webhooks", exclude) {
+		log.Debug("webhooks sync excluded by config")
+
+		return nil, nil
+	}
+
+	if len(webhooks) == 0 && !allowRemoval {
+		log.Debug("no webhooks configured")
+
+		return nil, nil
+	}
+
+	fingerprints, fingerprintsExist, err := getWebhookFingerprints(ctx, client, org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := planWebhooks(
+		ctx, client, org, repo, webhooks, fingerprints, exclude, allowRemoval,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logWebhookChanges(log, changes, dryRun)
+
+	if dryRun {
+		log.Info("dry-run mode: skipping webhook changes", "count", len(changes))
+
+		return webhookOutcomes(changes), nil
+	}
+
+	if err := applyWebhookChanges(ctx, log, client, org, repo, changes); err != nil {
+		return webhookOutcomes(changes), err
+	}
+
+	updated := updatedWebhookFingerprints(fingerprints, changes)
+	if !maps.Equal(fingerprints, updated) {
+		err := putWebhookFingerprints(ctx, client, org, repo, updated, fingerprintsExist)
+		if err != nil {
+			return webhookOutcomes(changes), err
+		}
+	}
+
+	log.Info("webhooks synced successfully", "count", len(changes))
+
+	return webhookOutcomes(changes), nil
+}
+
+// planWebhooks compares configured webhooks with the existing ones and returns the
+// operation needed for each of them.
+func planWebhooks(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	webhooks []configtypes.WebhookConfig,
+	fingerprints map[string]string,
+	exclude []string,
+	allowRemoval bool,
+) ([]webhookChange, error) {
+	existing, err := listWebhooks(ctx, client, org, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching existing webhooks")
+	}
+
+	existingByURL := make(map[string]*github.Hook, len(existing))
+	for _, hook := range existing {
+		existingByURL[hook.GetConfig().GetURL()] = hook
+	}
+
+	changes := make([]webhookChange, 0, len(webhooks))
+	configured := make(map[string]bool, len(webhooks))
+
+	for _, webhook := range webhooks {
+		configured[webhook.URL] = true
+
+		if isSettingExcluded("webhooks."+webhook.URL, exclude) {
+			continue
+		}
+
+		change, err := planWebhook(webhook, existingByURL[webhook.URL], fingerprints)
+		if err != nil {
+			return nil, errors.Wrapf(err, "planning webhook %q", webhook.URL)
+		}
+
+		changes = append(changes, change)
+	}
+
+	if !allowRemoval {
+		return changes, nil
+	}
+
+	for _, hook := range existing {
+		url := hook.GetConfig().GetURL()
+		if configured[url] || isSettingExcluded("webhooks."+url, exclude) {
+			continue
+		}
+
+		changes = append(changes, webhookChange{
+			WebhookOutcome: WebhookOutcome{
+				URL:    url,
+				ID:     hook.GetID(),
+				Action: ResourceActionDeleted,
+			},
+		})
+	}
+
+	return changes, nil
+}
+
+// planWebhook builds the desired webhook and compares it with the existing one of
+// the same URL, which is nil when the webhook does not exist yet.
+func planWebhook(
+	webhook configtypes.WebhookConfig,
+	existing *github.Hook,
+	fingerprints map[string]string,
+) (webhookChange, error) {
+	desired := buildWebhook(webhook)
+
+	change := webhookChange{
+		WebhookOutcome: WebhookOutcome{
+			URL:    webhook.URL,
+			Action: ResourceActionCreated,
+		},
+		Hook: desired,
+	}
+
+	if webhook.SecretFromEnv != "" {
+		secret, ok := os.LookupEnv(webhook.SecretFromEnv)
+		if !ok {
+			return webhookChange{}, errors.Wrapf(
+				ErrSecretValueUnavailable,
+				"environment variable %q is not set", webhook.SecretFromEnv,
+			)
+		}
+
+		key := os.Getenv(webhookFingerprintKeyEnv)
+		if key == "" {
+			return webhookChange{}, errors.Wrapf(
+				ErrSecretValueUnavailable,
+				"environment variable %q is not set", webhookFingerprintKeyEnv,
+			)
+		}
+
+		desired.Config.Secret = github.Ptr(secret)
+		change.Fingerprint = webhookSecretFingerprint([]byte(key), webhook.URL, secret)
+	}
+
+	if existing == nil {
+		return change, nil
+	}
+
+	change.ID = existing.GetID()
+	change.Action = ResourceActionUnchanged
+
+	var fields []FieldDiff
+
+	fields = appendDiff(fields, "content_type",
+		existing.GetConfig().GetContentType(), desired.Config.GetContentType())
+	fields = appendSetDiff(fields, "events", existing.Events, desired.Events)
+	fields = appendDiff(fields, "active", existing.GetActive(), desired.GetActive())
+	fields = appendDiff(fields, "insecure_ssl",
+		existing.GetConfig().GetInsecureSSL(), desired.Config.GetInsecureSSL())
+	fields = appendDiff(fields, "secret", fingerprints[webhook.URL], change.Fingerprint)
+
+	if len(fields) > 0 {
+		change.Action = ResourceActionUpdated
+		change.Fields = fields
+	}
+
+	return change, nil
+}
+
+// buildWebhook converts a webhook config to a go-github Hook, applying defaults.
+func buildWebhook(webhook configtypes.WebhookConfig) *github.Hook {
+	contentType := webhook.ContentType
+	if contentType == "" {
+		contentType = defaultWebhookContentType
+	}
+
+	events := webhook.Events
+	if len(events) == 0 {
+		events = []string{defaultWebhookEvent}
+	}
+
+	insecureSSL := webhookSSLVerify
+	if webhook.InsecureSSL {
+		insecureSSL = webhookSSLInsecure
+	}
+
+	return &github.Hook{
+		Config: &github.HookConfig{
+			URL:         github.Ptr(webhook.URL),
+			ContentType: github.Ptr(contentType),
+			InsecureSSL: github.Ptr(insecureSSL),
+		},
+		Events: events,
+		Active: github.Ptr(getBoolValueOr(webhook.Active, true)),
+	}
+}
+
+// webhookSecretFingerprint returns a keyed fingerprint of a webhook secret. The URL is
+// mixed in so equal secrets of different webhooks have different fingerprints.
+func webhookSecretFingerprint(key []byte, url string, secret string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(url + "\n" + secret))
+
+	return hex.EncodeToString(mac.Sum(nil)[:webhookFingerprintSize])
+}
+
+// listWebhooks returns all webhooks of a repository.
+func listWebhooks(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) ([]*github.Hook, error) {
+	opts := &github.ListOptions{PerPage: webhooksPerPage}
+
+	var hooks []*github.Hook
+
+	for {
+		page, resp, err := client.Repositories.ListHooks(ctx, org, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing webhooks")
+		}
+
+		hooks = append(hooks, page...)
+
+		if resp.NextPage == 0 {
+			return hooks, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// getWebhookFingerprints returns the stored webhook secret fingerprints and whether
+// the variable storing them exists.
+func getWebhookFingerprints(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+) (map[string]string, bool, error) {
+	fingerprints := make(map[string]string)
+
+	variable, _, err := client.Actions.GetRepoVariable(ctx, org, repo, webhookFingerprintsVariable)
+	if err != nil {
+		if isNotFoundError(err) {
+			return fingerprints, false, nil
+		}
+
+		return nil, false, errors.Wrap(err, "getting webhook fingerprints")
+	}
+
+	if err := json.Unmarshal([]byte(variable.Value), &fingerprints); err != nil {
+		return nil, false, errors.Wrap(err, "parsing webhook fingerprints")
+	}
+
+	return fingerprints, true, nil
+}
+
+// updatedWebhookFingerprints returns the fingerprints after applying changes.
+func updatedWebhookFingerprints(
+	fingerprints map[string]string,
+	changes []webhookChange,
+) map[string]string {
+	updated := maps.Clone(fingerprints)
+
+	for _, change := range changes {
+		if change.Action == ResourceActionDeleted || change.Fingerprint == "" {
+			delete(updated, change.URL)
+
+			continue
+		}
+
+		updated[change.URL] = change.Fingerprint
+	}
+
+	return updated
+}
+
+// putWebhookFingerprints stores webhook secret fingerprints in a repository variable.
+func putWebhookFingerprints(
+	ctx context.Context,
+	client *Client,
+	org string,
+	repo string,
+	fingerprints map[string]string,
+	exists bool,
+) error {
+	value, err := json.Marshal(fingerprints)
+	if err != nil {
+		return errors.Wrap(err, "encoding webhook fingerprints")
+	}
+
+	variable := &github.ActionsVariable{Name: webhookFingerprintsVariable, Value: string(value)}
+
+	if exists {
+		_, err = client.Actions.UpdateRepoVariable(ctx, org, repo, variable)
+	} else {
+		_, err = client.Actions.CreateRepoVariable(ctx, org, repo, variable)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "storing webhook fingerprints")
+	}
+
+	return nil
+}
+
+// applyWebhookChanges creates, updates and deletes webhooks as planned. Unchanged
+// webhooks are skipped.
+func applyWebhookChanges(
+	ctx context.Context,
+	log *logger.Logger,
+	client *Client,
+	org string,
+	repo string,
+	changes []webhookChange,
+) error {
+	for i := range changes {
+		change := &changes[i]
+
+		switch change.Action {
+		case ResourceActionCreated:
+			hook, _, err := client.Repositories.CreateHook(ctx, org, repo, change.Hook)
+			if err != nil {
+				return errors.Wrapf(err, "creating webhook %q", change.URL)
+			}
+
+			change.ID = hook.GetID()
+		case ResourceActionUpdated:
+			_, _, err := client.Repositories.EditHook(ctx, org, repo, change.ID, change.Hook)
+			if err != nil {
+				return errors.Wrapf(err, "updating webhook %q", change.URL)
+			}
+		case ResourceActionDeleted:
+			if _, err := client.Repositories.DeleteHook(ctx, org, repo, change.ID); err != nil {
+				return errors.Wrapf(err, "deleting webhook %q", change.URL)
+			}
+		case ResourceActionUnchanged:
+			continue
+		}
+
+		log.Info(string(change.Action)+" webhook", "url", change.URL, "id", change.ID)
+	}
+
+	return nil
+}
+
+// logWebhookChanges logs planned webhook operations and their field changes.
+func logWebhookChanges(log *logger.Logger, changes []webhookChange, dryRun bool) {
+	for _, change := range changes {
+		if change.Action == ResourceActionUnchanged {
+			log.Debug("webhook up to date", "url", change.URL, "id", change.ID)
+
+			continue
+		}
+
+		if dryRun {
+			log.Info("would apply webhook change",
+				"url", change.URL,
+				"id", change.ID,
+				"action", change.Action,
+			)
+		}
+
+		for _, diff := range change.Fields {
+			log.Info("  ~ "+diff.Field, "current", diff.Current, "desired", diff.Desired)
+		}
+	}
+}
+
+// webhookOutcomes returns the exported outcomes of planned changes.
+func webhookOutcomes(changes []webhookChange) []WebhookOutcome {
+	outcomes := make([]WebhookOutcome, 0, len(changes))
+
+	for _, change := range changes {
+		outcomes = append(outcomes, change.WebhookOutcome)
+	}
+
+	return outcomes
+}
+
+// countWebhookChanges returns the number of webhooks created, updated or deleted.
+func countWebhookChanges(outcomes []WebhookOutcome) int {
+	count := 0
+
+	for _, outcome := range outcomes {
+		if outcome.Action != ResourceActionUnchanged {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,221 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/smykla-labs/.github/internal/configtypes"
+	"github.com/smykla-labs/.github/pkg/logger"
+)
+
+const (
+	ciWebhookURL    = "https://ci.example.com/hook"
+	chatWebhookURL  = "https://chat.example.com/hook"
+	staleWebhookURL = "https://stale.example.com/hook"
+
+	testFingerprintKey = "fingerprint-key"
+)
+
+// testFingerprint returns the fingerprint of a webhook secret under the test key.
+func testFingerprint(url string, secret string) string {
+	return webhookSecretFingerprint([]byte(testFingerprintKey), url, secret)
+}
+
+func webhookResponses(t *testing.T) map[string]string {
+	t.Helper()
+
+	fingerprints, err := json.Marshal(map[string]string{
+		ciWebhookURL: testFingerprint(ciWebhookURL, "old-secret"),
+	})
+	if err != nil {
+		t.Fatalf("encoding fingerprints: %v", err)
+	}
+
+	variable, err := json.Marshal(map[string]string{
+		"name":  webhookFingerprintsVariable,
+		"value": string(fingerprints),
+	})
+	if err != nil {
+		t.Fatalf("encoding variable: %v", err)
+	}
+
+	return map[string]string{
+		"GET /repos/org/repo/hooks": `[
+			{"id":1,"active":true,"events":["push"],"config":{
+				"url":"` + ciWebhookURL + `","content_type":"json","insecure_ssl":"0",
+				"secret":"********"}},
+			{"id":2,"active":true,"events":["push"],"config":{
+				"url":"` + staleWebhookURL + `","content_type":"form","insecure_ssl":"0"}}]`,
+		"GET /repos/org/repo/actions/variables/" + webhookFingerprintsVariable:   string(variable),
+		"PATCH /repos/org/repo/hooks/1":                                          `{"id":1}`,
+		"POST /repos/org/repo/hooks":                                             `{"id":3}`,
+		"DELETE /repos/org/repo/hooks/2":                                         ``,
+		"PATCH /repos/org/repo/actions/variables/" + webhookFingerprintsVariable: ``,
+	}
+}
+
+var testWebhooks = []configtypes.WebhookConfig{
+	{
+		URL:           ciWebhookURL,
+		Events:        []string{"push", "pull_request"},
+		SecretFromEnv: "DOTSYNC_TEST_WEBHOOK_SECRET",
+	},
+	{URL: chatWebhookURL, ContentType: "form"},
+}
+
+func TestSyncWebhooks(t *testing.T) {
+	t.Setenv("DOTSYNC_TEST_WEBHOOK_SECRET", "new-secret")
+	t.Setenv(webhookFingerprintKeyEnv, testFingerprintKey)
+
+	fake, client := newFakeGitHub(t, webhookResponses(t))
+
+	outcomes, err := SyncWebhooks(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		testWebhooks, nil, true, false,
+	)
+	if err != nil {
+		t.Fatalf("SyncWebhooks() error = %v", err)
+	}
+
+	want := []WebhookOutcome{
+		{
+			URL:    ciWebhookURL,
+			ID:     1,
+			Action: ResourceActionUpdated,
+			Fields: []FieldDiff{
+				{
+					Field:   "events",
+					Current: []string{"push"},
+					Desired: []string{"pull_request", "push"},
+				},
+				{
+					Field:   "secret",
+					Current: testFingerprint(ciWebhookURL, "old-secret"),
+					Desired: testFingerprint(ciWebhookURL, "new-secret"),
+				},
+			},
+		},
+		{URL: chatWebhookURL, ID: 3, Action: ResourceActionCreated},
+		{URL: staleWebhookURL, ID: 2, Action: ResourceActionDeleted},
+	}
+
+	if diff := cmp.Diff(want, outcomes); diff != "" {
+		t.Errorf("outcomes mismatch (-want +got):\n%s", diff)
+	}
+
+	if !strings.Contains(fake.body("PATCH /repos/org/repo/hooks/1"), `"secret":"new-secret"`) {
+		t.Error("expected updated webhook to carry the new secret")
+	}
+
+	created := fake.body("POST /repos/org/repo/hooks")
+	if !strings.Contains(created, `"content_type":"form"`) ||
+		!strings.Contains(created, `"events":["push"]`) {
+		t.Errorf("created webhook lacks defaults: %s", created)
+	}
+
+	var variable struct {
+		Value string `json:"value"`
+	}
+
+	body := fake.body("PATCH /repos/org/repo/actions/variables/" + webhookFingerprintsVariable)
+	if err := json.Unmarshal([]byte(body), &variable); err != nil {
+		t.Fatalf("decoding variable: %v", err)
+	}
+
+	if strings.Contains(variable.Value, "new-secret") {
+		t.Fatal("webhook secret stored in plain text")
+	}
+
+	wantFingerprints := map[string]string{
+		ciWebhookURL: testFingerprint(ciWebhookURL, "new-secret"),
+	}
+
+	var fingerprints map[string]string
+	if err := json.Unmarshal([]byte(variable.Value), &fingerprints); err != nil {
+		t.Fatalf("decoding fingerprints: %v", err)
+	}
+
+	if diff := cmp.Diff(wantFingerprints, fingerprints); diff != "" {
+		t.Errorf("fingerprints mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSyncWebhooksDryRun(t *testing.T) {
+	t.Setenv("DOTSYNC_TEST_WEBHOOK_SECRET", "old-secret")
+	t.Setenv(webhookFingerprintKeyEnv, testFingerprintKey)
+
+	fake, client := newFakeGitHub(t, webhookResponses(t))
+
+	outcomes, err := SyncWebhooks(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		testWebhooks, nil, false, true,
+	)
+	if err != nil {
+		t.Fatalf("SyncWebhooks() error = %v", err)
+	}
+
+	want := []WebhookOutcome{
+		{
+			URL:    ciWebhookURL,
+			ID:     1,
+			Action: ResourceActionUpdated,
+			Fields: []FieldDiff{
+				{
+					Field:   "events",
+					Current: []string{"push"},
+					Desired: []string{"pull_request", "push"},
+				},
+			},
+		},
+		{URL: chatWebhookURL, Action: ResourceActionCreated},
+	}
+
+	if diff := cmp.Diff(want, outcomes); diff != "" {
+		t.Errorf("outcomes mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, key := range fake.requests {
+		if !strings.HasPrefix(key, "GET ") {
+			t.Errorf("unexpected request in dry-run: %s", key)
+		}
+	}
+}
+
+func TestSyncWebhooksWithoutFingerprintKey(t *testing.T) {
+	t.Setenv("DOTSYNC_TEST_WEBHOOK_SECRET", "new-secret")
+	t.Setenv(webhookFingerprintKeyEnv, "")
+
+	fake, client := newFakeGitHub(t, webhookResponses(t))
+
+	_, err := SyncWebhooks(
+		context.Background(), logger.New("error"), client, "org", "repo",
+		testWebhooks, nil, false, false,
+	)
+	if !errors.Is(err, ErrSecretValueUnavailable) {
+		t.Fatalf("SyncWebhooks() error = %v, want %v", err, ErrSecretValueUnavailable)
+	}
+
+	if fake.called("PATCH /repos/org/repo/hooks/1") {
+		t.Error("webhook updated without a fingerprint key")
+	}
+}
+
+func TestWebhookSecretFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := testFingerprint(ciWebhookURL, "secret")
+
+	other := webhookSecretFingerprint([]byte("other-key"), ciWebhookURL, "secret")
+	if other == fingerprint {
+		t.Error("fingerprints with different keys are equal")
+	}
+
+	if other := testFingerprint(chatWebhookURL, "secret"); other == fingerprint {
+		t.Error("fingerprints of different webhooks are equal")
+	}
+}
@@ -227,8 +227,11 @@ var settingsOverrideTypes = []struct {
 	{"RepositorySettingsConfig", &configtypes.RepositorySettingsConfig{}},
 	{"FeaturesConfig", &configtypes.FeaturesConfig{}},
 	{"SecurityConfig", &configtypes.SecurityConfig{}},
+	{"ActionsConfig", &configtypes.ActionsConfig{}},
+	{"AccessConfig", &configtypes.AccessConfig{}},
 	{"BranchProtectionRuleConfig", &configtypes.BranchProtectionRuleConfig{}},
 	{"RulesetConfig", &configtypes.RulesetConfig{}},
+	{"EnvironmentConfig", &configtypes.EnvironmentConfig{}},
 }
 
 // injectSettingsDefinitions adds settings type definitions to the sync-config schema
@@ -327,6 +330,7 @@ func updateOverridesSchema(schema *jsonschema.Schema, anyOfRefs []*jsonschema.Sc
 	overridesProp.AnyOf = anyOfRefs
 	overridesProp.Type = "" // Clear type when using anyOf
 	overridesProp.Description = "Override values to merge with org settings. " +
-		"Structure should match the section type (repository, features, security, " +
-		"branch protection rule, or ruleset). Only specified fields will override org defaults."
+		"Structure should match the section type (repository, features, security, actions, " +
+		"access, branch protection rule, ruleset, or environment). " +
+		"Only specified fields will override org defaults."
 }